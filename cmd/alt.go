@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"solana-bot/internal/alt"
+	"solana-bot/internal/config"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/spf13/cobra"
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// altCmd 管理交易使用的地址表，authority 为 bot 的交易钱包
+var altCmd = &cobra.Command{
+	Use:   "alt",
+	Short: "solana-bot alt",
+	Long:  `solana-bot alt: 创建、扩充、查看地址表`,
+}
+
+var altSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "把常用账户补进配置的地址表，不够时创建新表",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcClient, wallet, err := nonceEnv(cmd)
+		if err != nil {
+			return err
+		}
+		var c config.Config
+		conf.Load(cfgFile, &c)
+
+		accounts, err := alt.PublicKeys(alt.DefaultAccounts)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		lookups, err := loadLookupTables(ctx, rpcClient, c.Bot.LookupTables)
+		if err != nil {
+			return err
+		}
+		missing := lookups.Missing(accounts...)
+		if len(missing) == 0 {
+			fmt.Println("所有账户都已在地址表中")
+			return nil
+		}
+		fmt.Printf("追加 %d 个账户\n", len(missing))
+		created, err := lookups.Ensure(ctx, wallet, missing...)
+		for _, table := range created {
+			fmt.Println(table.String())
+		}
+		if err != nil {
+			return err
+		}
+		if len(created) > 0 {
+			fmt.Println("把以上地址表加入 etc.yaml 的 bot.lookupTables")
+		}
+		return nil
+	},
+}
+
+var altExtendCmd = &cobra.Command{
+	Use:   "extend <table> <account>...",
+	Short: "向地址表追加账户",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcClient, wallet, err := nonceEnv(cmd)
+		if err != nil {
+			return err
+		}
+		keys, err := alt.PublicKeys(args)
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		lookups := alt.NewManager(rpcClient)
+		if err := lookups.Load(ctx, keys[0]); err != nil {
+			return err
+		}
+		return lookups.Extend(ctx, wallet, keys[0], lookups.Missing(keys[1:]...)...)
+	},
+}
+
+var altListCmd = &cobra.Command{
+	Use:   "list [table]...",
+	Short: "查看地址表的内容，不指定时列出配置中的地址表",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcURL, _ := cmd.Flags().GetString("rpc")
+		if len(args) == 0 {
+			var c config.Config
+			conf.Load(cfgFile, &c)
+			args = c.Bot.LookupTables
+		}
+		lookups, err := loadLookupTables(context.Background(), rpc.New(rpcURL), args)
+		if err != nil {
+			return err
+		}
+		for table, addrs := range lookups.Tables() {
+			fmt.Printf("%s  %d/%d\n", table, len(addrs), alt.MaxAddresses)
+			for i, addr := range addrs {
+				fmt.Printf("  %3d %s\n", i, addr)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(altCmd)
+	altCmd.AddCommand(altSyncCmd, altExtendCmd, altListCmd)
+
+	altCmd.PersistentFlags().String("rpc", rpc.MainNetBeta_RPC, "RPC 地址")
+}
+
+// loadLookupTables 读取 tables 中的地址表
+func loadLookupTables(ctx context.Context, rpcClient *rpc.Client, tables []string) (*alt.Manager, error) {
+	keys, err := alt.PublicKeys(tables)
+	if err != nil {
+		return nil, err
+	}
+	lookups := alt.NewManager(rpcClient)
+	if err := lookups.Load(ctx, keys...); err != nil {
+		return nil, err
+	}
+	return lookups, nil
+}
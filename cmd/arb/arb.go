@@ -7,6 +7,9 @@ import (
 
 	"strconv"
 
+	"solana-bot/internal/config"
+	"solana-bot/internal/signer"
+
 	"github.com/BlockRazorinc/solana-trader-client-go/pb/serverpb"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/system"
@@ -16,12 +19,18 @@ import (
 
 type ArbBot struct {
 	rpcClient  *rpc.Client
-	wallet     *solana.Wallet
+	wallet     signer.Signer
 	sendClient serverpb.ServerClient
 }
 
 func NewArbBot() *ArbBot {
-	wallet, err := solana.WalletFromPrivateKeyBase58("your private key")
+	key, err := signer.FromEnv("PRIVATE_KEY")
+	if err != nil {
+		logx.Must(err)
+		return nil
+	}
+	// Jupiter 返回的指令签名前检查，SOL 只允许转给贿赂账户
+	wallet, err := signer.NewGuarded(key, config.PolicyConf{}, BribeAccount)
 	if err != nil {
 		logx.Must(err)
 		return nil
@@ -138,12 +147,7 @@ func (a *ArbBot) SwapSend(quote *QuoteResponse) {
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &a.wallet.PrivateKey
-		},
-	)
-	if err != nil {
+	if err := a.wallet.SignTransaction(tx); err != nil {
 		logx.Errorf("Error signing transaction: %v", err)
 		return
 	}
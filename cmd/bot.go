@@ -42,6 +42,11 @@ func init() {
 	botCmd.Flags().Bool("mint", false, "启用 mint 监控")
 	botCmd.Flags().Bool("smart", false, "启用 smart 监控")
 	botCmd.Flags().Bool("scm", false, "启用 scm 监控")
+	botCmd.Flags().StringSlice("strategy", nil, "启用的策略，可重复，和 bot.strategies 合并")
+	botCmd.Flags().String("replay", "", "回放录制文件代替实时流，总是以 paper 模式运行")
+	botCmd.Flags().Float64("replay-speed", 1, "回放速度，1 为原速，0 为尽快回放")
+	botCmd.Flags().Bool("paper", false, "模拟交易：不广播，按本地池子状态成交")
+	botCmd.Flags().Float64("paper-balance", 10, "模拟交易时每个钱包的初始 SOL")
 
 }
 
@@ -53,17 +58,35 @@ func StartPumpMonitor(botCmd *cobra.Command) {
 	conf.MustLoad(cfgFile, &c)
 	config.C = c
 
-	// 获取命令行参数
-	mintEnable, _ := botCmd.Flags().GetBool("mint")
-	smartEnable, _ := botCmd.Flags().GetBool("smart")
-	scmEnable, _ := botCmd.Flags().GetBool("scm")
+	// 启用的策略：配置文件加上命令行参数
+	strategies := append([]string(nil), c.Bot.Strategies...)
+	for _, name := range []string{"mint", "smart", "scm"} {
+		if enable, _ := botCmd.Flags().GetBool(name); enable {
+			strategies = append(strategies, name)
+		}
+	}
+	if names, err := botCmd.Flags().GetStringSlice("strategy"); err == nil {
+		strategies = append(strategies, names...)
+	}
+
+	// record 命令没有回放参数，取不到时保持为空
+	replay, _ := botCmd.Flags().GetString("replay")
+	if replay != "" {
+		speed, _ := botCmd.Flags().GetFloat64("replay-speed")
+		monitor.SetReplay(replay, speed)
+	}
+
+	// 回放的是历史行情，只能模拟成交，不能用真实钱包下单；录制默认也只模拟，--live 时才真实下单
+	paper, _ := botCmd.Flags().GetBool("paper")
+	// 只有 record 命令有 --live
+	live, err := botCmd.Flags().GetBool("live")
+	recordPaper := err == nil && !live
+	if replay != "" || paper || recordPaper {
+		balance, _ := botCmd.Flags().GetFloat64("paper-balance")
+		monitor.SetPaper(uint64(balance * 1e9))
+	}
 
-	// 设置 MonitorType
-	monitor.SetMonitorType(map[string]bool{
-		"mint":  mintEnable,
-		"smart": smartEnable,
-		"scm":   scmEnable,
-	})
+	monitor.SetStrategies(strategies)
 
 	monitor.InitConfig()
 
@@ -85,7 +108,10 @@ func StartPumpMonitor(botCmd *cobra.Command) {
 		return
 	}
 	monitor.PumpMonitor = pumpMonitor
-	pumpMonitor.Start()
+	if err := pumpMonitor.Start(); err != nil {
+		logx.Error(err)
+		return
+	}
 	// 监听重启信号
 	go func() {
 		for range restartChan {
@@ -97,7 +123,10 @@ func StartPumpMonitor(botCmd *cobra.Command) {
 				return
 			}
 			monitor.PumpMonitor = newM
-			newM.Start()
+			if err := newM.Start(); err != nil {
+				logx.Must(err)
+				return
+			}
 		}
 	}()
 
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"solana-bot/internal/client"
+	"solana-bot/internal/config"
+	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// nonceCmd 管理 bot 使用的 durable nonce 账户，authority 为 bot 的交易钱包
+var nonceCmd = &cobra.Command{
+	Use:   "nonce",
+	Short: "solana-bot nonce",
+	Long:  `solana-bot nonce: 创建、充值、查看、关闭 nonce 账户`,
+}
+
+var nonceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "创建 nonce 账户",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcClient, wallet, err := nonceEnv(cmd)
+		if err != nil {
+			return err
+		}
+		count, _ := cmd.Flags().GetInt("count")
+		lamports, _ := cmd.Flags().GetUint64("lamports")
+		created, err := client.CreateNonceAccounts(rpcClient, wallet, count, lamports)
+		for _, acc := range created {
+			fmt.Println(acc.String())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("把以上账户加入 etc.yaml 的 bot.nonce.accounts")
+		return nil
+	},
+}
+
+var nonceFundCmd = &cobra.Command{
+	Use:   "fund <account>...",
+	Short: "向 nonce 账户转入 SOL",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcClient, wallet, err := nonceEnv(cmd)
+		if err != nil {
+			return err
+		}
+		lamports, _ := cmd.Flags().GetUint64("lamports")
+		if lamports == 0 {
+			return fmt.Errorf("--lamports is required")
+		}
+		accounts, err := parseNonceAccounts(args)
+		if err != nil {
+			return err
+		}
+		for _, acc := range accounts {
+			sig, err := client.FundNonceAccount(rpcClient, wallet, acc, lamports)
+			if err != nil {
+				return fmt.Errorf("%s: %w", acc, err)
+			}
+			fmt.Printf("%s: %s\n", acc, sig)
+		}
+		return nil
+	},
+}
+
+var nonceListCmd = &cobra.Command{
+	Use:   "list [account]...",
+	Short: "查看 nonce 账户，不指定时列出配置中的账户",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rpcURL, _ := cmd.Flags().GetString("rpc")
+		rpcClient := rpc.New(rpcURL)
+		if len(args) == 0 {
+			c, err := loadNonceConfig()
+			if err != nil {
+				return err
+			}
+			args = configNonceAccounts(c)
+		}
+		accounts, err := parseNonceAccounts(args)
+		if err != nil {
+			return err
+		}
+		for _, acc := range accounts {
+			info, err := client.GetNonceAccount(rpcClient, acc)
+			if err != nil {
+				fmt.Printf("%s: %v\n", acc, err)
+				continue
+			}
+			fmt.Printf("%s  %.6f SOL  authority %s  nonce %s\n",
+				acc, float64(info.Lamports)/1e9, info.Authority, info.Nonce)
+		}
+		return nil
+	},
+}
+
+var nonceCloseCmd = &cobra.Command{
+	Use:   "close <account>...",
+	Short: "关闭 nonce 账户，余额取回它的 authority 钱包",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := loadNonceConfig()
+		if err != nil {
+			return err
+		}
+		accounts, err := parseNonceAccounts(args)
+		if err != nil {
+			return err
+		}
+		rpcURL, _ := cmd.Flags().GetString("rpc")
+		rpcClient := rpc.New(rpcURL)
+		// wallets.list 里的账户由各自的钱包关闭，其余用 bot.signer
+		wallets := make(map[string]signer.Signer)
+		for _, acc := range accounts {
+			name, signerConf := nonceAuthority(c, acc.String())
+			wallet, ok := wallets[name]
+			if !ok {
+				if wallet, err = signer.New(signerConf); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				wallets[name] = wallet
+			}
+			sig, err := client.CloseNonceAccount(rpcClient, wallet, acc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", acc, err)
+			}
+			fmt.Printf("%s: %s\n", acc, sig)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nonceCmd)
+	nonceCmd.AddCommand(nonceCreateCmd, nonceFundCmd, nonceListCmd, nonceCloseCmd)
+
+	nonceCmd.PersistentFlags().String("rpc", rpc.MainNetBeta_RPC, "RPC 地址")
+	nonceCreateCmd.Flags().Int("count", 1, "创建的账户数")
+	nonceCreateCmd.Flags().Uint64("lamports", 0, "每个账户存入的 lamports，默认为免租金最低余额")
+	nonceFundCmd.Flags().Uint64("lamports", 0, "转入的 lamports")
+}
+
+// nonceEnv 按配置 bot.signer 的签名方式取得付款和 authority 钱包
+func nonceEnv(cmd *cobra.Command) (*rpc.Client, signer.Signer, error) {
+	c, err := loadNonceConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	wallet, err := signer.New(c.Bot.Signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	rpcURL, _ := cmd.Flags().GetString("rpc")
+	return rpc.New(rpcURL), wallet, nil
+}
+
+func loadNonceConfig() (config.Config, error) {
+	godotenv.Load()
+	var c config.Config
+	if err := conf.Load(cfgFile, &c); err != nil {
+		return c, fmt.Errorf("load %s: %w", cfgFile, err)
+	}
+	return c, nil
+}
+
+// configNonceAccounts 配置中 bot 使用的 nonce 账户：bot.nonce 的账户（没有配置多钱包且为空时用内置账户），
+// 加上 wallets.list 里每个钱包的账户
+func configNonceAccounts(c config.Config) []string {
+	accounts := c.Bot.Nonce.Accounts
+	if len(accounts) == 0 && len(c.Bot.Wallets.List) == 0 {
+		accounts = global.DefaultNonceAccounts
+	}
+	accounts = slices.Clone(accounts)
+	for _, wc := range c.Bot.Wallets.List {
+		accounts = append(accounts, wc.Nonce.Accounts...)
+	}
+	return accounts
+}
+
+// nonceAuthority account 所属钱包的名字和签名方式，不在 wallets.list 里时为 bot.signer
+func nonceAuthority(c config.Config, account string) (string, config.SignerConf) {
+	for i, wc := range c.Bot.Wallets.List {
+		if slices.Contains(wc.Nonce.Accounts, account) {
+			name := wc.Name
+			if name == "" {
+				name = fmt.Sprintf("wallet-%d", i)
+			}
+			return name, wc.Signer
+		}
+	}
+	return "default", c.Bot.Signer
+}
+
+// parseNonceAccounts 解析命令行或配置里的账户地址
+func parseNonceAccounts(args []string) ([]solana.PublicKey, error) {
+	out := make([]solana.PublicKey, 0, len(args))
+	for _, acc := range args {
+		key, err := solana.PublicKeyFromBase58(acc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account %q: %w", acc, err)
+		}
+		out = append(out, key)
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"solana-bot/internal/monitor"
+
+	"github.com/spf13/cobra"
+)
+
+// recordCmd 与 bot 相同地运行，同时把收到的所有流更新录制到文件，供 bot --replay 离线复现；
+// 默认以 paper 模式运行，加 --live 才用真实钱包下单
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "solana-bot record",
+	Long:  `solana-bot record: 运行 bot 并录制所有 Geyser 流更新`,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		monitor.SetRecord(out)
+		StartPumpMonitor(cmd)
+		Start(cfgFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().String("out", "stream.rec", "录制文件路径（追加写入）")
+	recordCmd.Flags().Bool("mint", false, "启用 mint 监控")
+	recordCmd.Flags().Bool("smart", false, "启用 smart 监控")
+	recordCmd.Flags().Bool("scm", false, "启用 scm 监控")
+	recordCmd.Flags().Bool("live", false, "录制的同时真实下单，默认只模拟交易")
+	recordCmd.Flags().Float64("paper-balance", 10, "模拟交易时每个钱包的初始 SOL")
+}
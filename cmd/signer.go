@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/rpcs"
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// signerCmd 管理交易钱包的私钥：生成、导入 keystore，或作为独立的签名服务运行
+var signerCmd = &cobra.Command{
+	Use:   "signer",
+	Short: "solana-bot signer",
+	Long:  `solana-bot signer: 生成、导入 keystore，运行远程签名服务`,
+}
+
+var signerCreateCmd = &cobra.Command{
+	Use:   "create <keystore>",
+	Short: "生成新钱包并写入 keystore",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := newPassphrase()
+		if err != nil {
+			return err
+		}
+		key, err := solana.NewRandomPrivateKey()
+		if err != nil {
+			return err
+		}
+		if err := signer.WriteKeystore(args[0], key, passphrase); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", args[0], key.PublicKey())
+		return nil
+	},
+}
+
+var signerImportCmd = &cobra.Command{
+	Use:   "import <keystore>",
+	Short: "把 base58 私钥加密写入 keystore，私钥取自 --env 指定的环境变量或终端输入",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		godotenv.Load()
+		env, _ := cmd.Flags().GetString("env")
+		raw, err := signer.ReadPassphrase(env, "base58 private key: ")
+		if err != nil {
+			return err
+		}
+		key, err := solana.PrivateKeyFromBase58(raw)
+		if err != nil {
+			return err
+		}
+		passphrase, err := newPassphrase()
+		if err != nil {
+			return err
+		}
+		if err := signer.WriteKeystore(args[0], key, passphrase); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", args[0], key.PublicKey())
+		fmt.Printf("确认无误后可以从 .env 中删除 %s\n", env)
+		return nil
+	},
+}
+
+var signerServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "按配置的签名方式加载私钥，提供签名服务",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		godotenv.Load()
+		var c config.Config
+		conf.Load(cfgFile, &c)
+		if c.Bot.Signer.Type == "remote" {
+			return errors.New("signer serve: signer type must be env or keystore")
+		}
+		s, err := signer.New(c.Bot.Signer)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		// 策略检查放在签名服务这一侧，bot 进程被攻破也绕不过
+		if !c.Bot.Signer.Policy.Disabled {
+			tips, err := rpcs.TipAccounts(c.Bot.Channels)
+			if err != nil {
+				return err
+			}
+			policy, err := signer.NewPolicy(c.Bot.Signer.Policy, tips...)
+			if err != nil {
+				return err
+			}
+			// v0 交易的地址表内容由签名服务自己从链上读取，不信任 bot 传来的
+			if len(c.Bot.LookupTables) > 0 {
+				rpcURL, _ := cmd.Flags().GetString("rpc")
+				lookups, err := loadLookupTables(ctx, rpc.New(rpcURL), c.Bot.LookupTables)
+				if err != nil {
+					return err
+				}
+				policy.SetAddressTables(lookups.Tables())
+				go lookups.Run(ctx, time.Minute, policy.SetAddressTables)
+			}
+			s = signer.Guard(s, policy)
+		}
+		listen, _ := cmd.Flags().GetString("listen")
+		tokenEnv, _ := cmd.Flags().GetString("token-env")
+		return signer.Serve(ctx, listen, s, os.Getenv(tokenEnv))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signerCmd)
+	signerCmd.AddCommand(signerCreateCmd, signerImportCmd, signerServeCmd)
+
+	signerImportCmd.Flags().String("env", "PRIVATE_KEY", "私钥所在的环境变量")
+	signerServeCmd.Flags().String("listen", "unix:///tmp/solana-bot-signer.sock", "监听地址，unix:///path/to.sock 或 host:port")
+	signerServeCmd.Flags().String("token-env", "SIGNER_TOKEN", "共享口令所在的环境变量，监听 host:port 时必须设置，请求需带 Authorization: Bearer <口令>")
+	signerServeCmd.Flags().String("rpc", rpc.MainNetBeta_RPC, "读取地址表的 RPC 地址")
+}
+
+// newPassphrase 在终端输入两次新口令；设置了 KEYSTORE_PASSPHRASE 时直接使用
+func newPassphrase() (string, error) {
+	if v := os.Getenv("KEYSTORE_PASSPHRASE"); v != "" {
+		return v, nil
+	}
+	first, err := signer.ReadPassphrase("", "new passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	second, err := signer.ReadPassphrase("", "repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", errors.New("passphrases do not match")
+	}
+	if first == "" {
+		return "", errors.New("passphrase is empty")
+	}
+	return first, nil
+}
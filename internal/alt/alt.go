@@ -0,0 +1,324 @@
+package alt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var ProgramID = solana.MustPublicKeyFromBase58("AddressLookupTab1e1111111111111111111111111")
+
+const (
+	MaxAddresses = 256 // 每个地址表最多的地址数
+	extendChunk  = 20  // 每笔交易追加的地址数，受交易大小限制
+)
+
+// DefaultAccounts 各池子交易都会用到的固定账户，放进地址表；
+// 通道小费账户不放，Jito 等中继只认交易静态账户里的小费转账
+var DefaultAccounts = []string{
+	"11111111111111111111111111111111",             // System
+	"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",  // Token
+	"TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb",  // Token-2022
+	"ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL", // Associated Token Account
+	"So11111111111111111111111111111111111111112",  // WSOL
+	"SysvarRecentB1ockHashes11111111111111111111",  // nonce 推进
+	"SysvarRent111111111111111111111111111111111",
+	"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",  // pump.fun
+	"4wTV1YmiEkRvAtNtsSGPtUrqRYQMe5SKy2uB4Jjaxnjf", // pump.fun global
+	"CebN5WGQ4jvEPvsVU4EoHEpgzq1VV7AbicfhtW4xC9iM", // pump.fun fee recipient
+	"Ce6TQqeHC9p8KetsN6JsjHK7UTZk7nasjjnr7XxXp9F1", // pump.fun event authority
+	"8Wf5TiAheLUqBrKXeYg2JtAFFMWtKdG2BSFgqUcPVwTt", // pump fee config
+	"pfeeUxB6jkeY1Hxd7CsFCAjcbHA9rWtchMGdZ6VojVZ",  // pump fee program
+	"pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA",  // PumpSwap
+	"ADyA8hdefvWN2dbGGWFotbzWxrAvLW83WG6QCVXvJKqw", // PumpSwap global config
+	"GS4CU59F31iL7aR2Q8zVS8DRrcRnXX1yjQ66TqNVQnaR", // PumpSwap event authority
+	"62qc2CNXwrYqQScmEdiZFFAnJR262PxWEuNQtxfafNgV", // PumpSwap protocol fee recipient
+	"dbcij3LWUppWqq96dh6gJWwBifmcGfLSB5D4DuSMaqN",  // Meteora DBC
+	"FhVo3mqL8PW5pH5U2CN4XE33DokiyZnUwuGpH2hmHLuM", // Meteora DBC pool authority
+	"LanMV9sAd7wArD4vJFi2qDdfnVhFxYSUg6eADduJ3uj",  // Raydium Launchpad
+	"2DPAtwB8L12vrMRExbLuyGnC7n2J5LNoZQSejeQGpwkr", // Raydium Launchpad event authority
+}
+
+// FindTableAddress authority 在 recentSlot 创建的地址表地址
+func FindTableAddress(authority solana.PublicKey, recentSlot uint64) (solana.PublicKey, uint8, error) {
+	slot := make([]byte, 8)
+	binary.LittleEndian.PutUint64(slot, recentSlot)
+	return solana.FindProgramAddress([][]byte{authority[:], slot}, ProgramID)
+}
+
+// NewCreateInstruction 创建地址表，recentSlot 必须是最近的 slot
+func NewCreateInstruction(authority, payer solana.PublicKey, recentSlot uint64) (solana.Instruction, solana.PublicKey, error) {
+	table, bump, err := FindTableAddress(authority, recentSlot)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	data := make([]byte, 4+8+1)
+	binary.LittleEndian.PutUint32(data, 0)
+	binary.LittleEndian.PutUint64(data[4:], recentSlot)
+	data[12] = bump
+	return solana.NewInstruction(ProgramID, solana.AccountMetaSlice{
+		solana.Meta(table).WRITE(),
+		solana.Meta(authority).SIGNER(),
+		solana.Meta(payer).SIGNER().WRITE(),
+		solana.Meta(solana.SystemProgramID),
+	}, data), table, nil
+}
+
+// NewExtendInstruction 向地址表追加地址
+func NewExtendInstruction(table, authority, payer solana.PublicKey, addresses []solana.PublicKey) solana.Instruction {
+	data := make([]byte, 4+8, 4+8+32*len(addresses))
+	binary.LittleEndian.PutUint32(data, 2)
+	binary.LittleEndian.PutUint64(data[4:], uint64(len(addresses)))
+	for _, addr := range addresses {
+		data = append(data, addr[:]...)
+	}
+	return solana.NewInstruction(ProgramID, solana.AccountMetaSlice{
+		solana.Meta(table).WRITE(),
+		solana.Meta(authority).SIGNER(),
+		solana.Meta(payer).SIGNER().WRITE(),
+		solana.Meta(solana.SystemProgramID),
+	}, data)
+}
+
+// Manager 维护地址表的本地缓存，并能创建、扩充 authority 名下的地址表
+type Manager struct {
+	client *rpc.Client
+
+	mu     sync.RWMutex
+	tables map[solana.PublicKey]solana.PublicKeySlice
+	order  []solana.PublicKey // 按加载顺序，扩充时优先填满前面的表
+}
+
+func NewManager(client *rpc.Client) *Manager {
+	return &Manager{
+		client: client,
+		tables: make(map[solana.PublicKey]solana.PublicKeySlice),
+	}
+}
+
+// Load 从链上读取地址表的内容，更新本地缓存
+func (m *Manager) Load(ctx context.Context, tables ...solana.PublicKey) error {
+	for _, table := range tables {
+		state, err := addresslookuptable.GetAddressLookupTable(ctx, m.client, table)
+		if err != nil {
+			return fmt.Errorf("address lookup table %s: %w", table, err)
+		}
+		if !state.IsActive() {
+			return fmt.Errorf("address lookup table %s is deactivated", table)
+		}
+		m.mu.Lock()
+		if _, ok := m.tables[table]; !ok {
+			m.order = append(m.order, table)
+		}
+		m.tables[table] = state.Addresses
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Tables 当前缓存的地址表，可直接用于 TxBuilder
+func (m *Manager) Tables() map[solana.PublicKey]solana.PublicKeySlice {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[solana.PublicKey]solana.PublicKeySlice, len(m.tables))
+	for k, v := range m.tables {
+		out[k] = v
+	}
+	return out
+}
+
+// Missing accounts 中不在任何缓存地址表里的账户，去重
+func (m *Manager) Missing(accounts ...solana.PublicKey) []solana.PublicKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	have := make(map[solana.PublicKey]bool)
+	for _, addrs := range m.tables {
+		for _, addr := range addrs {
+			have[addr] = true
+		}
+	}
+	var out []solana.PublicKey
+	for _, acc := range accounts {
+		if !have[acc] {
+			have[acc] = true
+			out = append(out, acc)
+		}
+	}
+	return out
+}
+
+// Run 每隔 interval 重新读取缓存的地址表，其它进程扩充后这里也能用上；onUpdate 收到最新的地址表
+func (m *Manager) Run(ctx context.Context, interval time.Duration, onUpdate func(map[solana.PublicKey]solana.PublicKeySlice)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.RLock()
+			tables := append([]solana.PublicKey(nil), m.order...)
+			m.mu.RUnlock()
+			if err := m.Load(ctx, tables...); err != nil {
+				logx.Errorf("[alt] %v", err)
+				continue
+			}
+			onUpdate(m.Tables())
+		}
+	}
+}
+
+// Create 创建一个 authority 为 wallet 的地址表，并写入前 extendChunk 个地址
+func (m *Manager) Create(ctx context.Context, wallet signer.Signer, addresses ...solana.PublicKey) (solana.PublicKey, error) {
+	slot, err := m.client.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	create, table, err := NewCreateInstruction(wallet.PublicKey(), wallet.PublicKey(), slot)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	instrs := []solana.Instruction{create}
+	first := addresses[:min(len(addresses), extendChunk)]
+	if len(first) > 0 {
+		instrs = append(instrs, NewExtendInstruction(table, wallet.PublicKey(), wallet.PublicKey(), first))
+	}
+	if err := m.send(ctx, wallet, instrs...); err != nil {
+		return solana.PublicKey{}, fmt.Errorf("create address lookup table: %w", err)
+	}
+	m.mu.Lock()
+	m.tables[table] = append(solana.PublicKeySlice(nil), first...)
+	m.order = append(m.order, table)
+	m.mu.Unlock()
+
+	if err := m.Extend(ctx, wallet, table, addresses[len(first):]...); err != nil {
+		return table, err
+	}
+	return table, nil
+}
+
+// Extend 向 table 追加地址，每笔交易 extendChunk 个，逐笔确认
+func (m *Manager) Extend(ctx context.Context, wallet signer.Signer, table solana.PublicKey, addresses ...solana.PublicKey) error {
+	m.mu.RLock()
+	size := len(m.tables[table])
+	m.mu.RUnlock()
+	if size+len(addresses) > MaxAddresses {
+		return fmt.Errorf("address lookup table %s: %d + %d addresses exceeds %d", table, size, len(addresses), MaxAddresses)
+	}
+
+	for start := 0; start < len(addresses); start += extendChunk {
+		chunk := addresses[start:min(start+extendChunk, len(addresses))]
+		if err := m.send(ctx, wallet, NewExtendInstruction(table, wallet.PublicKey(), wallet.PublicKey(), chunk)); err != nil {
+			return fmt.Errorf("extend address lookup table %s: %w", table, err)
+		}
+		m.mu.Lock()
+		m.tables[table] = append(m.tables[table], chunk...)
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Ensure 把缓存地址表里还没有的账户补进去：先填满已有的表，不够时创建新表；返回新建的表
+func (m *Manager) Ensure(ctx context.Context, wallet signer.Signer, accounts ...solana.PublicKey) ([]solana.PublicKey, error) {
+	missing := m.Missing(accounts...)
+
+	m.mu.RLock()
+	order := append([]solana.PublicKey(nil), m.order...)
+	m.mu.RUnlock()
+	for _, table := range order {
+		if len(missing) == 0 {
+			break
+		}
+		m.mu.RLock()
+		room := MaxAddresses - len(m.tables[table])
+		m.mu.RUnlock()
+		if room <= 0 {
+			continue
+		}
+		n := min(room, len(missing))
+		if err := m.Extend(ctx, wallet, table, missing[:n]...); err != nil {
+			return nil, err
+		}
+		missing = missing[n:]
+	}
+
+	var created []solana.PublicKey
+	for len(missing) > 0 {
+		n := min(MaxAddresses, len(missing))
+		table, err := m.Create(ctx, wallet, missing[:n]...)
+		if !table.IsZero() {
+			created = append(created, table)
+		}
+		if err != nil {
+			return created, err
+		}
+		missing = missing[n:]
+	}
+	return created, nil
+}
+
+// send 发送交易并等到 confirmed；后续的扩充依赖前一笔已经上链
+func (m *Manager) send(ctx context.Context, wallet signer.Signer, instrs ...solana.Instruction) error {
+	recent, err := m.client.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return err
+	}
+	tx, err := solana.NewTransaction(instrs, recent.Value.Blockhash, solana.TransactionPayer(wallet.PublicKey()))
+	if err != nil {
+		return err
+	}
+	if err := wallet.SignTransaction(tx); err != nil {
+		return err
+	}
+	sig, err := m.client.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
+		PreflightCommitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	for {
+		statuses, err := m.client.GetSignatureStatuses(ctx, false, sig)
+		if err == nil && len(statuses.Value) > 0 && statuses.Value[0] != nil {
+			status := statuses.Value[0]
+			if status.Err != nil {
+				return fmt.Errorf("%s: %v", sig, status.Err)
+			}
+			if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
+				status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				logx.Infof("[alt] %s confirmed", sig)
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(fmt.Errorf("%s not confirmed", sig), ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// PublicKeys 把 base58 地址转成 PublicKey
+func PublicKeys(addrs []string) ([]solana.PublicKey, error) {
+	out := make([]solana.PublicKey, 0, len(addrs))
+	for _, addr := range addrs {
+		key, err := solana.PublicKeyFromBase58(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", addr, err)
+		}
+		out = append(out, key)
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package alt
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"solana-bot/internal/global"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+func TestInstructions(t *testing.T) {
+	authority := solana.NewWallet().PublicKey()
+	create, table, err := NewCreateInstruction(authority, authority, 300_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, bump, _ := FindTableAddress(authority, 300_000_000)
+	if !table.Equals(want) || !create.Accounts()[0].PublicKey.Equals(table) {
+		t.Fatalf("table = %s, want %s", table, want)
+	}
+	data, _ := create.Data()
+	if binary.LittleEndian.Uint32(data) != 0 || binary.LittleEndian.Uint64(data[4:]) != 300_000_000 || data[12] != bump {
+		t.Fatalf("create data = %x", data)
+	}
+
+	addrs := []solana.PublicKey{solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()}
+	data, _ = NewExtendInstruction(table, authority, authority, addrs).Data()
+	if len(data) != 12+64 || binary.LittleEndian.Uint32(data) != 2 || binary.LittleEndian.Uint64(data[4:]) != 2 {
+		t.Fatalf("extend data = %x", data)
+	}
+	if !solana.PublicKeyFromBytes(data[12+32:]).Equals(addrs[1]) {
+		t.Fatal("extend addresses out of order")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	m := NewManager(nil)
+	table := solana.NewWallet().PublicKey()
+	known, unknown := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	m.tables[table] = solana.PublicKeySlice{known}
+
+	missing := m.Missing(known, unknown, unknown)
+	if len(missing) != 1 || !missing[0].Equals(unknown) {
+		t.Fatalf("missing = %v", missing)
+	}
+}
+
+func TestVersionedTx(t *testing.T) {
+	payer := solana.NewWallet().PublicKey()
+	tip := solana.NewWallet().PublicKey()
+	table := solana.NewWallet().PublicKey()
+
+	b := global.NewTxBuilder(payer, solana.Hash{1})
+	b.AddInstruction(system.NewTransferInstruction(1, payer, tip).Build())
+	b.SetAddressTables(map[solana.PublicKey]solana.PublicKeySlice{table: {tip}})
+	tx, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tx.Message.IsVersioned() || len(tx.Message.AddressTableLookups) != 1 {
+		t.Fatalf("not a v0 transaction with lookups: %v", tx.Message.AddressTableLookups)
+	}
+	keys, err := tx.Message.GetAllKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys[tx.Message.Instructions[0].Accounts[1]].Equals(tip) {
+		t.Fatal("tip not resolved from lookup table")
+	}
+
+	// 小费账户即使在地址表里也作为静态账户
+	global.SetStaticAccounts(tip)
+	defer global.SetStaticAccounts()
+	if tx, err = b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Message.AddressTableLookups) != 0 || !tx.Message.AccountKeys[tx.Message.Instructions[0].Accounts[1]].Equals(tip) {
+		t.Fatalf("tip loaded from lookup table: %v", tx.Message.AddressTableLookups)
+	}
+
+	// 没有地址表时仍是 legacy 交易
+	b.SetAddressTables(nil)
+	if tx, _ = b.Build(); tx.Message.IsVersioned() {
+		t.Fatal("legacy builder produced a v0 transaction")
+	}
+}
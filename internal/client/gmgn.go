@@ -7,6 +7,8 @@ import (
 	"log"
 	"net/http"
 
+	"solana-bot/internal/signer"
+
 	solana "github.com/gagliardetto/solana-go"
 )
 
@@ -63,7 +65,7 @@ func GetSwap(inAddress, outToken, amount, fromAddress, slippage string) (*Result
 
 }
 
-func SendTransaction(signedTx, from_address string, signer solana.PrivateKey) (*Result, error) {
+func SendTransaction(signedTx, from_address string, wallet signer.Signer) (*Result, error) {
 	url := "https://gmgn.ai/defi/router/v1/sol/tx/submit_signed_bundle_transaction"
 
 	var tx solana.Transaction
@@ -73,12 +75,7 @@ func SendTransaction(signedTx, from_address string, signer solana.PrivateKey) (*
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &signer
-		},
-	)
-	if err != nil {
+	if err := wallet.SignTransaction(&tx); err != nil {
 		log.Fatal(err)
 	}
 
@@ -5,7 +5,8 @@ import (
 	"log"
 	"testing"
 
-	"github.com/gagliardetto/solana-go"
+	"solana-bot/internal/signer"
+
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
@@ -32,11 +33,11 @@ func TestSend(t *testing.T) {
 	}
 
 	rpcClient := rpc.New(HTTPUrl)
-	pk, err := solana.PrivateKeyFromBase58("")
+	wallet, err := signer.FromEnv("PRIVATE_KEY")
 	if err != nil {
-		log.Fatal(err)
+		t.Skip(err)
 	}
-	txHash, err := SendTransactionWithJupiter(rpcClient, wsClient, r.SwapTransaction, &pk)
+	txHash, err := SendTransactionWithJupiter(rpcClient, wsClient, r.SwapTransaction, wallet)
 	if err != nil {
 		t.Error(err)
 	}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"solana-bot/internal/txerr"
 )
 
 const (
@@ -126,6 +128,9 @@ func Quote(fromToken, toToken string, amount, slippageBps uint64) (*QuoteRespons
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("[%s] %w", apiUrl, txerr.ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("[%s] request failed with status code: %d", apiUrl, resp.StatusCode)
 	}
@@ -239,6 +244,9 @@ func SwapInstructions(quote *QuoteResponse, spender string) (*SwapInstructionsRe
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("swap-instructions: %w", txerr.ErrRateLimited)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
 	}
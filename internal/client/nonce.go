@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"solana-bot/internal/signer"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// NonceAccountInfo nonce 账户的链上状态
+type NonceAccountInfo struct {
+	Account   solana.PublicKey
+	Lamports  uint64
+	Authority solana.PublicKey
+	Nonce     solana.Hash
+}
+
+// CreateNonceAccounts 创建 count 个以 wallet 为 authority 的 nonce 账户，
+// lamports 为 0 时只存入免租金的最低余额
+func CreateNonceAccounts(client *rpc.Client, wallet signer.Signer, count int, lamports uint64) ([]solana.PublicKey, error) {
+	minBalance, err := client.GetMinimumBalanceForRentExemption(context.TODO(), nonceAccountSize, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	if lamports < minBalance {
+		lamports = minBalance
+	}
+
+	var created []solana.PublicKey
+	for i := 0; i < count; i++ {
+		nonceAccount := solana.NewWallet()
+		_, err := sendWithSigners(client, wallet.PublicKey(),
+			[]signer.Signer{wallet, signer.NewKeySigner(nonceAccount.PrivateKey)},
+			system.NewCreateAccountInstruction(
+				lamports,
+				nonceAccountSize,
+				system.ProgramID,
+				wallet.PublicKey(),
+				nonceAccount.PublicKey(),
+			).Build(),
+			system.NewInitializeNonceAccountInstruction(
+				wallet.PublicKey(),
+				nonceAccount.PublicKey(),
+				solana.SysVarRecentBlockHashesPubkey,
+				solana.SysVarRentPubkey,
+			).Build(),
+		)
+		if err != nil {
+			return created, fmt.Errorf("create nonce account: %w", err)
+		}
+		created = append(created, nonceAccount.PublicKey())
+	}
+	return created, nil
+}
+
+// FundNonceAccount 向 nonce 账户转入 lamports
+func FundNonceAccount(client *rpc.Client, wallet signer.Signer, nonceAccount solana.PublicKey, lamports uint64) (solana.Signature, error) {
+	return sendWithSigners(client, wallet.PublicKey(), []signer.Signer{wallet},
+		system.NewTransferInstruction(lamports, wallet.PublicKey(), nonceAccount).Build(),
+	)
+}
+
+// CloseNonceAccount 把 nonce 账户的全部余额取回 wallet，账户随之关闭
+func CloseNonceAccount(client *rpc.Client, wallet signer.Signer, nonceAccount solana.PublicKey) (solana.Signature, error) {
+	balance, err := client.GetBalance(context.TODO(), nonceAccount, rpc.CommitmentConfirmed)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if balance.Value == 0 {
+		return solana.Signature{}, errors.New("nonce account is empty")
+	}
+	return sendWithSigners(client, wallet.PublicKey(), []signer.Signer{wallet},
+		system.NewWithdrawNonceAccountInstruction(
+			balance.Value,
+			nonceAccount,
+			wallet.PublicKey(),
+			solana.SysVarRecentBlockHashesPubkey,
+			solana.SysVarRentPubkey,
+			wallet.PublicKey(),
+		).Build(),
+	)
+}
+
+// GetNonceAccount 读取 nonce 账户的余额、authority 和当前 nonce
+func GetNonceAccount(client *rpc.Client, nonceAccount solana.PublicKey) (*NonceAccountInfo, error) {
+	account, err := client.GetAccountInfo(context.TODO(), nonceAccount)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.Value == nil {
+		return nil, errors.New("nonce account not found")
+	}
+	acc := new(system.NonceAccount)
+	if err := acc.UnmarshalWithDecoder(bin.NewBinDecoder(account.Value.Data.GetBinary())); err != nil {
+		return nil, fmt.Errorf("decode nonce account: %w", err)
+	}
+	return &NonceAccountInfo{
+		Account:   nonceAccount,
+		Lamports:  account.Value.Lamports,
+		Authority: acc.AuthorizedPubkey,
+		Nonce:     solana.Hash(acc.Nonce),
+	}, nil
+}
+
+func sendWithSigners(client *rpc.Client, payer solana.PublicKey, signers []signer.Signer, instructions ...solana.Instruction) (solana.Signature, error) {
+	recent, err := client.GetLatestBlockhash(context.TODO(), rpc.CommitmentConfirmed)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	tx, err := solana.NewTransaction(instructions, recent.Value.Blockhash, solana.TransactionPayer(payer))
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if err := signer.Sign(tx, signers...); err != nil {
+		return solana.Signature{}, err
+	}
+	return client.SendTransactionWithOpts(context.TODO(), tx, rpc.TransactionOpts{
+		PreflightCommitment: rpc.CommitmentConfirmed,
+	})
+}
@@ -3,15 +3,15 @@ package client
 import (
 	"testing"
 
-	"github.com/gagliardetto/solana-go"
+	"solana-bot/internal/signer"
+
 	"github.com/gagliardetto/solana-go/rpc"
 )
 
 func TestRay(t *testing.T) {
-	wallet, err := solana.WalletFromPrivateKeyBase58("2rGpKU7MXBWsdrugTyTcRepa25zmEkeYYj4Bjqv68rRXkzgAvh2rcVQfSrAz2G8Bdcv3Ve3nfCftLZPErCMaH65W")
+	wallet, err := signer.FromEnv("PRIVATE_KEY")
 	if err != nil {
-		t.Error(err)
-		return
+		t.Skip(err)
 	}
 
 	// Initialize Solana client
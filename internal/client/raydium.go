@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"strings"
 
+	"solana-bot/internal/signer"
+
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 )
@@ -52,7 +54,7 @@ type SwapTransactionResponse struct {
 	} `json:"data"`
 }
 
-func RaySwapByApi(client *rpc.Client, wallet *solana.Wallet, InputMint, OutputMint, inputAccount, outputAccount string, Amount, Slippage uint64) error {
+func RaySwapByApi(client *rpc.Client, wallet signer.Signer, InputMint, OutputMint, inputAccount, outputAccount string, Amount, Slippage uint64) error {
 	// log.Println("Getting priority fee...")
 	// // Get priority fee
 	// priorityFeeResp, err := http.Get(fmt.Sprintf("%s/priority-fee", BaseHost))
@@ -160,13 +162,7 @@ func RaySwapByApi(client *rpc.Client, wallet *solana.Wallet, InputMint, OutputMi
 
 			log.Printf("Signing transaction %d...", idx+1)
 			// Sign the transaction
-			_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-				if key.Equals(wallet.PublicKey()) {
-					return &wallet.PrivateKey
-				}
-				return nil
-			})
-			if err != nil {
+			if err := wallet.SignTransaction(tx); err != nil {
 				return fmt.Errorf("failed to sign transaction %d: %v", idx+1, err)
 			}
 
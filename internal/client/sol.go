@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
+	"solana-bot/internal/signer"
+
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/memo"
@@ -17,13 +20,14 @@ import (
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
 
-const (
-	HTTPUrl = "https://mainnet.helius-rpc.com/?api-key=cae6aa3f-a766-4ad7-a0fd-d9ae935d414e"
-	WSUrl   = "wss://mainnet.helius-rpc.com/?api-key=cae6aa3f-a766-4ad7-a0fd-d9ae935d414e" // WebSocket 节点                           // Pump 合约的 Program ID
-
-	nonceAccountSize = uint64(80)
+// 节点地址带 api-key，和其它节点一样从环境变量读取，不写进代码
+var (
+	HTTPUrl = os.Getenv("RPC_HTTP_URL")
+	WSUrl   = os.Getenv("RPC_WS_URL") // WebSocket 节点
 )
 
+const nonceAccountSize = uint64(80)
+
 func SimulateAndSendTransaction(rpcClient *rpc.Client, transaction *solana.Transaction) (string, error) {
 	sopts := &rpc.SimulateTransactionOpts{
 		ReplaceRecentBlockhash: true,
@@ -68,7 +72,7 @@ func SimulateTransaction(rpcClient *rpc.Client, transaction *solana.Transaction)
 
 }
 
-func SendTransactionWithJupiter(rpcClient *rpc.Client, wsClient *ws.Client, txStr string, senderPrivateKey *solana.PrivateKey) (string, error) {
+func SendTransactionWithJupiter(rpcClient *rpc.Client, wsClient *ws.Client, txStr string, sender signer.Signer) (string, error) {
 	tx, err := solana.TransactionFromBase64(txStr)
 	if err != nil {
 		log.Fatalf("Invalid transaction: %v", err)
@@ -76,12 +80,7 @@ func SendTransactionWithJupiter(rpcClient *rpc.Client, wsClient *ws.Client, txSt
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return senderPrivateKey
-		},
-	)
-	if err != nil {
+	if err := sender.SignTransaction(tx); err != nil {
 		log.Fatal(err)
 	}
 
@@ -250,7 +249,7 @@ func SubForLogs(wsClient *ws.Client, programID solana.PublicKey, msg chan interf
 
 }
 
-func BatchBurnAndClose(client *rpc.Client, wallet solana.PrivateKey, tokens []struct {
+func BatchBurnAndClose(client *rpc.Client, wallet signer.Signer, tokens []struct {
 	Mint         solana.PublicKey
 	TokenAccount solana.PublicKey
 	Amount       uint64
@@ -276,25 +275,19 @@ func sendBurnAndCloseTx(
 	client *rpc.Client,
 	recentBlockhash solana.Hash,
 	instructions []solana.Instruction,
-	signer solana.PrivateKey, // 钱包私钥
+	wallet signer.Signer,
 ) (string, error) {
 	tx, err := solana.NewTransaction(
 		instructions,
 		recentBlockhash,
-		solana.TransactionPayer(signer.PublicKey()),
+		solana.TransactionPayer(wallet.PublicKey()),
 	)
 	if err != nil {
 		return "", fmt.Errorf("构建交易失败: %v", err)
 	}
 
 	// 签名交易
-	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(signer.PublicKey()) {
-			return &signer
-		}
-		return nil
-	})
-	if err != nil {
+	if err := wallet.SignTransaction(tx); err != nil {
 		return "", fmt.Errorf("签名失败: %v", err)
 	}
 
@@ -374,7 +367,7 @@ func buildBatchBurnAndCloseInstructions(
 	return instructions
 }
 
-func SendMemoWithTransfer(client *rpc.Client, sender solana.PrivateKey, receiver solana.PublicKey, lamports uint64, msg string) {
+func SendMemoWithTransfer(client *rpc.Client, sender signer.Signer, receiver solana.PublicKey, lamports uint64, msg string) {
 	// recentBlockhash, err := client.GetLatestBlockhash(context.TODO(), rpc.CommitmentFinalized)
 	// if err != nil {
 	// 	log.Fatal(err)
@@ -411,13 +404,7 @@ func SendMemoWithTransfer(client *rpc.Client, sender solana.PrivateKey, receiver
 		log.Fatal(err)
 	}
 
-	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(sender.PublicKey()) {
-			return &sender
-		}
-		return nil
-	})
-	if err != nil {
+	if err := sender.SignTransaction(tx); err != nil {
 		log.Fatal(err)
 	}
 
@@ -8,8 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"solana-bot/internal/signer"
+
 	solana "github.com/gagliardetto/solana-go"
-	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
@@ -31,7 +32,10 @@ func TestGetTx(t *testing.T) {
 
 func TestBurn(t *testing.T) {
 	rpcClient := rpc.New(HTTPUrl)
-	wallet := solana.MustPrivateKeyFromBase58("your private key")
+	wallet, err := signer.FromEnv("PRIVATE_KEY")
+	if err != nil {
+		t.Skip(err)
+	}
 
 	tokenAddress := "4PpMir7mjTkf3ixqEnUxpibSzCMmSnxfZLCKWmnXyJ5X"
 	mint := solana.MustPublicKeyFromBase58(tokenAddress)
@@ -69,12 +73,9 @@ func TestBurn(t *testing.T) {
 
 func TestTracker(t *testing.T) {
 	startTime := time.Now()
-	privateKey := "" // replace with your base58 private key
-
-	// Create a keypair from the secret key
-	keypair, err := solana.PrivateKeyFromBase58(privateKey)
+	keypair, err := signer.FromEnv("PRIVATE_KEY")
 	if err != nil {
-		log.Fatalf("Error creating keypair: %v", err)
+		t.Skip(err)
 	}
 
 	rpcUrl := "https://api.mainnet-beta.solana.com"
@@ -135,195 +136,16 @@ func TestTracker(t *testing.T) {
 
 func TestMemo(t *testing.T) {
 	rpcClient := rpc.New(HTTPUrl)
-	wallet := solana.MustPrivateKeyFromBase58("your private key")
+	wallet, err := signer.FromEnv("PRIVATE_KEY")
+	if err != nil {
+		t.Skip(err)
+	}
 
 	recevice := solana.MustPublicKeyFromBase58("nDn9URUkHhCH4eoLT2bLU3w6tXckfXx8ziVTmTceauQ")
 	SendMemoWithTransfer(rpcClient, wallet, recevice, 1, "I can help you sell SCM ")
 	// SendMemoWithTransfer(rpcClient, wallet, recevice, 1, "Transfer some tokens to me. I can attract more people.")
 }
 
-func TestNonceAccount(t *testing.T) {
-
-	client := rpc.New(rpc.MainNetBeta_RPC)
-
-	// 钱包
-	// wallet := solana.NewWallet()
-	wallet, err := solana.WalletFromPrivateKeyBase58("your private key")
-	if err != nil {
-		log.Fatal(err)
-	}
-	payer := wallet.PublicKey()
-
-	// payer, err := solana.PrivateKeyFromBase58(key)
-	// if err != nil {
-	// 	panic(err)
-	// }
-
-	// 创建 nonce account
-	nonceAccount := solana.NewWallet()
-
-	fmt.Println("Nonce account:", nonceAccount.PublicKey())
-	fmt.Println("PrivateKey:", nonceAccount.PrivateKey.String())
-
-	// 获取租金豁免的最小 lamports（nonce account 大小）
-	minBalance, err := client.GetMinimumBalanceForRentExemption(
-		context.TODO(),
-		nonceAccountSize,
-		rpc.CommitmentConfirmed,
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println("Rent-exempt balance:", minBalance)
-
-	// 获取最近 blockhash
-	recentBlockhashResp, err := client.GetLatestBlockhash(context.TODO(), rpc.CommitmentConfirmed)
-	if err != nil {
-		log.Fatal(err)
-	}
-	blockhash := recentBlockhashResp.Value.Blockhash
-
-	// 创建 + 初始化 nonce account
-	tx, err := solana.NewTransaction(
-		[]solana.Instruction{
-			system.NewCreateAccountInstruction(
-				minBalance,
-				nonceAccountSize,
-				system.ProgramID,
-				payer,
-				nonceAccount.PublicKey(),
-			).Build(),
-			system.NewInitializeNonceAccountInstruction(
-				payer,
-				nonceAccount.PublicKey(),
-				solana.SysVarRecentBlockHashesPubkey,
-				solana.SysVarRentPubkey,
-			).Build(),
-		},
-		blockhash,
-		solana.TransactionPayer(wallet.PublicKey()),
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 签名
-	tx.Sign(
-		func(pub solana.PublicKey) *solana.PrivateKey {
-			switch pub {
-			case wallet.PublicKey():
-				return &wallet.PrivateKey
-			case nonceAccount.PublicKey():
-				return &nonceAccount.PrivateKey
-			default:
-				return nil
-			}
-		},
-	)
-
-	// 发送交易
-	sig, err := client.SendTransactionWithOpts(
-		context.TODO(),
-		tx,
-		rpc.TransactionOpts{
-			SkipPreflight:       true,
-			PreflightCommitment: rpc.CommitmentFinalized,
-		},
-	)
-	if err != nil {
-		log.Fatalf("发送失败: %v", err)
-	}
-	fmt.Println("NonceAccount 创建成功:", sig.String())
-}
-
-// 34t46xZJq1TjRKcfDrinmrRhpCT9JvQjLcs75EEgbQvcqLBwuRnExM67qTsGT9pCwYK77sefuWWyiepG4WdCx9ef
-// 63RSXvZRPmVoZPrCXGDTwR35Ua7Xzj9AxGhbbFeuuHhfUGqPgf3DByNRr16HXCDiLiP5QwxRc77BvmJKk17xi9a4
-// 4znPKt2yHyVyWCaJNTjuvmiuWkpyKY59M69V2UPP1ahPJUEVrXJq1TSSXxkBqrPzCQwvQVwTwkTw1UhZNADotS5d
-func TestCloseNonceAccount(t *testing.T) {
-	client := rpc.New(rpc.MainNetBeta_RPC)
-
-	// 钱包
-	// wallet := solana.NewWallet()
-	wallet, err := solana.WalletFromPrivateKeyBase58("your private key")
-	if err != nil {
-		log.Fatal(err)
-	}
-	payer := wallet.PublicKey()
-
-	// payer, err := solana.PrivateKeyFromBase58(key)
-	// if err != nil {
-	// 	panic(err)
-	// }
-
-	// 创建 nonce account
-	nonceAccount := solana.MustPublicKeyFromBase58("4XxYgdxdSu7a3evtGcU1H7nbwm7LFq8MNKwTfdiKHEr3")
-
-	// 获取租金豁免的最小 lamports（nonce account 大小）
-	minBalance, err := client.GetMinimumBalanceForRentExemption(
-		context.TODO(),
-		nonceAccountSize,
-		rpc.CommitmentConfirmed,
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println("Rent-exempt balance:", minBalance)
-
-	// 获取最近 blockhash
-	recentBlockhashResp, err := client.GetLatestBlockhash(context.TODO(), rpc.CommitmentConfirmed)
-	if err != nil {
-		log.Fatal(err)
-	}
-	blockhash := recentBlockhashResp.Value.Blockhash
-
-	// 创建 + 初始化 nonce account
-	tx, err := solana.NewTransaction(
-		[]solana.Instruction{
-			system.NewWithdrawNonceAccountInstruction(
-				minBalance,
-				nonceAccount,
-				payer,
-				solana.SysVarRecentBlockHashesPubkey,
-				solana.SysVarRentPubkey,
-				payer,
-			).Build(),
-		},
-		blockhash,
-		solana.TransactionPayer(wallet.PublicKey()),
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 签名
-	tx.Sign(
-		func(pub solana.PublicKey) *solana.PrivateKey {
-			switch pub {
-			case wallet.PublicKey():
-				return &wallet.PrivateKey
-			default:
-				return nil
-			}
-		},
-	)
-
-	// 发送交易
-	sig, err := client.SendTransactionWithOpts(
-		context.TODO(),
-		tx,
-		rpc.TransactionOpts{
-			SkipPreflight:       true,
-			PreflightCommitment: rpc.CommitmentFinalized,
-		},
-	)
-	if err != nil {
-		log.Fatalf("发送失败: %v", err)
-	}
-	fmt.Println("NonceAccount 创建成功:", sig.String())
-}
-
 func TestGetNonce(t *testing.T) {
 	client := rpc.New(rpc.MainNetBeta_RPC)
 	nonceAccount := solana.MustPublicKeyFromBase58("3Fx3o9PnUmPaa2VPbmozrFvyLPYymoLn14pkxhkJbfGx")
@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"time"
 
+	"solana-bot/internal/signer"
+
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
@@ -21,31 +23,31 @@ SolanaTracker struct is used to interact with the Solana swap API.
 Fields:
   - BaseURL: The base URL for the Solana swap API.
   - RPC: The URL for the Solana RPC node.
-  - Keypair: The private key used for signing transactions.
+  - Signer: The wallet used for signing transactions.
 */
 type SolanaTracker struct {
-	BaseURL string            // The base URL for the Solana Swap API
-	RPC     string            // The URL for the Solana RPC node.
-	Keypair solana.PrivateKey // The private key used for signing transactions.
+	BaseURL string        // The base URL for the Solana Swap API
+	RPC     string        // The URL for the Solana RPC node.
+	Signer  signer.Signer // The wallet used for signing transactions.
 }
 
 /*
-NewSolanaTracker creates a new instance of SolanaTracker with the given keypair and RPC URL.
+NewSolanaTracker creates a new instance of SolanaTracker with the given signer and RPC URL.
 
 The base URL for the Solana Swap API is hardcoded as "https://swap-v2.solanatracker.io".
 
 Parameters:
-  - keypair: The private key used for signing transactions.
+  - s: The wallet used for signing transactions.
   - rpcURL: The URL for the Solana RPC node.
 
 Returns:
-  - *SolanaTracker: A pointer to a new SolanaTracker struct with the provided keypair, rpcURL, and base URL.
+  - *SolanaTracker: A pointer to a new SolanaTracker struct with the provided signer, rpcURL, and base URL.
 */
-func NewSolanaTracker(keypair solana.PrivateKey, rpcURL string) *SolanaTracker {
+func NewSolanaTracker(s signer.Signer, rpcURL string) *SolanaTracker {
 	return &SolanaTracker{
 		BaseURL: "https://swap-v2.solanatracker.io",
 		RPC:     rpcURL,
-		Keypair: keypair,
+		Signer:  s,
 	}
 }
 
@@ -224,13 +226,7 @@ func (st *SolanaTracker) PerformSwap(trackerSwapResponse *TrackerSwapResponse, o
 	tx.Message.RecentBlockhash = recentBlockhash.Value.Blockhash
 
 	// Sign the transaction
-	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if st.Keypair.PublicKey().Equals(key) {
-			return &st.Keypair
-		}
-		return nil
-	})
-	if err != nil {
+	if err := st.Signer.SignTransaction(tx); err != nil {
 		return "", fmt.Errorf("error signing transaction: %w", err)
 	}
 
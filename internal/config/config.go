@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/rest"
 )
@@ -29,5 +31,110 @@ type BannerConf struct {
 }
 
 type BotConf struct {
-	Player string `json:",default=123"`
+	Player     string               `json:",default=123"`
+	Strategies []string             `json:",optional"`                  // 启用的策略（mint/smart/scm 等），命令行 --mint、--strategy 追加
+	Positions  string               `json:",default=data/positions.db"` // 仓位库文件，买卖都会写入，重启后据此恢复
+	Reconcile  ReconcileConf        `json:",optional"`
+	Tip        TipConf              `json:",optional"`
+	Channels   []ChannelConf        `json:",optional"` // 为空时使用内置的 Jito、BlockRazor、Astralane
+	Routes     map[string]RouteConf `json:",optional"` // 按模式（mint/smart/scm）选择买卖使用的通道
+	Nonce      NonceConf            `json:",optional"`
+	Signer     SignerConf           `json:",optional"`
+	Wallets    WalletsConf          `json:",optional"` // 多个交易钱包，为空时只用 signer、nonce 配置的一个钱包
+	Preflight  PreflightConf        `json:",optional"`
+	Risk       RiskConf             `json:",optional"`
+	// 地址表，有配置时交易以 v0 格式发送，用 solana-bot alt sync 创建
+	LookupTables []string `json:",optional"`
+}
+
+// ReconcileConf 启动时核对钱包里的 token 和仓位库
+type ReconcileConf struct {
+	Known   string `json:",default=resume,options=resume|liquidate"` // 仓位库里有的持仓：按记录的退出方式继续，或直接清仓
+	Unknown string `json:",default=ignore,options=ignore|liquidate"` // 仓位库里没有的持仓：默认只记日志，明确配置 liquidate 时查到池子后清仓
+}
+
+// RiskConf 每次开仓前检查的风控上限，数值为 0 时不限制
+type RiskConf struct {
+	MaxExposure     uint64        `json:",optional"`    // 所有未平仓位合计投入的 lamports
+	MaxPerStrategy  int           `json:",optional"`    // 每个策略（mint/smart/scm）同时持有的仓位数
+	MaxPerLeader    int           `json:",optional"`    // 跟随同一个钱包同时持有的仓位数
+	MaxPerLaunchpad int           `json:",optional"`    // 同一种池子（PumpFun、RaydiumLaunchpad 等）同时持有的仓位数
+	MaxPoolShare    float64       `json:",optional"`    // 单笔买入最多占池子 SOL 储备的比例，如 0.02
+	LossLimit       uint64        `json:",optional"`    // 窗口内已实现亏损合计超过这么多 lamports 时暂停开仓，需要手动恢复
+	LossWindow      time.Duration `json:",default=24h"` // 统计已实现亏损的滚动窗口
+}
+
+// PreflightConf 发送前模拟交易：按实际消耗设置 CU 上限，模拟出错的交易不发送
+type PreflightConf struct {
+	Enabled bool    `json:",optional"`
+	Margin  float64 `json:",optional"` // CU 上限在模拟消耗之上多留的比例，0 为 0.1
+	Samples int     `json:",optional"` // 同一池子类型、指令组合模拟这么多次后直接使用学到的上限，0 为 3
+	// 学到的上限多久后过期重新模拟，0 为 10m；链上因 CU 不够失败时立即丢掉
+	TTL time.Duration `json:",optional"`
+}
+
+// WalletsConf 交易钱包及新开仓时选择钱包的方式
+type WalletsConf struct {
+	Policy     string       `json:",default=round_robin,options=round_robin|least_exposure|strategy"`
+	MinBalance uint64       `json:",optional"` // SOL 余额低于该值的钱包不再开仓，0 为 0.5 SOL
+	List       []WalletConf `json:",optional"`
+}
+
+// WalletConf 一个交易钱包，nonce 账户的 authority 必须是该钱包
+type WalletConf struct {
+	Name       string
+	Signer     SignerConf `json:",optional"`
+	Strategies []string   `json:",optional"` // 只用于这些模式（mint/smart/scm），为空时不限；strategy 方式下优先使用
+	Nonce      NonceConf  `json:",optional"`
+}
+
+// SignerConf 交易钱包的签名方式
+type SignerConf struct {
+	Type          string     `json:",default=env,options=env|keystore|remote"`
+	Env           string     `json:",default=PRIVATE_KEY"`         // env：base58 私钥所在的环境变量
+	Keystore      string     `json:",optional"`                    // keystore：加密私钥文件，用 solana-bot signer create 生成
+	PassphraseEnv string     `json:",default=KEYSTORE_PASSPHRASE"` // keystore：口令所在的环境变量，为空时在终端输入
+	Remote        string     `json:",optional"`                    // remote：unix:///path/to.sock 或 http://host:port
+	TokenEnv      string     `json:",default=SIGNER_TOKEN"`        // remote：签名服务共享口令所在的环境变量，http 端点必须设置
+	Policy        PolicyConf `json:",optional"`
+}
+
+// PolicyConf 签名前的交易检查，防止聚合器 API 返回的指令转走钱包资产；默认开启
+type PolicyConf struct {
+	Disabled       bool     `json:",optional"`
+	Programs       []string `json:",optional"` // 内置白名单之外允许调用的程序
+	Tips           []string `json:",optional"` // 通道小费账户之外允许转入 SOL 的账户
+	MaxLamports    uint64   `json:",optional"` // 每笔交易从钱包转出的 SOL 上限（只算顶层指令，含优先费），0 为 1 SOL
+	MaxPriorityFee uint64   `json:",optional"` // 每笔交易的优先费上限（CU 单价 × CU 上限），0 为 0.01 SOL
+}
+
+// NonceConf durable nonce 账户池，账户的 authority 为 bot 钱包
+type NonceConf struct {
+	Accounts []string `json:",optional"` // 为空时使用内置的账户
+	Size     int      `json:",optional"` // 只使用前 Size 个账户，0 表示全部
+}
+
+// ChannelConf 一个交易发送通道
+type ChannelConf struct {
+	Name      string   // 通道名，路由中用它引用
+	Type      string   `json:",options=jito|blockrazor|astralane|0slot|tempgoral"`
+	Enabled   bool     `json:",default=true"`
+	Weight    int      `json:",default=1"` // 权重高的通道先发送
+	Endpoints []string // 各区域端点；其中的 {auth} 会替换为 AuthEnv 的值
+	AuthEnv   string   `json:",optional"` // 鉴权 key 所在的环境变量
+	Tips      []string `json:",optional"` // 小费账户，为空时用该类型内置的列表
+}
+
+// RouteConf 一个模式买入、卖出使用的通道名，为空表示所有启用的通道
+type RouteConf struct {
+	Buy  []string `json:",optional"`
+	Sell []string `json:",optional"`
+}
+
+// TipConf 各发送通道小费竞价的目标和预算，金额单位 lamports
+type TipConf struct {
+	Target      float64 `json:",default=0.8"`     // 期望的落地概率
+	TargetSlots uint64  `json:",default=2"`       // 发送后多少个 slot 内落地算成功
+	Min         uint64  `json:",default=100000"`  // 最低出价
+	Max         uint64  `json:",default=5000000"` // 单笔预算上限
 }
@@ -0,0 +1,150 @@
+package confirm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+// Result 把流里的交易转换成 getTransaction 的结果，调用方不用再查一次 RPC
+func Result(slot uint64, tx *pb.SubscribeUpdateTransactionInfo) *rpc.GetTransactionResult {
+	meta := tx.GetMeta()
+	out := &rpc.GetTransactionResult{
+		Slot: slot,
+		Meta: &rpc.TransactionMeta{
+			Fee:               meta.GetFee(),
+			PreBalances:       meta.GetPreBalances(),
+			PostBalances:      meta.GetPostBalances(),
+			PreTokenBalances:  tokenBalances(meta.GetPreTokenBalances()),
+			PostTokenBalances: tokenBalances(meta.GetPostTokenBalances()),
+			LogMessages:       meta.GetLogMessages(),
+		},
+	}
+	if meta != nil {
+		out.Meta.ComputeUnitsConsumed = meta.ComputeUnitsConsumed
+	}
+	if meta.GetErr() != nil {
+		out.Meta.Err = TransactionError(meta.GetErr().GetErr())
+	}
+	return out
+}
+
+func tokenBalances(in []*pb.TokenBalance) []rpc.TokenBalance {
+	out := make([]rpc.TokenBalance, 0, len(in))
+	for _, b := range in {
+		mint, err := solana.PublicKeyFromBase58(b.GetMint())
+		if err != nil {
+			continue
+		}
+		tb := rpc.TokenBalance{
+			AccountIndex: uint16(b.GetAccountIndex()),
+			Mint:         mint,
+			UiTokenAmount: &rpc.UiTokenAmount{
+				Amount:         b.GetUiTokenAmount().GetAmount(),
+				Decimals:       uint8(b.GetUiTokenAmount().GetDecimals()),
+				UiAmountString: b.GetUiTokenAmount().GetUiAmountString(),
+			},
+		}
+		if owner, err := solana.PublicKeyFromBase58(b.GetOwner()); err == nil {
+			tb.Owner = &owner
+		}
+		if program, err := solana.PublicKeyFromBase58(b.GetProgramId()); err == nil {
+			tb.ProgramId = &program
+		}
+		out = append(out, tb)
+	}
+	return out
+}
+
+// TransactionError 的变体，顺序和 solana-sdk 一致
+var transactionErrors = []string{
+	"AccountInUse", "AccountLoadedTwice", "AccountNotFound", "ProgramAccountNotFound",
+	"InsufficientFundsForFee", "InvalidAccountForFee", "AlreadyProcessed", "BlockhashNotFound",
+	"InstructionError", "CallChainTooDeep", "MissingSignatureForFee", "InvalidAccountIndex",
+	"SignatureFailure", "InvalidProgramForExecution", "SanitizeFailure", "ClusterMaintenance",
+	"AccountBorrowOutstanding", "WouldExceedMaxBlockCostLimit", "UnsupportedVersion", "InvalidWritableAccount",
+	"WouldExceedMaxAccountCostLimit", "WouldExceedAccountDataBlockLimit", "TooManyAccountLocks", "AddressLookupTableNotFound",
+	"InvalidAddressLookupTableOwner", "InvalidAddressLookupTableData", "InvalidAddressLookupTableIndex", "InvalidRentPayingAccount",
+	"WouldExceedMaxVoteCostLimit", "WouldExceedAccountDataTotalLimit", "DuplicateInstruction", "InsufficientFundsForRent",
+	"MaxLoadedAccountsDataSizeExceeded", "InvalidLoadedAccountsDataSizeLimit", "ResanitizationNeeded", "ProgramExecutionTemporarilyRestricted",
+	"UnbalancedTransaction", "ProgramCacheHitMaxLimit",
+}
+
+// InstructionError 的变体，顺序和 solana-sdk 一致
+var instructionErrors = []string{
+	"GenericError", "InvalidArgument", "InvalidInstructionData", "InvalidAccountData",
+	"AccountDataTooSmall", "InsufficientFunds", "IncorrectProgramId", "MissingRequiredSignature",
+	"AccountAlreadyInitialized", "UninitializedAccount", "UnbalancedInstruction", "ModifiedProgramId",
+	"ExternalAccountLamportSpend", "ExternalAccountDataModified", "ReadonlyLamportChange", "ReadonlyDataModified",
+	"DuplicateAccountIndex", "ExecutableModified", "RentEpochModified", "NotEnoughAccountKeys",
+	"AccountDataSizeChanged", "AccountNotExecutable", "AccountBorrowFailed", "AccountBorrowOutstanding",
+	"DuplicateAccountOutOfSync", "Custom", "InvalidError", "ExecutableDataModified",
+	"ExecutableLamportChange", "ExecutableAccountNotRentExempt", "UnsupportedProgramId", "CallDepth",
+	"MissingAccount", "ReentrancyNotAllowed", "MaxSeedLengthExceeded", "InvalidSeeds",
+	"InvalidRealloc", "ComputationalBudgetExceeded", "PrivilegeEscalation", "ProgramEnvironmentSetupFailure",
+	"ProgramFailedToComplete", "ProgramFailedToCompile", "Immutable", "IncorrectAuthority",
+	"BorshIoError", "AccountNotRentExempt", "InvalidAccountOwner", "ArithmeticOverflow",
+	"UnsupportedSysvar", "IllegalOwner", "MaxAccountsDataAllocationsExceeded", "MaxAccountsReallocsExceeded",
+	"MaxInstructionTraceLengthExceeded", "BuiltinProgramsMustConsumeComputeUnits",
+}
+
+// TransactionError 解析流里 bincode 编码的交易错误，结构和 RPC 返回的 JSON 一致，例如
+// {"InstructionError": [2, {"Custom": 6001}]}
+func TransactionError(data []byte) interface{} {
+	if len(data) < 4 {
+		return fmt.Sprintf("TransactionError(%x)", data)
+	}
+	variant := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	if int(variant) >= len(transactionErrors) {
+		return fmt.Sprintf("TransactionError(%d)", variant)
+	}
+	name := transactionErrors[variant]
+
+	switch name {
+	case "InstructionError":
+		if len(data) < 5 {
+			return name
+		}
+		index := int(data[0])
+		return map[string]interface{}{name: []interface{}{index, instructionError(data[1:])}}
+	case "DuplicateInstruction":
+		if len(data) < 1 {
+			return name
+		}
+		return map[string]interface{}{name: int(data[0])}
+	case "InsufficientFundsForRent", "ProgramExecutionTemporarilyRestricted":
+		if len(data) < 1 {
+			return name
+		}
+		return map[string]interface{}{name: map[string]interface{}{"account_index": int(data[0])}}
+	}
+	return name
+}
+
+func instructionError(data []byte) interface{} {
+	variant := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	if int(variant) >= len(instructionErrors) {
+		return fmt.Sprintf("InstructionError(%d)", variant)
+	}
+	name := instructionErrors[variant]
+
+	switch name {
+	case "Custom":
+		if len(data) < 4 {
+			return name
+		}
+		return map[string]interface{}{name: binary.LittleEndian.Uint32(data)}
+	case "BorshIoError":
+		// 字符串：u64 长度 + 内容
+		if len(data) < 8 || uint64(len(data)-8) < binary.LittleEndian.Uint64(data) {
+			return name
+		}
+		return map[string]interface{}{name: string(data[8 : 8+binary.LittleEndian.Uint64(data)])}
+	}
+	return name
+}
@@ -0,0 +1,180 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"solana-bot/internal/client"
+	"solana-bot/internal/stream"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 流里等这么久还没看到交易才开始用 RPC 轮询
+	fallbackAfter = 1500 * time.Millisecond
+	pollInterval  = 400 * time.Millisecond
+	// 先于 Wait 到达的交易保留这么久
+	seenTTL = 30 * time.Second
+)
+
+var ErrTimeout = errors.New("transaction not confirmed")
+
+type seenTx struct {
+	result *rpc.GetTransactionResult
+	at     time.Time
+}
+
+// Tracker 订阅自己钱包上的交易，签名一出现在流里就返回它的执行结果；
+// 流断开或迟迟没有推送时才退回 RPC 轮询 getTransaction
+type Tracker struct {
+	mu      sync.Mutex
+	waiters map[string][]chan *rpc.GetTransactionResult
+	seen    map[string]seenTx
+	fetch   func(sig string) (*rpc.GetTransactionResult, error)
+	healthy func() bool
+	now     func() time.Time
+}
+
+func NewTracker(rpcClient *rpc.Client) *Tracker {
+	return &Tracker{
+		waiters: make(map[string][]chan *rpc.GetTransactionResult),
+		seen:    make(map[string]seenTx),
+		fetch: func(sig string) (*rpc.GetTransactionResult, error) {
+			return client.GetTransactionByHash(rpcClient, sig)
+		},
+		healthy: streamHealthy,
+		now:     time.Now,
+	}
+}
+
+// streamHealthy 至少有一条 Geyser 订阅在线
+func streamHealthy() bool {
+	for _, st := range stream.Statuses() {
+		if st.State == stream.StateConnected {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 订阅 accounts 参与的交易（包括失败的），直到 ctx 结束
+func (t *Tracker) Run(ctx context.Context, hub *stream.Hub, accounts ...string) {
+	vote := false
+	subscribe := make(chan interface{})
+	hub.Subscribe(ctx, &pb.SubscribeRequest{
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"confirm_sub": {Vote: &vote, AccountInclude: accounts},
+		},
+	}, subscribe)
+
+	ticker := time.NewTicker(seenTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.prune()
+		case msg, ok := <-subscribe:
+			if !ok {
+				return
+			}
+			update := msg.(*stream.StreamMessage).Data.(*pb.SubscribeUpdate).GetTransaction()
+			tx := update.GetTransaction()
+			if tx == nil {
+				continue
+			}
+			t.Resolve(solana.SignatureFromBytes(tx.GetSignature()).String(), Result(update.GetSlot(), tx))
+		}
+	}
+}
+
+// Resolve 交给等待该签名的调用方；还没有人等时先记下来。paper 通道也用它交付模拟的结果
+func (t *Tracker) Resolve(sig string, result *rpc.GetTransactionResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	waiters := t.waiters[sig]
+	delete(t.waiters, sig)
+	for _, ch := range waiters {
+		ch <- result
+	}
+	t.seen[sig] = seenTx{result: result, at: t.now()}
+}
+
+func (t *Tracker) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	for sig, s := range t.seen {
+		if now.Sub(s.at) > seenTTL {
+			delete(t.seen, sig)
+		}
+	}
+}
+
+func (t *Tracker) remove(sig string, ch chan *rpc.GetTransactionResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	waiters := t.waiters[sig]
+	for i, w := range waiters {
+		if w == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(t.waiters, sig)
+	} else {
+		t.waiters[sig] = waiters
+	}
+}
+
+// Wait 等待签名为 sig 的交易上链，返回所在 slot 和执行结果（失败的交易 Meta.Err 非空），
+// 超时由 ctx 控制
+func (t *Tracker) Wait(ctx context.Context, sig string) (*rpc.GetTransactionResult, error) {
+	ch := make(chan *rpc.GetTransactionResult, 1)
+	t.mu.Lock()
+	if s, ok := t.seen[sig]; ok {
+		t.mu.Unlock()
+		return s.result, nil
+	}
+	t.waiters[sig] = append(t.waiters[sig], ch)
+	t.mu.Unlock()
+	defer t.remove(sig, ch)
+
+	delay := fallbackAfter
+	if !t.healthy() {
+		delay = 0
+	}
+	fallback := time.NewTimer(delay)
+	defer fallback.Stop()
+
+	var poll <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(ErrTimeout, ctx.Err())
+		case result := <-ch:
+			return result, nil
+		case <-fallback.C:
+			ticker := time.NewTicker(pollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+			logx.Infof("[confirm] %s: not seen in stream, polling rpc", sig)
+			if result, err := t.fetch(sig); err == nil {
+				return result, nil
+			}
+		case <-poll:
+			if result, err := t.fetch(sig); err == nil {
+				return result, nil
+			}
+		}
+	}
+}
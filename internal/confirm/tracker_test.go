@@ -0,0 +1,100 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func newTestTracker(healthy bool, fetch func(string) (*rpc.GetTransactionResult, error)) *Tracker {
+	t := NewTracker(nil)
+	t.healthy = func() bool { return healthy }
+	t.fetch = fetch
+	return t
+}
+
+func TestTrackerStream(t *testing.T) {
+	fetched := 0
+	tr := newTestTracker(true, func(string) (*rpc.GetTransactionResult, error) {
+		fetched++
+		return nil, errors.New("not found")
+	})
+
+	// 流先于 Wait 推送
+	tr.Resolve("a", &rpc.GetTransactionResult{Slot: 1})
+	got, err := tr.Wait(context.Background(), "a")
+	if err != nil || got.Slot != 1 {
+		t.Fatalf("wait a = %v, %v", got, err)
+	}
+
+	// Wait 之后推送
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tr.Resolve("b", &rpc.GetTransactionResult{Slot: 2})
+	}()
+	got, err = tr.Wait(context.Background(), "b")
+	if err != nil || got.Slot != 2 {
+		t.Fatalf("wait b = %v, %v", got, err)
+	}
+	if fetched != 0 {
+		t.Fatalf("rpc fetched %d times while stream is healthy", fetched)
+	}
+	if len(tr.waiters) != 0 {
+		t.Fatalf("waiters left: %v", tr.waiters)
+	}
+
+	// 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := tr.Wait(ctx, "c"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("wait c err = %v", err)
+	}
+}
+
+func TestTrackerFallback(t *testing.T) {
+	tr := newTestTracker(false, func(sig string) (*rpc.GetTransactionResult, error) {
+		return &rpc.GetTransactionResult{Slot: 3}, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, err := tr.Wait(ctx, "d")
+	if err != nil || got.Slot != 3 {
+		t.Fatalf("wait d = %v, %v", got, err)
+	}
+}
+
+func TestResult(t *testing.T) {
+	tx := &pb.SubscribeUpdateTransactionInfo{
+		Meta: &pb.TransactionStatusMeta{
+			// InstructionError(2, Custom(6001))
+			Err:          &pb.TransactionError{Err: []byte{8, 0, 0, 0, 2, 25, 0, 0, 0, 0x71, 0x17, 0, 0}},
+			Fee:          5000,
+			PreBalances:  []uint64{100},
+			PostBalances: []uint64{90},
+			PostTokenBalances: []*pb.TokenBalance{{
+				AccountIndex:  1,
+				Mint:          "So11111111111111111111111111111111111111112",
+				UiTokenAmount: &pb.UiTokenAmount{Amount: "42", Decimals: 9},
+			}},
+		},
+	}
+	got := Result(7, tx)
+	if got.Slot != 7 || got.Meta.Fee != 5000 || got.Meta.PostBalances[0] != 90 {
+		t.Fatalf("result = %+v", got)
+	}
+	if b := got.Meta.PostTokenBalances; len(b) != 1 || b[0].UiTokenAmount.Amount != "42" || b[0].Mint.String() != "So11111111111111111111111111111111111111112" {
+		t.Fatalf("token balances = %+v", b)
+	}
+	want := map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": uint32(6001)}}}
+	if !reflect.DeepEqual(got.Meta.Err, want) {
+		t.Fatalf("err = %#v", got.Meta.Err)
+	}
+	if e := TransactionError([]byte{7, 0, 0, 0}); e != "BlockhashNotFound" {
+		t.Fatalf("err = %#v", e)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"solana-bot/internal/dex/meteora/instructions"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 
 	"github.com/gagliardetto/solana-go"
 	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
@@ -12,7 +13,8 @@ import (
 )
 
 func GetBuyTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
+	nonce *global.NonceLease, // 调用方租用的 nonce，发送结束后由调用方归还
 	config, pool, baseVault, quoteVault, baseMint, quoteMint solana.PublicKey,
 	sqrtPrice *big.Int,
 	maxAmountIn *big.Int,
@@ -23,10 +25,9 @@ func GetBuyTx(
 ) (*solana.Transaction, error) {
 
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 	amountInAfterOurFee := new(big.Int).Sub(maxAmountIn, big.NewInt(int64(fee)))
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	// nonce advance的操作一定要在第一個instruction
 	instrs = append(instrs, system.NewAdvanceNonceAccountInstruction(nonceAccount, solana.SysVarRecentBlockHashesPubkey, signerAndOwner.PublicKey()).Build())
 
@@ -81,12 +82,12 @@ func GetBuyTx(
 		minOut, // minOut
 	))
 	// spew.Dump(instrs)
-	tx, err := BuildTransaction(nonceHash, signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(nonceHash, signerAndOwner, instrs...)
 	return tx, err
 }
 
 func GetSellTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
 	config, pool, baseVault, quoteVault, baseMint, quoteMint solana.PublicKey,
 	sqrtPrice *big.Int,
 	maxAmountIn *big.Int,
@@ -97,7 +98,6 @@ func GetSellTx(
 ) (*solana.Transaction, error) {
 
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 	amountInAfterOurFee := new(big.Int).Sub(maxAmountIn, big.NewInt(int64(fee)))
 
 	// nonceAccount, nonceHash := global.GetNonceAccountAndHash()
@@ -151,7 +151,7 @@ func GetSellTx(
 		minOut, // minOut
 	))
 	// spew.Dump(instrs)
-	tx, err := BuildTransaction(global.GetBlockHash(), signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(global.GetBlockHash(), signerAndOwner, instrs...)
 	return tx, err
 }
 
@@ -193,22 +193,17 @@ func EstimateBackrunBuyOutAmount(
 	return out
 }
 
-func BuildTransaction(nonceHash solana.Hash, signers []solana.PrivateKey, signer solana.PrivateKey, instrs ...solana.Instruction) (*solana.Transaction, error) {
+func BuildTransaction(nonceHash solana.Hash, s signer.Signer, instrs ...solana.Instruction) (*solana.Transaction, error) {
 	tx, err := solana.NewTransaction(
 		instrs,
 		nonceHash,
-		solana.TransactionPayer(signers[0].PublicKey()),
+		solana.TransactionPayer(s.PublicKey()),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &signer
-		},
-	)
-	if err != nil {
+	if err := s.SignTransaction(tx); err != nil {
 		return nil, err
 	}
 	return tx, nil
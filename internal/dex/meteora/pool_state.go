@@ -0,0 +1,70 @@
+package meteora
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// VirtualPool 账户（DBC 池子）中关心的字段偏移：
+// discriminator(8) + volatility_tracker(64) + config/creator/base_mint/base_vault/quote_vault(32*5)
+const (
+	virtualPoolBaseReserveOffset = 8 + 64 + 32*5
+	virtualPoolSqrtPriceOffset   = virtualPoolBaseReserveOffset + 8*6
+	virtualPoolMigratedOffset    = virtualPoolSqrtPriceOffset + 16 + 8 + 1
+	virtualPoolMinSize           = virtualPoolMigratedOffset + 1
+)
+
+var virtualPoolDiscriminator = accountDiscriminator("VirtualPool")
+
+var q64 = new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 64))
+
+// VirtualPoolState DBC 池子的链上状态
+type VirtualPoolState struct {
+	BaseReserve  uint64
+	QuoteReserve uint64
+	SqrtPrice    *big.Int // Q64.64
+	IsMigrated   bool
+}
+
+// DecodeVirtualPool 解析 DBC VirtualPool 账户数据
+func DecodeVirtualPool(data []byte) (*VirtualPoolState, error) {
+	if len(data) < virtualPoolMinSize {
+		return nil, errors.New("meteora: virtual pool data too short")
+	}
+	if string(data[:8]) != string(virtualPoolDiscriminator[:]) {
+		return nil, errors.New("meteora: not a virtual pool account")
+	}
+	lo := binary.LittleEndian.Uint64(data[virtualPoolSqrtPriceOffset:])
+	hi := binary.LittleEndian.Uint64(data[virtualPoolSqrtPriceOffset+8:])
+	sqrtPrice := new(big.Int).Lsh(new(big.Int).SetUint64(hi), 64)
+	sqrtPrice.Or(sqrtPrice, new(big.Int).SetUint64(lo))
+	return &VirtualPoolState{
+		BaseReserve:  binary.LittleEndian.Uint64(data[virtualPoolBaseReserveOffset:]),
+		QuoteReserve: binary.LittleEndian.Uint64(data[virtualPoolBaseReserveOffset+8:]),
+		SqrtPrice:    sqrtPrice,
+		IsMigrated:   data[virtualPoolMigratedOffset] != 0,
+	}, nil
+}
+
+// Price 返回每个整 token 值多少 quote（按 decimals 换算）
+func (s *VirtualPoolState) Price(baseDecimals, quoteDecimals int) *big.Float {
+	p := new(big.Float).Quo(new(big.Float).SetInt(s.SqrtPrice), q64)
+	p.Mul(p, p)
+	scale := new(big.Float).SetFloat64(1)
+	for i := 0; i < baseDecimals; i++ {
+		scale.Mul(scale, big.NewFloat(10))
+	}
+	for i := 0; i < quoteDecimals; i++ {
+		scale.Quo(scale, big.NewFloat(10))
+	}
+	return p.Mul(p, scale)
+}
+
+func accountDiscriminator(name string) [8]byte {
+	var d [8]byte
+	sum := sha256.Sum256([]byte("account:" + name))
+	copy(d[:], sum[:8])
+	return d
+}
@@ -0,0 +1,153 @@
+package pump
+
+import (
+	"context"
+	"errors"
+
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var ErrPoolNotFound = errors.New("pump: pool not found")
+
+// pump 曲线账户里 creator 的位置：8 字节 discriminator + 5 个 u64 + complete
+const bondingCurveCreatorOffset = 49
+
+// PumpAMM Pool 账户布局：discriminator、bump(u8)、index(u16)、creator、base_mint、quote_mint、lp_mint、
+// pool_base_token_account、pool_quote_token_account、lp_supply(u64)、coin_creator
+const (
+	ammPoolBaseMintOffset     = 43
+	ammPoolQuoteMintOffset    = 75
+	ammPoolBaseAccountOffset  = 139
+	ammPoolQuoteAccountOffset = 171
+	ammPoolCoinCreatorOffset  = 211
+	ammPoolSize               = 243
+)
+
+// GlobalConfig 账户里 protocol_fee_recipients 的位置：discriminator、admin、两个 u64 费率、disable_flags
+const ammGlobalFeeRecipientsOffset = 57
+
+// BondingCurveAddress mint 的 pump 曲线账户
+func BondingCurveAddress(mint solana.PublicKey) solana.PublicKey {
+	addr, _, _ := solana.FindProgramAddress([][]byte{[]byte("bonding-curve"), mint.Bytes()}, PUMPManager)
+	return addr
+}
+
+// CanonicalAmmPool 曲线完成后迁移到 PumpAMM 的池子：creator 为 pump 的 pool-authority，index 为 0
+func CanonicalAmmPool(mint solana.PublicKey) solana.PublicKey {
+	authority, _, _ := solana.FindProgramAddress([][]byte{[]byte("pool-authority"), mint.Bytes()}, PUMPManager)
+	index := make([]byte, 2)
+	pool, _, _ := solana.FindProgramAddress([][]byte{
+		[]byte("pool"), index, authority.Bytes(), mint.Bytes(), solana.WrappedSol.Bytes(),
+	}, PUMPSWAP_PROGRAM_ID)
+	return pool
+}
+
+// FindPumpFunPool 从链上查 mint 的 pump 曲线，返回和解析交易得到的结构一致的池子数据；
+// complete 为 true 时曲线已经完成，应改用 FindPumpAmmPool
+func FindPumpFunPool(ctx context.Context, client *rpc.Client, mint solana.PublicKey) (pool *solanaswapgo.PumpFunPool, complete bool, err error) {
+	bondingCurve := BondingCurveAddress(mint)
+	globalSettings, _, _ := solana.FindProgramAddress([][]byte{[]byte("global")}, PUMPManager)
+	res, err := client.GetMultipleAccountsWithOpts(ctx, []solana.PublicKey{bondingCurve, globalSettings}, &rpc.GetMultipleAccountsOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(res.Value) != 2 || res.Value[0] == nil || res.Value[1] == nil {
+		return nil, false, ErrPoolNotFound
+	}
+	pool, complete, err = decodePumpFunPool(mint, res.Value[0].Data.GetBinary(), res.Value[1].Data.GetBinary())
+	return pool, complete, err
+}
+
+func decodePumpFunPool(mint solana.PublicKey, curveData, globalData []byte) (*solanaswapgo.PumpFunPool, bool, error) {
+	curve, err := DecodeBondingCurve(curveData)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(curveData) < bondingCurveCreatorOffset+32 {
+		return nil, false, errors.New("pumpfun: bonding curve without creator")
+	}
+	var global GlobalSettingsLayout
+	if err := decode(globalData, &global); err != nil {
+		return nil, false, err
+	}
+	creator := solana.PublicKeyFromBytes(curveData[bondingCurveCreatorOffset : bondingCurveCreatorOffset+32])
+
+	bondingCurve := BondingCurveAddress(mint)
+	globalSettings, _, _ := solana.FindProgramAddress([][]byte{[]byte("global")}, PUMPManager)
+	creatorVault, _, _ := solana.FindProgramAddress([][]byte{[]byte("creator-vault"), creator.Bytes()}, PUMPManager)
+	eventAuthority, _, _ := solana.FindProgramAddress([][]byte{[]byte("__event_authority")}, PUMPManager)
+	associatedBondingCurve, _, _ := solana.FindAssociatedTokenAddress(bondingCurve, mint)
+	return &solanaswapgo.PumpFunPool{
+		Global:                 globalSettings,
+		FeeRecipient:           global.FeeRecipient,
+		Mint:                   mint,
+		BondingCurve:           bondingCurve,
+		AssociatedBondingCurve: associatedBondingCurve,
+		CreatorVault:           creatorVault,
+		EventAuthority:         eventAuthority,
+		VirtualSolReserves:     curve.VirtualSOLReserves,
+		VirtualTokenReserves:   curve.VirtualTokenReserves,
+		RealSOLReserves:        curve.RealSOLReserves,
+		RealTokenReserves:      curve.RealTokenReserves,
+	}, curve.Complete, nil
+}
+
+// FindPumpAmmPool 从链上查 mint 迁移后的 PumpAMM 池子和当前储备
+func FindPumpAmmPool(ctx context.Context, client *rpc.Client, mint solana.PublicKey) (*solanaswapgo.PumpAmmPool, error) {
+	poolAddress := CanonicalAmmPool(mint)
+	globalConfig, _, _ := solana.FindProgramAddress([][]byte{[]byte("global_config")}, PUMPSWAP_PROGRAM_ID)
+	res, err := client.GetMultipleAccountsWithOpts(ctx, []solana.PublicKey{poolAddress, globalConfig}, &rpc.GetMultipleAccountsOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Value) != 2 || res.Value[0] == nil || res.Value[1] == nil {
+		return nil, ErrPoolNotFound
+	}
+	pool, err := decodePumpAmmPool(poolAddress, globalConfig, res.Value[0].Data.GetBinary(), res.Value[1].Data.GetBinary())
+	if err != nil {
+		return nil, err
+	}
+
+	base, quote := Async_get_pool_reserves(*client, pool.PoolBaseTokenAccount, pool.PoolQuoteTokenAccount)
+	if base == nil || quote == nil {
+		return nil, errors.New("pumpamm: pool reserves not found")
+	}
+	pool.PoolBaseTokenReserves = *base
+	pool.PoolQuoteTokenReserves = *quote
+	return pool, nil
+}
+
+func decodePumpAmmPool(poolAddress, globalConfig solana.PublicKey, poolData, globalData []byte) (*solanaswapgo.PumpAmmPool, error) {
+	if len(poolData) < ammPoolSize {
+		return nil, errors.New("pumpamm: pool data too short")
+	}
+	if len(globalData) < ammGlobalFeeRecipientsOffset+32 {
+		return nil, errors.New("pumpamm: global config data too short")
+	}
+	key := func(data []byte, offset int) solana.PublicKey {
+		return solana.PublicKeyFromBytes(data[offset : offset+32])
+	}
+	quoteMint := key(poolData, ammPoolQuoteMintOffset)
+	feeRecipient := key(globalData, ammGlobalFeeRecipientsOffset)
+	feeRecipientATA, _, _ := solana.FindAssociatedTokenAddress(feeRecipient, quoteMint)
+	coinCreatorVaultAuthority, _, _ := solana.FindProgramAddress([][]byte{
+		[]byte("creator_vault"), key(poolData, ammPoolCoinCreatorOffset).Bytes(),
+	}, PUMPSWAP_PROGRAM_ID)
+	coinCreatorVaultAta, _, _ := solana.FindAssociatedTokenAddress(coinCreatorVaultAuthority, quoteMint)
+
+	return &solanaswapgo.PumpAmmPool{
+		Pool:                             poolAddress,
+		GlobalConfig:                     globalConfig,
+		BaseMint:                         key(poolData, ammPoolBaseMintOffset),
+		QuoteMint:                        quoteMint,
+		PoolBaseTokenAccount:             key(poolData, ammPoolBaseAccountOffset),
+		PoolQuoteTokenAccount:            key(poolData, ammPoolQuoteAccountOffset),
+		ProtocolFeeRecipient:             feeRecipient,
+		ProtocolFeeRecipientTokenAccount: feeRecipientATA,
+		CoinCreatorVaultAta:              coinCreatorVaultAta,
+		CoinCreatorVaultAuthority:        coinCreatorVaultAuthority,
+	}, nil
+}
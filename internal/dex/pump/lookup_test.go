@@ -0,0 +1,61 @@
+package pump
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestDecodePumpFunPool(t *testing.T) {
+	mint, creator, feeRecipient := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+
+	curve := make([]byte, 8+5*8+1+32)
+	for i, v := range []uint64{1_073_000_000_000_000, 30_000_000_000, 793_100_000_000_000, 0, 1_000_000_000_000_000} {
+		binary.LittleEndian.PutUint64(curve[8+i*8:], v)
+	}
+	curve[48] = 1
+	copy(curve[bondingCurveCreatorOffset:], creator.Bytes())
+
+	global := make([]byte, 8+1+32+32+5*8)
+	copy(global[8+1+32:], feeRecipient.Bytes())
+
+	pool, complete, err := decodePumpFunPool(mint, curve, global)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creatorVault, _, _ := solana.FindProgramAddress([][]byte{[]byte("creator-vault"), creator.Bytes()}, PUMPManager)
+	if !complete || pool.VirtualTokenReserves != 1_073_000_000_000_000 || pool.VirtualSolReserves != 30_000_000_000 ||
+		!pool.FeeRecipient.Equals(feeRecipient) || !pool.CreatorVault.Equals(creatorVault) || !pool.BondingCurve.Equals(BondingCurveAddress(mint)) {
+		t.Fatalf("pool = %+v, complete = %v", pool, complete)
+	}
+}
+
+func TestDecodePumpAmmPool(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	baseAccount, quoteAccount := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	coinCreator, feeRecipient := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+
+	data := make([]byte, ammPoolSize)
+	copy(data[ammPoolBaseMintOffset:], mint.Bytes())
+	copy(data[ammPoolQuoteMintOffset:], solana.WrappedSol.Bytes())
+	copy(data[ammPoolBaseAccountOffset:], baseAccount.Bytes())
+	copy(data[ammPoolQuoteAccountOffset:], quoteAccount.Bytes())
+	copy(data[ammPoolCoinCreatorOffset:], coinCreator.Bytes())
+	global := make([]byte, ammGlobalFeeRecipientsOffset+8*32)
+	copy(global[ammGlobalFeeRecipientsOffset:], feeRecipient.Bytes())
+
+	poolAddress := CanonicalAmmPool(mint)
+	pool, err := decodePumpAmmPool(poolAddress, solana.PublicKey{}, data, global)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feeATA, _, _ := solana.FindAssociatedTokenAddress(feeRecipient, solana.WrappedSol)
+	if !pool.BaseMint.Equals(mint) || !pool.QuoteMint.Equals(solana.WrappedSol) || !pool.PoolBaseTokenAccount.Equals(baseAccount) ||
+		!pool.PoolQuoteTokenAccount.Equals(quoteAccount) || !pool.ProtocolFeeRecipientTokenAccount.Equals(feeATA) || !pool.Pool.Equals(poolAddress) {
+		t.Fatalf("pool = %+v", pool)
+	}
+	if _, err := decodePumpAmmPool(poolAddress, solana.PublicKey{}, data[:100], global); err == nil {
+		t.Fatal("short pool decoded")
+	}
+}
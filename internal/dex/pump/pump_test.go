@@ -122,7 +122,7 @@ func TestLi(t *testing.T) {
 			VirtualSOLReserves:   30990099009,
 		},
 	})
-	amountOutWithSlippage := applySlippage(amountOut, slippage)
+	amountOutWithSlippage := ApplySlippage(amountOut, slippage)
 	t.Log(amountInAfterPumpFee)
 	t.Log(amountOutWithSlippage)
 }
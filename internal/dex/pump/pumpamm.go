@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"solana-bot/internal/global"
 	"solana-bot/internal/global/utils"
+	"solana-bot/internal/signer"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -172,7 +173,8 @@ func GetPrice(baseBalanceTokens *big.Float, quoteBalanceSol *big.Float) *big.Flo
 }
 
 func GetPumpAMMBuyTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
+	nonce *global.NonceLease, // 调用方租用的 nonce，发送结束后由调用方归还
 	pool solana.PublicKey,
 	globalConfig solana.PublicKey,
 	baseMint solana.PublicKey,
@@ -192,9 +194,8 @@ func GetPumpAMMBuyTx(
 	jitoTip uint64,
 ) (*solana.Transaction, error) {
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 
 	instrs = append(instrs, system.NewAdvanceNonceAccountInstruction(nonceAccount, solana.SysVarRecentBlockHashesPubkey, signerAndOwner.PublicKey()).Build())
 
@@ -227,7 +228,7 @@ func GetPumpAMMBuyTx(
 
 	addPumpAmmBuyIx(&instrs, signerAndOwner.PublicKey(), base_amount_out, max_quote_amount_in, base_balance_tokens, quote_balance_sol, pool, globalConfig, baseMint, quoteMint, poolBaseTokenAccount, poolQuoteTokenAccount, protocolFeeRecipient, protocolFeeRecipientATA, coinCreatorVaultAta, coinCreatorVaultAuthority)
 
-	tx, err := BuildTransaction(nonceHash, signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(nonceHash, signerAndOwner, instrs...)
 	return tx, err
 }
 
@@ -299,7 +300,7 @@ func addPumpAmmBuyIx(
 }
 
 func GetPumpAMMSellTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
 	pool solana.PublicKey,
 	globalConfig solana.PublicKey,
 	baseMint solana.PublicKey,
@@ -320,7 +321,6 @@ func GetPumpAMMSellTx(
 	shouldCloseTokenInAccount bool,
 ) (*solana.Transaction, error) {
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
 	// nonceAccount, nonceHash := global.GetNonceAccountAndHash()
 
@@ -350,7 +350,7 @@ func GetPumpAMMSellTx(
 	if shouldCloseTokenInAccount {
 		closeATA(&instrs, signerAndOwner.PublicKey(), baseMint)
 	}
-	tx, err := BuildTransaction(global.GetBlockHash(), signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(global.GetBlockHash(), signerAndOwner, instrs...)
 	return tx, err
 }
 
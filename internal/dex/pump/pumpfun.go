@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math/big"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 	"time"
 
 	bin "github.com/gagliardetto/binary"
@@ -302,7 +303,8 @@ func pumpGetFee(amount *big.Int, feeBP uint64) *big.Int {
 
 func GetPumpBuyTx(
 	// nonceAccount solana.PublicKey,
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
+	nonce *global.NonceLease, // 调用方租用的 nonce，发送结束后由调用方归还
 	mint *solana.PublicKey,
 	creatorVault solana.PublicKey,
 	maxAmountIn *big.Int,
@@ -314,9 +316,8 @@ func GetPumpBuyTx(
 ) (*solana.Transaction, error) {
 
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	// nonce advance的操作一定要在第一個instruction
 	instrs = append(instrs, system.NewAdvanceNonceAccountInstruction(nonceAccount, solana.SysVarRecentBlockHashesPubkey, signerAndOwner.PublicKey()).Build())
 
@@ -343,26 +344,21 @@ func GetPumpBuyTx(
 	addPumpBuyIx(&instrs, signerAndOwner.PublicKey(), mint, creatorVault, amountInAfterOurFee, bondingCurveData, slippage)
 
 	// spew.Dump(instrs)
-	tx, err := BuildTransaction(nonceHash, signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(nonceHash, signerAndOwner, instrs...)
 	return tx, err
 }
 
-func BuildTransaction(nonceHash solana.Hash, signers []solana.PrivateKey, signer solana.PrivateKey, instrs ...solana.Instruction) (*solana.Transaction, error) {
+func BuildTransaction(nonceHash solana.Hash, s signer.Signer, instrs ...solana.Instruction) (*solana.Transaction, error) {
 	tx, err := solana.NewTransaction(
 		instrs,
 		nonceHash,
-		solana.TransactionPayer(signers[0].PublicKey()),
+		solana.TransactionPayer(s.PublicKey()),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &signer
-		},
-	)
-	if err != nil {
+	if err := s.SignTransaction(tx); err != nil {
 		return nil, err
 	}
 	return tx, nil
@@ -384,7 +380,7 @@ func addPumpBuyIx(
 	// this is used for quoting
 	amountInAfterPumpFee := new(big.Int).Sub(amountInAfterOurFee, pumpFee)
 	amountOut := pumpQuoteBuy(amountInAfterPumpFee, bondingCurveData)
-	amountOutWithSlippage := applySlippage(amountOut, slippage)
+	amountOutWithSlippage := ApplySlippage(amountOut, slippage)
 
 	fmt.Printf("amountInAfterOurFee:%d \n", amountInAfterOurFee.Uint64())
 	fmt.Printf("amountOutWithSlippage:%d \n", amountOutWithSlippage.Uint64())
@@ -448,7 +444,7 @@ func createTokenAccountIfNotExists(instrs *[]solana.Instruction, owner solana.Pu
 }
 
 // slippage is a value between 0 - 100
-func applySlippage(amount *big.Int, slippage float32) *big.Int {
+func ApplySlippage(amount *big.Int, slippage float32) *big.Int {
 
 	slippageBP := (int64(100*slippage) + 25) * SlippageAdjustment
 	maxSlippage := new(big.Int).Mul(global.Big10000, big.NewInt(SlippageAdjustment))
@@ -499,7 +495,7 @@ func PumpGetValueAndPriceImpact(token string, amount *big.Int, decimals uint8) (
 }
 
 func GetPumpSellTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
 	mint *solana.PublicKey,
 	creatorVault solana.PublicKey,
 	// maxIn without taking any fees
@@ -513,7 +509,6 @@ func GetPumpSellTx(
 	shouldCloseTokenInAccount bool,
 ) (*solana.Transaction, error) {
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
 	// nonceAccount, nonceHash := global.GetNonceAccountAndHash()
 
@@ -540,7 +535,7 @@ func GetPumpSellTx(
 		closeATA(&instrs, signerAndOwner.PublicKey(), *mint)
 	}
 
-	tx, err := BuildTransaction(global.GetBlockHash(), signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(global.GetBlockHash(), signerAndOwner, instrs...)
 	return tx, err
 }
 
@@ -555,7 +550,7 @@ func addPumpSellIx(
 	slippage float32,
 ) {
 	amountOut := pumpQuoteSell(amountIn, bondingCurveData)
-	amountOutWithSlippage := applySlippage(amountOut, slippage)
+	amountOutWithSlippage := ApplySlippage(amountOut, slippage)
 	// we apply slippage on the amountOut
 	// quote buy here, then apply slippage
 	// if slippage is 100%, we reduce it
@@ -601,3 +596,15 @@ func closeATA(instrs *[]solana.Instruction, owner solana.PublicKey, mint solana.
 	).Build()
 	*instrs = append(*instrs, closeInst)
 }
+
+// DecodeBondingCurve 解析 bonding curve 账户数据
+func DecodeBondingCurve(data []byte) (*BondingCurveLayout, error) {
+	if len(data) < 49 {
+		return nil, errors.New("pumpfun: bonding curve data too short")
+	}
+	var layout BondingCurveLayout
+	if err := decode(data, &layout); err != nil {
+		return nil, err
+	}
+	return &layout, nil
+}
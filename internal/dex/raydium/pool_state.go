@@ -0,0 +1,75 @@
+package raydium
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// LaunchLab PoolState 账户：discriminator(8) + epoch(8) + auth_bump/status/base_decimals/quote_decimals/migrate_type(5)
+// 之后依次为 supply、total_base_sell、virtual_base、virtual_quote、real_base、real_quote
+const (
+	launchpadStatusOffset = 8 + 8 + 1
+	launchpadSupplyOffset = 8 + 8 + 5
+	launchpadMinSize      = launchpadSupplyOffset + 8*6
+)
+
+var launchpadPoolDiscriminator = func() [8]byte {
+	var d [8]byte
+	sum := sha256.Sum256([]byte("account:PoolState"))
+	copy(d[:], sum[:8])
+	return d
+}()
+
+// LaunchpadPoolState LaunchLab 池子的链上状态
+type LaunchpadPoolState struct {
+	Status        uint8 // 0 交易中，1 等待迁移，2 已迁移
+	BaseDecimals  uint8
+	QuoteDecimals uint8
+	Supply        uint64
+	TotalBaseSell uint64
+	VirtualBase   uint64
+	VirtualQuote  uint64
+	RealBase      uint64
+	RealQuote     uint64
+}
+
+// DecodeLaunchpadPool 解析 LaunchLab PoolState 账户数据
+func DecodeLaunchpadPool(data []byte) (*LaunchpadPoolState, error) {
+	if len(data) < launchpadMinSize {
+		return nil, errors.New("raydium: pool state data too short")
+	}
+	if string(data[:8]) != string(launchpadPoolDiscriminator[:]) {
+		return nil, errors.New("raydium: not a launchpad pool state account")
+	}
+	u64 := func(i int) uint64 {
+		return binary.LittleEndian.Uint64(data[launchpadSupplyOffset+8*i:])
+	}
+	return &LaunchpadPoolState{
+		Status:        data[launchpadStatusOffset],
+		BaseDecimals:  data[launchpadStatusOffset+1],
+		QuoteDecimals: data[launchpadStatusOffset+2],
+		Supply:        u64(0),
+		TotalBaseSell: u64(1),
+		VirtualBase:   u64(2),
+		VirtualQuote:  u64(3),
+		RealBase:      u64(4),
+		RealQuote:     u64(5),
+	}, nil
+}
+
+// Reserves 曲线上的有效储备：base = virtual_base - real_base，quote = virtual_quote + real_quote
+func (s *LaunchpadPoolState) Reserves() (base, quote uint64) {
+	base = s.VirtualBase
+	if s.RealBase < base {
+		base -= s.RealBase
+	} else {
+		base = 0
+	}
+	return base, s.VirtualQuote + s.RealQuote
+}
+
+// Migrated 池子是否已经毕业
+func (s *LaunchpadPoolState) Migrated() bool {
+	return s.Status != 0
+}
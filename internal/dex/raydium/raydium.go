@@ -3,6 +3,7 @@ package raydium
 import (
 	"math/big"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 
 	"github.com/gagliardetto/solana-go"
 	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
@@ -12,7 +13,8 @@ import (
 )
 
 func GetBuyTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
+	nonce *global.NonceLease, // 调用方租用的 nonce，发送结束后由调用方归还
 	globalConfig, platformConfig, poolState, baseVault, quoteVault, baseMint, quoteMint solana.PublicKey,
 	base_balance_tokens *big.Float,
 	quote_balance_sol *big.Float,
@@ -24,9 +26,8 @@ func GetBuyTx(
 ) (*solana.Transaction, error) {
 
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 
 	instrs = append(instrs, system.NewAdvanceNonceAccountInstruction(nonceAccount, solana.SysVarRecentBlockHashesPubkey, signerAndOwner.PublicKey()).Build())
 
@@ -80,12 +81,12 @@ func GetBuyTx(
 		base_amount_out, // minOut
 	))
 	// spew.Dump(instrs)
-	tx, err := BuildTransaction(nonceHash, signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(nonceHash, signerAndOwner, instrs...)
 	return tx, err
 }
 
 func GetSellTx(
-	signerAndOwner *solana.PrivateKey,
+	signerAndOwner signer.Signer,
 	globalConfig, platformConfig, poolState, baseVault, quoteVault, baseMint, quoteMint solana.PublicKey,
 	base_balance_tokens *big.Float,
 	quote_balance_sol *big.Float,
@@ -98,7 +99,6 @@ func GetSellTx(
 ) (*solana.Transaction, error) {
 
 	instrs := []solana.Instruction{}
-	signers := []solana.PrivateKey{*signerAndOwner}
 
 	// nonceAccount, nonceHash := global.GetNonceAccountAndHash()
 	// instrs = append(instrs, system.NewAdvanceNonceAccountInstruction(nonceAccount, solana.SysVarRecentBlockHashesPubkey, signerAndOwner.PublicKey()).Build())
@@ -156,7 +156,7 @@ func GetSellTx(
 	}
 
 	// spew.Dump(instrs)
-	tx, err := BuildTransaction(global.GetBlockHash(), signers, *signerAndOwner, instrs...)
+	tx, err := BuildTransaction(global.GetBlockHash(), signerAndOwner, instrs...)
 	return tx, err
 }
 
@@ -198,22 +198,17 @@ func EstimateBackrunBuyOutAmount(
 	return out
 }
 
-func BuildTransaction(nonceHash solana.Hash, signers []solana.PrivateKey, signer solana.PrivateKey, instrs ...solana.Instruction) (*solana.Transaction, error) {
+func BuildTransaction(nonceHash solana.Hash, s signer.Signer, instrs ...solana.Instruction) (*solana.Transaction, error) {
 	tx, err := solana.NewTransaction(
 		instrs,
 		nonceHash,
-		solana.TransactionPayer(signers[0].PublicKey()),
+		solana.TransactionPayer(s.PublicKey()),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &signer
-		},
-	)
-	if err != nil {
+	if err := s.SignTransaction(tx); err != nil {
 		return nil, err
 	}
 	return tx, nil
@@ -0,0 +1,280 @@
+package exitplan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 触发卖出的规则名，记录在每笔卖出上
+const (
+	RuleTakeProfit = "take_profit"
+	RuleStopLoss   = "stop_loss"
+	RuleTrailing   = "trailing"
+	RuleMaxHold    = "max_hold"
+	RuleLeaderExit = "leader_exit"
+)
+
+// 跟随的钱包卖出时的处理
+const (
+	LeaderExitAll          = "all"          // 清仓
+	LeaderExitProportional = "proportional" // 按它卖出的比例卖出
+)
+
+// Plan 一个仓位的退出规则，按策略配置在 config/strategy.yaml 的 exits 下；价格都是买入价的倍数
+type Plan struct {
+	TakeProfit []TakeProfit  `yaml:"take_profit"` // 止盈阶梯
+	StopLoss   float64       `yaml:"stop_loss"`   // 跌到买入价的这个倍数以下清仓，0 不启用
+	Trailing   Trailing      `yaml:"trailing"`    // 移动止损
+	MaxHold    time.Duration `yaml:"max_hold"`    // 持有超过这个时间清仓，0 不启用
+	HoldSteps  int           `yaml:"hold_steps"`  // 策略给出的持有时间分几批卖出，0 为 3 批
+	LeaderExit string        `yaml:"leader_exit"` // all、proportional，空为不跟随卖出
+}
+
+// TakeProfit 价格到达 Multiple 倍时卖出买入数量的 Fraction
+type TakeProfit struct {
+	Multiple float64 `yaml:"multiple"`
+	Fraction float64 `yaml:"fraction"`
+}
+
+// Trailing 价格到达 Activation 倍后启用，从最高价回撤 Drawdown 比例时清仓
+type Trailing struct {
+	Activation float64 `yaml:"activation"`
+	Drawdown   float64 `yaml:"drawdown"`
+}
+
+// Validate 检查规则是否自洽
+func (p Plan) Validate() error {
+	for _, tp := range p.TakeProfit {
+		if tp.Multiple <= 1 {
+			return fmt.Errorf("take_profit multiple %v <= 1", tp.Multiple)
+		}
+		if tp.Fraction <= 0 || tp.Fraction > 1 {
+			return fmt.Errorf("take_profit fraction %v not in (0, 1]", tp.Fraction)
+		}
+	}
+	if p.StopLoss < 0 || p.StopLoss >= 1 {
+		return fmt.Errorf("stop_loss %v not in [0, 1)", p.StopLoss)
+	}
+	if p.Trailing.Activation > 0 && (p.Trailing.Drawdown <= 0 || p.Trailing.Drawdown >= 1) {
+		return fmt.Errorf("trailing drawdown %v not in (0, 1)", p.Trailing.Drawdown)
+	}
+	if p.MaxHold < 0 || p.HoldSteps < 0 {
+		return errors.New("max_hold and hold_steps must not be negative")
+	}
+	switch p.LeaderExit {
+	case "", LeaderExitAll, LeaderExitProportional:
+	default:
+		return fmt.Errorf("unknown leader_exit %q", p.LeaderExit)
+	}
+	return nil
+}
+
+// Signal 规则触发后要卖出的数量
+type Signal struct {
+	Rule   string
+	Amount uint64
+}
+
+// State 一个仓位按 Plan 执行到哪一步；不并发安全，由调用方加锁。可以序列化后随仓位保存
+type State struct {
+	Plan        Plan
+	EntryPrice  float64
+	EntryAmount uint64
+	OpenedAt    time.Time
+	Peak        float64 // 买入后的最高价
+	Fired       []bool  // 已触发或撤销的止盈档，和 Plan.TakeProfit 一一对应
+	Done        bool    // 已经触发清仓
+	StopLossOff bool    // 止损已撤销
+	// Orders 止盈、止损挂成了条件单，OnPrice 不再计算它们，由条件单触发时调用 Fire
+	Orders bool
+}
+
+// Trigger 触发价固定的规则（止盈各档、止损），可以挂成条件单由价格撮合
+type Trigger struct {
+	Rule   string
+	Price  float64 // 触发价，SOL / token
+	Above  bool    // true 为涨到 Price 及以上触发，false 为跌到及以下
+	Amount uint64  // 触发时卖出的数量
+}
+
+// NewState 按买入价、买入数量开始执行 plan，止盈档按倍数从低到高排列
+func NewState(plan Plan, entryPrice float64, entryAmount uint64, openedAt time.Time) *State {
+	plan.TakeProfit = append([]TakeProfit(nil), plan.TakeProfit...)
+	sort.Slice(plan.TakeProfit, func(i, j int) bool {
+		return plan.TakeProfit[i].Multiple < plan.TakeProfit[j].Multiple
+	})
+	return &State{
+		Plan:        plan,
+		EntryPrice:  entryPrice,
+		EntryAmount: entryAmount,
+		OpenedAt:    openedAt,
+		Peak:        entryPrice,
+		Fired:       make([]bool, len(plan.TakeProfit)),
+	}
+}
+
+// HoldSteps 持有时间到期分几批卖出
+func (s *State) HoldSteps() int {
+	if s.Plan.HoldSteps > 0 {
+		return s.Plan.HoldSteps
+	}
+	return 3
+}
+
+// Deadline 最长持有到什么时候，没有配置时为零值
+func (s *State) Deadline() time.Time {
+	if s.Plan.MaxHold <= 0 {
+		return time.Time{}
+	}
+	return s.OpenedAt.Add(s.Plan.MaxHold)
+}
+
+// OnPrice 价格更新时按规则计算需要卖出的数量；price <= 0 时只检查持有时间。
+// 清仓规则优先，同一次更新越过多档止盈时合并为多个信号，总数不超过 remaining
+func (s *State) OnPrice(price float64, remaining uint64, now time.Time) []Signal {
+	if s.Done || remaining == 0 {
+		return nil
+	}
+	if deadline := s.Deadline(); !deadline.IsZero() && !now.Before(deadline) {
+		return s.exit(RuleMaxHold, remaining)
+	}
+	if price <= 0 || s.EntryPrice <= 0 {
+		return nil
+	}
+	s.Peak = max(s.Peak, price)
+	ratio := price / s.EntryPrice
+
+	if !s.Orders && s.Plan.StopLoss > 0 && !s.StopLossOff && ratio <= s.Plan.StopLoss {
+		return s.exit(RuleStopLoss, remaining)
+	}
+	if t := s.Plan.Trailing; t.Activation > 0 && s.Peak >= s.EntryPrice*t.Activation && price <= s.Peak*(1-t.Drawdown) {
+		return s.exit(RuleTrailing, remaining)
+	}
+	if s.Orders {
+		return nil
+	}
+
+	var signals []Signal
+	for i, tp := range s.Plan.TakeProfit {
+		if s.Fired[i] || ratio < tp.Multiple {
+			continue
+		}
+		signal, ok := s.fireTakeProfit(i, remaining)
+		if !ok {
+			continue
+		}
+		remaining -= signal.Amount
+		signals = append(signals, signal)
+		if s.Done {
+			break
+		}
+	}
+	return signals
+}
+
+// Triggers 还没触发、也没撤销的止盈档和止损，remaining 为当前持仓
+func (s *State) Triggers(remaining uint64) []Trigger {
+	if s.Done || remaining == 0 || s.EntryPrice <= 0 {
+		return nil
+	}
+	var out []Trigger
+	if s.Plan.StopLoss > 0 && !s.StopLossOff {
+		out = append(out, Trigger{Rule: RuleStopLoss, Price: s.EntryPrice * s.Plan.StopLoss, Amount: remaining})
+	}
+	for i, tp := range s.Plan.TakeProfit {
+		if !s.Fired[i] {
+			amount := min(uint64(float64(s.EntryAmount)*tp.Fraction), remaining)
+			out = append(out, Trigger{Rule: takeProfitRule(tp), Price: s.EntryPrice * tp.Multiple, Above: true, Amount: amount})
+		}
+	}
+	return out
+}
+
+// Fire 条件单触发了 rule，和 OnPrice 一样记下触发并算出卖出数量；规则已触发、已撤销或仓位已清仓时返回 nil
+func (s *State) Fire(rule string, remaining uint64) []Signal {
+	if s.Done || remaining == 0 {
+		return nil
+	}
+	if rule == RuleStopLoss {
+		if s.Plan.StopLoss <= 0 || s.StopLossOff {
+			return nil
+		}
+		return s.exit(RuleStopLoss, remaining)
+	}
+	for i, tp := range s.Plan.TakeProfit {
+		if s.Fired[i] || takeProfitRule(tp) != rule {
+			continue
+		}
+		if signal, ok := s.fireTakeProfit(i, remaining); ok {
+			return []Signal{signal}
+		}
+		return nil
+	}
+	return nil
+}
+
+// Disarm 撤销 rule，之后不会再触发
+func (s *State) Disarm(rule string) {
+	if rule == RuleStopLoss {
+		s.StopLossOff = true
+	}
+	for i, tp := range s.Plan.TakeProfit {
+		if takeProfitRule(tp) == rule {
+			s.Fired[i] = true
+		}
+	}
+}
+
+// fireTakeProfit 记下第 i 档止盈触发，卖完 remaining 时清仓
+func (s *State) fireTakeProfit(i int, remaining uint64) (Signal, bool) {
+	s.Fired[i] = true
+	amount := min(uint64(float64(s.EntryAmount)*s.Plan.TakeProfit[i].Fraction), remaining)
+	if amount == 0 {
+		return Signal{}, false
+	}
+	if amount == remaining {
+		s.Done = true
+	}
+	return Signal{Rule: takeProfitRule(s.Plan.TakeProfit[i]), Amount: amount}, true
+}
+
+// OnLeaderExit 跟随的钱包卖出了持仓的 ratio（0~1），按 leader_exit 决定卖出多少；未配置时返回 nil
+func (s *State) OnLeaderExit(ratio float64, remaining uint64) []Signal {
+	if s.Done || remaining == 0 {
+		return nil
+	}
+	switch s.Plan.LeaderExit {
+	case LeaderExitAll:
+		return s.exit(RuleLeaderExit, remaining)
+	case LeaderExitProportional:
+		// 对方基本卖完时跟着清仓
+		if ratio >= 0.9 {
+			return s.exit(RuleLeaderExit, remaining)
+		}
+		if amount := uint64(float64(remaining) * ratio); amount > 0 {
+			return []Signal{{Rule: RuleLeaderExit, Amount: amount}}
+		}
+	}
+	return nil
+}
+
+// Rearm 规则触发后卖出失败，撤销触发，之后的价格更新会再次触发
+func (s *State) Rearm(rule string) {
+	s.Done = false
+	for i, tp := range s.Plan.TakeProfit {
+		if takeProfitRule(tp) == rule {
+			s.Fired[i] = false
+		}
+	}
+}
+
+func takeProfitRule(tp TakeProfit) string {
+	return fmt.Sprintf("%s:%gx", RuleTakeProfit, tp.Multiple)
+}
+
+func (s *State) exit(rule string, remaining uint64) []Signal {
+	s.Done = true
+	return []Signal{{Rule: rule, Amount: remaining}}
+}
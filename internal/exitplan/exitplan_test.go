@@ -0,0 +1,156 @@
+package exitplan
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestParsePlan(t *testing.T) {
+	var plans map[string]Plan
+	err := yaml.Unmarshal([]byte(`
+mint:
+  take_profit:
+    - {multiple: 4, fraction: 1}
+    - {multiple: 1.5, fraction: 0.5}
+  stop_loss: 0.7
+  trailing: {activation: 2, drawdown: 0.3}
+  max_hold: 90s
+  leader_exit: all
+`), &plans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := plans["mint"]
+	if err := plan.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if plan.MaxHold != 90*time.Second || len(plan.TakeProfit) != 2 || plan.Trailing.Drawdown != 0.3 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	for _, bad := range []Plan{
+		{TakeProfit: []TakeProfit{{Multiple: 0.5, Fraction: 1}}},
+		{TakeProfit: []TakeProfit{{Multiple: 2, Fraction: 1.5}}},
+		{StopLoss: 1.2},
+		{Trailing: Trailing{Activation: 2}},
+		{LeaderExit: "half"},
+	} {
+		if bad.Validate() == nil {
+			t.Fatalf("%+v validated", bad)
+		}
+	}
+}
+
+func TestTakeProfitLadder(t *testing.T) {
+	now := time.Now()
+	s := NewState(Plan{TakeProfit: []TakeProfit{{Multiple: 4, Fraction: 1}, {Multiple: 1.5, Fraction: 0.5}}}, 1, 1000, now)
+
+	if got := s.OnPrice(1.2, 1000, now); got != nil {
+		t.Fatalf("below ladder = %v", got)
+	}
+	got := s.OnPrice(1.6, 1000, now)
+	if len(got) != 1 || got[0].Amount != 500 || got[0].Rule != "take_profit:1.5x" {
+		t.Fatalf("first step = %v", got)
+	}
+	// 同一档只触发一次
+	if got := s.OnPrice(1.7, 500, now); got != nil {
+		t.Fatalf("refired = %v", got)
+	}
+	// 卖出失败后重新触发
+	s.Rearm("take_profit:1.5x")
+	if got := s.OnPrice(1.7, 1000, now); len(got) != 1 || got[0].Rule != "take_profit:1.5x" {
+		t.Fatalf("rearmed = %v", got)
+	}
+	// 卖出数量不超过剩余
+	got = s.OnPrice(5, 500, now)
+	if len(got) != 1 || got[0].Amount != 500 || !s.Done {
+		t.Fatalf("last step = %v, done %v", got, s.Done)
+	}
+}
+
+func TestExitRules(t *testing.T) {
+	now := time.Now()
+	plan := Plan{StopLoss: 0.7, Trailing: Trailing{Activation: 2, Drawdown: 0.25}, MaxHold: time.Minute}
+
+	s := NewState(plan, 1, 1000, now)
+	if got := s.OnPrice(0.69, 800, now); len(got) != 1 || got[0].Rule != RuleStopLoss || got[0].Amount != 800 {
+		t.Fatalf("stop loss = %v", got)
+	}
+	if got := s.OnPrice(0.1, 800, now); got != nil {
+		t.Fatalf("fired after done = %v", got)
+	}
+
+	// 未到启用价时回撤不触发
+	s = NewState(plan, 1, 1000, now)
+	s.OnPrice(1.8, 1000, now)
+	if got := s.OnPrice(1.3, 1000, now); got != nil {
+		t.Fatalf("trailing before activation = %v", got)
+	}
+	s.OnPrice(3, 1000, now)
+	if got := s.OnPrice(2.3, 1000, now); got != nil {
+		t.Fatalf("trailing within drawdown = %v", got)
+	}
+	if got := s.OnPrice(2.2, 1000, now); len(got) != 1 || got[0].Rule != RuleTrailing {
+		t.Fatalf("trailing = %v", got)
+	}
+
+	// 没有价格也按时间退出
+	s = NewState(plan, 1, 1000, now)
+	if got := s.OnPrice(0, 1000, now.Add(time.Minute)); len(got) != 1 || got[0].Rule != RuleMaxHold {
+		t.Fatalf("max hold = %v", got)
+	}
+}
+
+func TestLeaderExit(t *testing.T) {
+	now := time.Now()
+	if got := NewState(Plan{}, 1, 1000, now).OnLeaderExit(1, 1000); got != nil {
+		t.Fatalf("disabled = %v", got)
+	}
+	if got := NewState(Plan{LeaderExit: LeaderExitAll}, 1, 1000, now).OnLeaderExit(0.1, 1000); len(got) != 1 || got[0].Amount != 1000 {
+		t.Fatalf("all = %v", got)
+	}
+	s := NewState(Plan{LeaderExit: LeaderExitProportional}, 1, 1000, now)
+	if got := s.OnLeaderExit(0.25, 1000); len(got) != 1 || got[0].Amount != 250 || s.Done {
+		t.Fatalf("proportional = %v", got)
+	}
+	if got := s.OnLeaderExit(0.95, 750); len(got) != 1 || got[0].Amount != 750 || !s.Done {
+		t.Fatalf("proportional all = %v", got)
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	now := time.Now()
+	plan := Plan{TakeProfit: []TakeProfit{{Multiple: 2, Fraction: 0.5}, {Multiple: 3, Fraction: 1}}, StopLoss: 0.5}
+	s := NewState(plan, 0.1, 1000, now)
+	s.Orders = true
+
+	triggers := s.Triggers(1000)
+	if len(triggers) != 3 || triggers[0].Rule != RuleStopLoss || triggers[0].Above || triggers[0].Price != 0.05 ||
+		triggers[1].Rule != "take_profit:2x" || !triggers[1].Above || triggers[1].Price != 0.2 || triggers[1].Amount != 500 {
+		t.Fatalf("triggers = %+v", triggers)
+	}
+	// 挂成条件单后价格不再触发止盈止损
+	if got := s.OnPrice(0.25, 1000, now); got != nil {
+		t.Fatalf("price fired = %v", got)
+	}
+	if got := s.Fire("take_profit:2x", 1000); len(got) != 1 || got[0].Amount != 500 {
+		t.Fatalf("fire = %v", got)
+	}
+	if got := s.Fire("take_profit:2x", 500); got != nil {
+		t.Fatalf("refired = %v", got)
+	}
+
+	// 撤销的规则不再挂出，也不会触发
+	s.Disarm(RuleStopLoss)
+	if triggers := s.Triggers(500); len(triggers) != 1 || triggers[0].Rule != "take_profit:3x" || triggers[0].Amount != 500 {
+		t.Fatalf("after disarm = %+v", triggers)
+	}
+	if got := s.Fire(RuleStopLoss, 500); got != nil {
+		t.Fatalf("disarmed stop loss = %v", got)
+	}
+	if got := s.Fire("take_profit:3x", 500); len(got) != 1 || got[0].Amount != 500 || !s.Done {
+		t.Fatalf("last step = %v, done %v", got, s.Done)
+	}
+}
@@ -0,0 +1,230 @@
+package fee
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 常用的分位数档位
+const (
+	Low      = 25
+	Medium   = 50
+	High     = 75
+	VeryHigh = 90
+)
+
+const (
+	// 自己流里观察到的样本保留时长
+	observeWindow = 60 * time.Second
+	// 每个账户最多保留的观察样本
+	maxObserved = 256
+	// RPC / relay 数据超过这个时间视为过期
+	staleAfter = 30 * time.Second
+	// 账户这么久没有被估算过就停止跟踪
+	trackIdle = 5 * time.Minute
+	// 本地样本少于这个数时，用 relay 的全网费用兜底
+	minSamples = 8
+)
+
+type sample struct {
+	at    time.Time
+	price uint64
+}
+
+// accountFees 单个可写账户上的费用样本，价格单位为 micro-lamports / CU
+type accountFees struct {
+	rpc      []uint64 // 最近一次 getRecentPrioritizationFees 的结果
+	rpcAt    time.Time
+	observed []sample // 自己的流里看到的写这个账户的交易
+	lastUsed time.Time
+}
+
+// Oracle 合并 relay 费用流、RPC 按账户的历史费用和自己流里观察到的 CU 价格，
+// 按交易要写入的账户集合给出分位数估算
+type Oracle struct {
+	mu       sync.Mutex
+	accounts map[string]*accountFees
+	relayFee uint64 // micro-lamports / CU
+	relayTip uint64 // lamports
+	relayAt  time.Time
+	min, max uint64
+	changed  chan struct{}
+	now      func() time.Time
+}
+
+func NewOracle() *Oracle {
+	return &Oracle{
+		accounts: make(map[string]*accountFees),
+		max:      5e6,
+		changed:  make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// SetLimit 设置优先费（micro-lamports / CU）的上下限，max 为 0 表示不限
+func (o *Oracle) SetLimit(min, max uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.min, o.max = min, max
+}
+
+// Track 开始跟踪这些账户的费用，之后订阅和 RPC 轮询会覆盖它们
+func (o *Oracle) Track(accounts ...string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.track(accounts, o.now())
+}
+
+func (o *Oracle) track(accounts []string, now time.Time) {
+	added := false
+	for _, acc := range accounts {
+		a, ok := o.accounts[acc]
+		if !ok {
+			a = &accountFees{}
+			o.accounts[acc] = a
+			added = true
+		}
+		a.lastUsed = now
+	}
+	if added {
+		o.notify()
+	}
+}
+
+func (o *Oracle) notify() {
+	select {
+	case o.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Tracked 返回仍在跟踪的账户，顺带清理长时间未使用的账户
+func (o *Oracle) Tracked() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	out := make([]string, 0, len(o.accounts))
+	for acc, a := range o.accounts {
+		if now.Sub(a.lastUsed) > trackIdle {
+			delete(o.accounts, acc)
+			continue
+		}
+		out = append(out, acc)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Observe 记录一笔写入 accounts 的交易设置的 CU 价格，只保留正在跟踪的账户
+func (o *Oracle) Observe(accounts []string, price uint64, at time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, acc := range accounts {
+		a, ok := o.accounts[acc]
+		if !ok {
+			continue
+		}
+		a.observed = append(a.observed, sample{at: at, price: price})
+		if len(a.observed) > maxObserved {
+			a.observed = a.observed[len(a.observed)-maxObserved:]
+		}
+	}
+}
+
+// SetRPC 用 getRecentPrioritizationFees 的结果替换账户的 RPC 样本
+func (o *Oracle) SetRPC(account string, fees []uint64, at time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	a, ok := o.accounts[account]
+	if !ok {
+		return
+	}
+	a.rpc = fees
+	a.rpcAt = at
+}
+
+// SetRelay 更新 relay 给出的全网优先费（micro-lamports / CU）和小费（lamports）
+func (o *Oracle) SetRelay(fee, tip uint64, at time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.relayFee, o.relayTip, o.relayAt = fee, tip, at
+}
+
+// Estimate 返回写入 accounts 的交易在 percentile 分位上的优先费，单位 micro-lamports / CU；
+// 本地样本不足时不低于 relay 的全网费用
+func (o *Oracle) Estimate(accounts []string, percentile int) uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	o.track(accounts, now)
+
+	var values []uint64
+	for _, acc := range accounts {
+		a := o.accounts[acc]
+		if now.Sub(a.rpcAt) <= staleAfter {
+			values = append(values, a.rpc...)
+		}
+		for _, s := range a.observed {
+			if now.Sub(s.at) <= observeWindow {
+				values = append(values, s.price)
+			}
+		}
+	}
+
+	est := percentileOf(values, percentile)
+	if len(values) < minSamples && now.Sub(o.relayAt) <= staleAfter && o.relayFee > est {
+		est = o.relayFee
+	}
+	if est < o.min {
+		est = o.min
+	}
+	if o.max > 0 && est > o.max {
+		est = o.max
+	}
+	return est
+}
+
+// Tip 返回 relay 建议的小费，单位 lamports，没有新数据时为 0
+func (o *Oracle) Tip() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.now().Sub(o.relayAt) > staleAfter {
+		return 0
+	}
+	return o.relayTip
+}
+
+func percentileOf(values []uint64, percentile int) uint64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]uint64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if percentile <= 0 {
+		return sorted[0]
+	}
+	if percentile >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[(len(sorted)-1)*percentile/100]
+}
+
+// Default 进程内共享的费用预言机
+var Default = NewOracle()
+
+func Track(accounts ...string) {
+	Default.Track(accounts...)
+}
+
+func Estimate(accounts []string, percentile int) uint64 {
+	return Default.Estimate(accounts, percentile)
+}
+
+func Tip() uint64 {
+	return Default.Tip()
+}
+
+func SetLimit(min, max uint64) {
+	Default.SetLimit(min, max)
+}
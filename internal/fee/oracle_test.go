@@ -0,0 +1,100 @@
+package fee
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func TestEstimateMergesSources(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	o := NewOracle()
+	o.now = func() time.Time { return now }
+
+	pool := solana.NewWallet().PublicKey().String()
+	other := solana.NewWallet().PublicKey().String()
+
+	// 没有本地样本时用 relay 兜底
+	o.SetRelay(20_000, 1_000_000, now)
+	if got := o.Estimate([]string{pool}, Medium); got != 20_000 {
+		t.Fatalf("relay fallback = %d", got)
+	}
+
+	// 没被跟踪的账户不记录样本
+	o.Observe([]string{other}, 999_999, now)
+	if _, ok := o.accounts[other]; ok {
+		t.Fatal("untracked account recorded")
+	}
+
+	o.SetRPC(pool, []uint64{0, 0, 100, 200, 300, 400}, now)
+	for _, price := range []uint64{1_000, 2_000, 3_000, 4_000} {
+		o.Observe([]string{pool, other}, price, now)
+	}
+	// 10 个样本：0 0 100 200 300 400 1000 2000 3000 4000
+	if got := o.Estimate([]string{pool}, Medium); got != 300 {
+		t.Fatalf("p50 = %d", got)
+	}
+	if got := o.Estimate([]string{pool}, VeryHigh); got != 3_000 {
+		t.Fatalf("p90 = %d", got)
+	}
+
+	// 样本过期后回到 relay
+	now = now.Add(observeWindow + time.Second)
+	o.SetRelay(20_000, 1_000_000, now)
+	if got := o.Estimate([]string{pool}, Medium); got != 20_000 {
+		t.Fatalf("after expiry = %d", got)
+	}
+
+	o.SetLimit(0, 10_000)
+	if got := o.Estimate([]string{pool}, Medium); got != 10_000 {
+		t.Fatalf("clamped = %d", got)
+	}
+	if got := o.Tip(); got != 1_000_000 {
+		t.Fatalf("tip = %d", got)
+	}
+}
+
+func TestParseTransaction(t *testing.T) {
+	payer := solana.NewWallet().PublicKey()
+	pool := solana.NewWallet().PublicKey()
+	program := solana.NewWallet().PublicKey()
+	loaded := solana.NewWallet().PublicKey()
+
+	data := make([]byte, 9)
+	data[0] = 3
+	binary.LittleEndian.PutUint64(data[1:], 123_456)
+
+	tx := &pb.SubscribeUpdateTransactionInfo{
+		Transaction: &pb.Transaction{
+			Message: &pb.Message{
+				Header: &pb.MessageHeader{
+					NumRequiredSignatures:       1,
+					NumReadonlyUnsignedAccounts: 2,
+				},
+				AccountKeys: [][]byte{payer.Bytes(), pool.Bytes(), program.Bytes(), solana.ComputeBudget.Bytes()},
+				Instructions: []*pb.CompiledInstruction{
+					{ProgramIdIndex: 3, Data: data},
+					{ProgramIdIndex: 2, Accounts: []byte{0, 1}},
+				},
+			},
+		},
+		Meta: &pb.TransactionStatusMeta{LoadedWritableAddresses: [][]byte{loaded.Bytes()}},
+	}
+
+	if got := ComputeUnitPrice(tx.Transaction.Message); got != 123_456 {
+		t.Fatalf("cu price = %d", got)
+	}
+	got := WritableAccounts(tx)
+	want := []string{payer.String(), pool.String(), loaded.String()}
+	if len(got) != len(want) {
+		t.Fatalf("writable = %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("writable = %v", got)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package fee
+
+import (
+	"context"
+	"encoding/binary"
+	"slices"
+	"time"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/pb/feepb"
+	"solana-bot/internal/stream"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	rpcInterval   = 5 * time.Second
+	rpcTimeout    = 2 * time.Second
+	pruneInterval = time.Minute
+)
+
+// Run 启动三路输入：relay 费用流、按账户轮询 RPC、订阅跟踪账户上的交易，直到 ctx 结束
+func (o *Oracle) Run(ctx context.Context, hub *stream.Hub) {
+	go o.runRelay(ctx)
+	go o.runRPC(ctx)
+	o.runStream(ctx, hub)
+}
+
+func Run(ctx context.Context, hub *stream.Hub) {
+	Default.Run(ctx, hub)
+}
+
+func (o *Oracle) runRelay(ctx context.Context) {
+	subscribe := make(chan interface{})
+	go stream.Fee_subscribe(ctx, subscribe)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-subscribe:
+			got := msg.(*feepb.TransactionFeeStreamResponse)
+			if got.GetPriorityFee() == nil {
+				continue
+			}
+			// 优先费为 micro-lamports / CU，小费以 SOL 计
+			fee := uint64(got.GetPriorityFee().GetValue())
+			tip := uint64(got.GetTip().GetValue() * float64(solana.LAMPORTS_PER_SOL))
+			o.SetRelay(fee, tip, time.Now())
+		}
+	}
+}
+
+func (o *Oracle) runRPC(ctx context.Context) {
+	ticker := time.NewTicker(rpcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// 不带账户时返回的是全网数据，所以每个账户单独查询
+		for _, acc := range o.Tracked() {
+			pk, err := solana.PublicKeyFromBase58(acc)
+			if err != nil {
+				continue
+			}
+			reqCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+			out, err := global.GetRPCForRequest().GetRecentPrioritizationFees(reqCtx, solana.PublicKeySlice{pk})
+			cancel()
+			if err != nil {
+				logx.Errorf("[fee]: getRecentPrioritizationFees %s: %v", acc, err)
+				continue
+			}
+			fees := make([]uint64, 0, len(out))
+			for _, f := range out {
+				fees = append(fees, f.PrioritizationFee)
+			}
+			o.SetRPC(acc, fees, time.Now())
+		}
+	}
+}
+
+// runStream 跟踪账户变化时更新订阅，记录写入这些账户的交易设置的 CU 价格
+func (o *Oracle) runStream(ctx context.Context, hub *stream.Hub) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	var (
+		consumer *stream.HubConsumer
+		current  []string
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.changed:
+		case <-ticker.C:
+		}
+
+		accounts := o.Tracked()
+		if slices.Equal(accounts, current) {
+			continue
+		}
+		current = accounts
+
+		switch {
+		case len(accounts) == 0:
+			// 空的 AccountInclude 会匹配所有交易
+			if consumer != nil {
+				consumer.Close()
+				consumer = nil
+			}
+		case consumer == nil:
+			subscribe := make(chan interface{})
+			consumer = hub.Subscribe(ctx, feeSubscription(accounts), subscribe)
+			go func() {
+				for msg := range subscribe {
+					tx := msg.(*stream.StreamMessage).Data.(*pb.SubscribeUpdate).GetTransaction().GetTransaction()
+					if tx == nil {
+						continue
+					}
+					o.Observe(WritableAccounts(tx), ComputeUnitPrice(tx.GetTransaction().GetMessage()), time.Now())
+				}
+			}()
+		default:
+			consumer.Update(feeSubscription(accounts))
+		}
+	}
+}
+
+func feeSubscription(accounts []string) *pb.SubscribeRequest {
+	vote := false
+	return &pb.SubscribeRequest{
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"fee_sub": {Vote: &vote, AccountInclude: accounts},
+		},
+	}
+}
+
+// ComputeUnitPrice 解析交易中 SetComputeUnitPrice 设置的价格，没有设置时为 0
+func ComputeUnitPrice(msg *pb.Message) uint64 {
+	keys := msg.GetAccountKeys()
+	for _, ix := range msg.GetInstructions() {
+		if int(ix.ProgramIdIndex) >= len(keys) || !solana.PublicKeyFromBytes(keys[ix.ProgramIdIndex]).Equals(solana.ComputeBudget) {
+			continue
+		}
+		if len(ix.Data) == 9 && ix.Data[0] == 3 {
+			return binary.LittleEndian.Uint64(ix.Data[1:])
+		}
+	}
+	return 0
+}
+
+// WritableAccounts 返回交易写锁定的账户，包括从地址表加载的可写账户
+func WritableAccounts(tx *pb.SubscribeUpdateTransactionInfo) []string {
+	msg := tx.GetTransaction().GetMessage()
+	header := msg.GetHeader()
+	keys := msg.GetAccountKeys()
+	signed := int(header.GetNumRequiredSignatures())
+	writableSigned := signed - int(header.GetNumReadonlySignedAccounts())
+	writableUnsigned := len(keys) - int(header.GetNumReadonlyUnsignedAccounts())
+
+	out := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if i < writableSigned || (i >= signed && i < writableUnsigned) {
+			out = append(out, solana.PublicKeyFromBytes(key).String())
+		}
+	}
+	for _, key := range tx.GetMeta().GetLoadedWritableAddresses() {
+		out = append(out, solana.PublicKeyFromBytes(key).String())
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package global
+
+import (
+	"sync"
+
+	atomic_ "solana-bot/internal/global/utils/atomic"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// WalletBalance 交易钱包的 SOL、WSOL 余额，由钱包账户订阅更新
+type WalletBalance struct {
+	Sol      atomic_.Uint64
+	SolKnown atomic_.Bool // 已收到过 SOL 余额，之前 Sol 的 0 表示未知
+	WSOL     atomic_.Uint64
+	HasWSOL  atomic_.Bool // WSOL 账户已存在
+}
+
+// SetSol 更新 SOL 余额
+func (b *WalletBalance) SetSol(lamports uint64) {
+	b.Sol.Store(lamports)
+	b.SolKnown.Store(true)
+}
+
+var balances sync.Map // owner -> *WalletBalance
+
+// Balance 返回 owner 的余额记录，不存在时创建
+func Balance(owner solana.PublicKey) *WalletBalance {
+	if b, ok := balances.Load(owner); ok {
+		return b.(*WalletBalance)
+	}
+	b, _ := balances.LoadOrStore(owner, &WalletBalance{})
+	return b.(*WalletBalance)
+}
@@ -62,10 +62,7 @@ var (
 	// FloatConst      = []*big.Float{}
 	JitoClient *jitorpc.JitoJsonRpcClient
 
-	LIMIT_TRAILING = 1
-	LIMIT_STOP     = 2
-	LIMIT_PROFIT   = 3
-	LIMITFEE       = 1
+	LIMITFEE = 1
 
 	// Solana RPC vars
 	RPCLast    = atomic.Int32{}
@@ -76,18 +73,9 @@ var (
 	GeyserRPC *rpc.Client
 
 	// Update this
-	RPCs = []string{
-		// "https://mainnet.helius-rpc.com/?api-key=cae6aa3f-a766-4ad7-a0fd-d9ae935d414e",
-		// "https://solana-mainnet.api.syndica.io/api-key/4XiD1jQVGHU7BZCiEPijxX3VXHuvjcEgf9cynwLXZRoJQyBtSg7DNj9K64KLqseJnKx4UnJ4YdfzC4rHyoztzVnPyZaubR6xuFZ",
-		// "https://rpc.shyft.to?api_key=q2zpOxQjvr3rBG0v",
-		// "https://tame-fragrant-sailboat.solana-mainnet.quiknode.pro/35954b63088306a140a40972c52e570e311a67a7/",
-	}
+	RPCs = []string{}
 
-	WRPCs = []string{
-		// "wss://solana-mainnet.api.syndica.io/api-key/4XiD1jQVGHU7BZCiEPijxX3VXHuvjcEgf9cynwLXZRoJQyBtSg7DNj9K64KLqseJnKx4UnJ4YdfzC4rHyoztzVnPyZaubR6xuFZ",
-		// "wss://mainnet.helius-rpc.com/?api-key=cae6aa3f-a766-4ad7-a0fd-d9ae935d414e",
-		// "wss://rpc.shyft.to?api_key=q2zpOxQjvr3rBG0v",
-	}
+	WRPCs       = []string{}
 	JitoServers = []string{""}
 	JitoRPCs    []*rpc.Client
 	RPCLen      = int32(len(RPCs)) - 1
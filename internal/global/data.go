@@ -177,6 +177,25 @@ func GetBuyPriceAndAmount(tx *rpc.GetTransactionResult, token string) (float64,
 	return costPrice, boughtAmount
 }
 
+// GetTokenChange owner 持有的 token 在交易前后的变化，卖出为负；账户在交易中关闭时交易后按 0 计
+func GetTokenChange(tx *rpc.GetTransactionResult, owner, token string) int64 {
+	if tx == nil || tx.Meta == nil {
+		return 0
+	}
+	sum := func(balances []rpc.TokenBalance) int64 {
+		var total int64
+		for _, bal := range balances {
+			if bal.Mint.String() != token || bal.Owner == nil || bal.Owner.String() != owner || bal.UiTokenAmount == nil {
+				continue
+			}
+			amount, _ := strconv.ParseInt(bal.UiTokenAmount.Amount, 10, 64)
+			total += amount
+		}
+		return total
+	}
+	return sum(tx.Meta.PostTokenBalances) - sum(tx.Meta.PreTokenBalances)
+}
+
 func Contains[T comparable](slice []T, value T) bool {
 	for _, item := range slice {
 		if item == value {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -13,57 +14,246 @@ import (
 )
 
 var (
-	NonceAccount = []string{
+	// DefaultNonceAccounts 配置里没有 nonce 账户时使用
+	DefaultNonceAccounts = []string{
 		"DouUTgawHYhmxbU8UghapgBtvBWZmjg9NzBMxazAZKPh",
 		"HJuxZbBwuoDsvHTZ1c3Xqws56bLyGEdfqBtnXP9fVxgB",
 		"EDKVigjAcfMVxMXRCdpfzUQKgXYxb3MYv7g84MnXBjrU",
 	}
-	nonceMutex        = &sync.RWMutex{}
-	currentNonceIndex = 0
-	NonceAccountHash  = make(map[string]solana.Hash)
+	// Nonces 进程内共享的 nonce 账户池
+	Nonces = NewNoncePool(DefaultNonceAccounts)
 )
 
-func SyncNonceAccount(nonceAddress string) {
-	nonceAccount := solana.MustPublicKeyFromBase58(nonceAddress)
-	//首次赋值
-	account, err := GetRPCForRequest().GetAccountInfo(context.Background(), nonceAccount)
-	if err != nil {
-		logx.Must(err)
+// 归还后等待账户订阅推送新 hash 的时间，超时后用 RPC 核对
+const nonceSettle = 2 * time.Second
+
+var ErrNoNonce = errors.New("no nonce account available")
+
+type nonceState struct {
+	hash     solana.Hash
+	ready    bool // 已拿到 hash
+	leased   bool
+	advanced bool // 租用期间 hash 被推进，租出去的 hash 已失效
+	settling bool // 已归还，等待确认 nonce 是否被推进
+}
+
+// NoncePool durable nonce 账户池：每笔进行中的交易独占一个 nonce，
+// 归还后等确认 nonce 是否被落地的交易推进，再借给下一笔
+type NoncePool struct {
+	mu       sync.Mutex
+	accounts []string
+	states   map[string]*nonceState
+	next     int
+	wake     chan struct{}
+	fetch    func(account string) (solana.Hash, error)
+	settle   time.Duration
+}
+
+func NewNoncePool(accounts []string) *NoncePool {
+	p := &NoncePool{
+		accounts: accounts,
+		states:   make(map[string]*nonceState, len(accounts)),
+		wake:     make(chan struct{}),
+		fetch:    FetchNonceHash,
+		settle:   nonceSettle,
 	}
-	if account == nil {
-		logx.Must(errors.New("nonce account not found"))
+	for _, acc := range accounts {
+		p.states[acc] = &nonceState{}
 	}
+	return p
+}
 
-	dec := bin.NewBinDecoder(account.Value.Data.GetBinary()) //.Decode(&nonceAccountData)
-	acc := new(system.NonceAccount)
+// InitNoncePool 用配置的账户替换共享的 nonce 池，size 大于 0 时只使用前 size 个
+func InitNoncePool(accounts []string, size int) *NoncePool {
+	if len(accounts) == 0 {
+		accounts = DefaultNonceAccounts
+	}
+	if size > 0 && size < len(accounts) {
+		accounts = accounts[:size]
+	}
+	Nonces = NewNoncePool(accounts)
+	return Nonces
+}
 
-	err = acc.UnmarshalWithDecoder(dec)
-	if err != nil {
-		logx.Must(fmt.Errorf("solana.NewBinDecoder() => %v", err))
+// Accounts 池中的 nonce 账户
+func (p *NoncePool) Accounts() []string {
+	return p.accounts
+}
+
+// Sync 通过 RPC 拉取所有账户当前的 nonce
+func (p *NoncePool) Sync() {
+	for _, acc := range p.accounts {
+		hash, err := p.fetch(acc)
+		if err != nil {
+			logx.Errorf("[nonce] %s: %v", acc, err)
+			continue
+		}
+		p.Update(acc, hash)
+	}
+}
+
+// Update 收到账户的最新 nonce；和当前不同说明有交易推进了它
+func (p *NoncePool) Update(account string, hash solana.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st, ok := p.states[account]
+	if !ok || (st.ready && st.hash == hash) {
+		if ok && st.settling {
+			// RPC 核对确认 nonce 没有被推进
+			st.settling = false
+			p.broadcast()
+		}
+		return
+	}
+	if st.ready {
+		logx.Infof("[nonce] %s advanced: %s", account, hash)
+	}
+	st.hash = hash
+	st.ready = true
+	if st.leased {
+		st.advanced = true
+		return
 	}
-	hash := solana.Hash(acc.Nonce)
-	nonceMutex.Lock()
-	NonceAccountHash[nonceAddress] = hash
-	nonceMutex.Unlock()
+	st.settling = false
+	p.broadcast()
 }
 
-func UpdataNonceAccountHash(nonceAddress string, nonceHash solana.Hash) {
-	nonceMutex.Lock()
-	NonceAccountHash[nonceAddress] = nonceHash
-	nonceMutex.Unlock()
+func (p *NoncePool) broadcast() {
+	close(p.wake)
+	p.wake = make(chan struct{})
 }
 
-func GetNonceAccountAndHash() (solana.PublicKey, solana.Hash) {
-	nonceMutex.RLock()
-	defer nonceMutex.RUnlock()
+// Lease 独占一个可用的 nonce 账户，全部被占用时等待，直到 ctx 结束
+func (p *NoncePool) Lease(ctx context.Context) (*NonceLease, error) {
+	for {
+		p.mu.Lock()
+		if len(p.accounts) == 0 {
+			p.mu.Unlock()
+			return nil, ErrNoNonce
+		}
+		for i := range p.accounts {
+			idx := (p.next + i) % len(p.accounts)
+			acc := p.accounts[idx]
+			st := p.states[acc]
+			if !st.ready || st.leased || st.settling {
+				continue
+			}
+			st.leased = true
+			p.next = (idx + 1) % len(p.accounts)
+			p.mu.Unlock()
+			return &NonceLease{
+				Account: solana.MustPublicKeyFromBase58(acc),
+				Hash:    st.hash,
+				pool:    p,
+				account: acc,
+			}, nil
+		}
+		wake := p.wake
+		p.mu.Unlock()
 
-	// 轮流获取nonce account
-	index := currentNonceIndex
-	currentNonceIndex = (currentNonceIndex + 1) % len(NonceAccount)
-	logx.Infof("[%s]nonce account hash: %s", NonceAccount[index], NonceAccountHash[NonceAccount[index]])
-	nonceAddress := NonceAccount[index]
-	nonceAccount := solana.MustPublicKeyFromBase58(nonceAddress)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", ErrNoNonce, ctx.Err())
+		case <-wake:
+		}
+	}
+}
 
-	return nonceAccount, NonceAccountHash[NonceAccount[index]]
+func (p *NoncePool) release(account string) {
+	p.mu.Lock()
+	st := p.states[account]
+	st.leased = false
+	if st.advanced {
+		// 订阅已经推送了新的 nonce，可以直接再用
+		st.advanced = false
+		p.broadcast()
+		p.mu.Unlock()
+		return
+	}
+	st.settling = true
+	p.mu.Unlock()
 
+	go p.confirm(account)
+}
+
+// confirm 等订阅推送新 nonce，超时后通过 RPC 核对
+func (p *NoncePool) confirm(account string) {
+	for {
+		time.Sleep(p.settle)
+
+		p.mu.Lock()
+		settling := p.states[account].settling
+		p.mu.Unlock()
+		if !settling {
+			return
+		}
+
+		hash, err := p.fetch(account)
+		if err != nil {
+			logx.Errorf("[nonce] %s: %v", account, err)
+			continue
+		}
+		p.Update(account, hash)
+	}
+}
+
+// Stats 返回空闲、租用中、等待确认的账户数
+func (p *NoncePool) Stats() (free, leased, settling int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, st := range p.states {
+		switch {
+		case st.leased:
+			leased++
+		case st.settling || !st.ready:
+			settling++
+		default:
+			free++
+		}
+	}
+	return
+}
+
+// NonceLease 一次 nonce 租用，交易发送结束后必须 Release
+type NonceLease struct {
+	Account solana.PublicKey
+	Hash    solana.Hash
+	pool    *NoncePool
+	account string
+	once    sync.Once
+}
+
+// Release 归还 nonce，可以重复调用
+func (l *NonceLease) Release() {
+	l.once.Do(func() {
+		l.pool.release(l.account)
+	})
+}
+
+// DecodeNonceHash 解析 nonce 账户数据中的 nonce
+func DecodeNonceHash(data []byte) (solana.Hash, error) {
+	acc := new(system.NonceAccount)
+	if err := acc.UnmarshalWithDecoder(bin.NewBinDecoder(data)); err != nil {
+		return solana.Hash{}, fmt.Errorf("decode nonce account: %w", err)
+	}
+	return solana.Hash(acc.Nonce), nil
+}
+
+// FetchNonceHash 通过 RPC 读取 nonce 账户当前的 nonce
+func FetchNonceHash(nonceAddress string) (solana.Hash, error) {
+	nonceAccount, err := solana.PublicKeyFromBase58(nonceAddress)
+	if err != nil {
+		return solana.Hash{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	account, err := GetRPCForRequest().GetAccountInfo(ctx, nonceAccount)
+	if err != nil {
+		return solana.Hash{}, err
+	}
+	if account == nil || account.Value == nil {
+		return solana.Hash{}, errors.New("nonce account not found")
+	}
+	return DecodeNonceHash(account.Value.Data.GetBinary())
 }
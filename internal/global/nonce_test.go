@@ -0,0 +1,97 @@
+package global
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestNoncePoolLease(t *testing.T) {
+	accounts := []string{
+		solana.NewWallet().PublicKey().String(),
+		solana.NewWallet().PublicKey().String(),
+	}
+	var mu sync.Mutex
+	chain := map[string]solana.Hash{
+		accounts[0]: {1},
+		accounts[1]: {2},
+	}
+	p := NewNoncePool(accounts)
+	p.settle = 10 * time.Millisecond
+	p.fetch = func(account string) (solana.Hash, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return chain[account], nil
+	}
+
+	// 还没拿到 hash 时不能租用
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	_, err := p.Lease(ctx)
+	cancel()
+	if !errors.Is(err, ErrNoNonce) {
+		t.Fatalf("lease before sync: %v", err)
+	}
+
+	p.Sync()
+	a, err := p.Lease(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Lease(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Account == b.Account {
+		t.Fatalf("same nonce leased twice: %s", a.Account)
+	}
+
+	// 全部占用时等待归还
+	got := make(chan *NonceLease)
+	go func() {
+		l, err := p.Lease(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- l
+	}()
+	select {
+	case <-got:
+		t.Fatal("lease should wait while all nonces are leased")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// a 的交易落地，订阅推送了新的 nonce，归还后立即可用
+	mu.Lock()
+	chain[a.account] = solana.Hash{3}
+	mu.Unlock()
+	p.Update(a.account, solana.Hash{3})
+	a.Release()
+	a.Release()
+
+	var c *NonceLease
+	select {
+	case c = <-got:
+	case <-time.After(time.Second):
+		t.Fatal("waiting lease not woken")
+	}
+	if c.account != a.account || c.Hash != (solana.Hash{3}) {
+		t.Fatalf("lease = %s %s, want %s with advanced hash", c.account, c.Hash, a.account)
+	}
+
+	// b 的交易没落地：归还后等 RPC 核对，nonce 不变再借出
+	b.Release()
+	if free, leased, settling := p.Stats(); free != 0 || leased != 1 || settling != 1 {
+		t.Fatalf("stats = %d %d %d", free, leased, settling)
+	}
+	d, err := p.Lease(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.account != b.account || d.Hash != b.Hash {
+		t.Fatalf("lease = %s %s, want %s %s", d.account, d.Hash, b.account, b.Hash)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	associated_token_account "github.com/gagliardetto/solana-go/programs/associated-token-account"
@@ -21,11 +22,11 @@ import (
 
 func CreateSOLAccountOrWrap(instrs *[]solana.Instruction, owner solana.PublicKey, amountIn *big.Int) {
 	var wrapAmountNeeded uint64
-	bal := SolATA_Balance.Load()
+	balance := Balance(owner)
 
-	if SolATA.Load() {
+	if balance.HasWSOL.Load() {
 		targetBalance := amountIn.Uint64()
-		wsolAccountBalanceU64 := bal.Uint64()
+		wsolAccountBalanceU64 := balance.WSOL.Load()
 		if wsolAccountBalanceU64 < targetBalance {
 			wrapAmountNeeded = targetBalance - wsolAccountBalanceU64
 		}
@@ -40,7 +41,7 @@ func CreateSOLAccountOrWrap(instrs *[]solana.Instruction, owner solana.PublicKey
 		*instrs = append(*instrs, system.NewTransferInstruction(wrapAmountNeeded, owner, solATA).Build())
 		*instrs = append(*instrs, token_program.NewSyncNativeInstruction(solATA).Build())
 	}
-	SolATA.Store(true)
+	balance.HasWSOL.Store(true)
 }
 
 func GetBalanceNoDecimals(hexKey string) *big.Float {
@@ -233,3 +234,33 @@ func TrimTrailing(str string) string {
 	}
 	return strings.TrimRight(strings.TrimRight(str, "0"), ".")
 }
+
+// mint 的精度不会变，查到一次后缓存
+var mintDecimals sync.Map // solana.PublicKey -> uint8
+
+// CachedMintDecimals 已经查到过的 mint 精度
+func CachedMintDecimals(mint solana.PublicKey) (uint8, bool) {
+	if d, ok := mintDecimals.Load(mint); ok {
+		return d.(uint8), true
+	}
+	return 0, false
+}
+
+// GetMintDecimals 查询 mint 的精度，Token 和 Token-2022 的 decimals 都在第 44 字节
+func GetMintDecimals(mint solana.PublicKey) (uint8, error) {
+	if d, ok := CachedMintDecimals(mint); ok {
+		return d, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	acc, err := GetRPCForRequest().GetAccountInfo(ctx, mint)
+	if err != nil {
+		return 0, err
+	}
+	data := acc.Value.Data.GetBinary()
+	if len(data) < 45 {
+		return 0, fmt.Errorf("mint %s: account data too short", mint)
+	}
+	mintDecimals.Store(mint, data[44])
+	return data[44], nil
+}
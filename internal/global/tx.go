@@ -1,29 +1,170 @@
 package global
 
-import "github.com/gagliardetto/solana-go"
+import (
+	"maps"
+	"slices"
+	"sync/atomic"
+
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+)
+
+// addressTables 新建 TxBuilder 默认使用的地址表
+var addressTables atomic.Pointer[map[solana.PublicKey]solana.PublicKeySlice]
+
+// SetAddressTables 设置新建 TxBuilder 默认使用的地址表，为空时生成 legacy 交易
+func SetAddressTables(tables map[solana.PublicKey]solana.PublicKeySlice) {
+	addressTables.Store(&tables)
+}
+
+// staticAccounts 不从地址表加载的账户，例如通道小费账户：Jito 等中继只认静态账户里的小费转账
+var staticAccounts atomic.Pointer[map[solana.PublicKey]bool]
+
+// SetStaticAccounts 设置不从地址表加载的账户，即使地址表里有
+func SetStaticAccounts(keys ...solana.PublicKey) {
+	set := make(map[solana.PublicKey]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	staticAccounts.Store(&set)
+}
+
+// Trade 交易意图，不上链的 paper 通道按它在本地池子状态上模拟成交
+type Trade struct {
+	Mint     solana.PublicKey
+	Program  solana.PublicKey // 池子程序，模拟成交失败时的错误码按它解析
+	Buy      bool
+	AmountIn uint64 // 买入为 lamports，卖出为 token 最小单位
+	// MinAmountOut 滑点下限，成交时报价低于它和链上一样失败；0 为不限
+	MinAmountOut uint64
+	// Quote 按成交时的池子状态估算 amountIn 能换到的数量，返回 0 表示无法成交
+	Quote func(amountIn uint64) uint64
+}
+
+// Programs 模拟成交结果里错误的指令序号对应的程序，传给 txerr.Decode：
+// 0 为池子程序，1 为 Token 程序，2 为 System 程序
+func (t *Trade) Programs() []solana.PublicKey {
+	return []solana.PublicKey{t.Program, solana.TokenProgramID, solana.SystemProgramID}
+}
 
 type TxBuilder struct {
 	payer        solana.PublicKey
 	blockhash    solana.Hash
 	instructions []solana.Instruction
+	tables       map[solana.PublicKey]solana.PublicKeySlice
+	trade        *Trade
 }
 
 func NewTxBuilder(payer solana.PublicKey, blockhash solana.Hash) *TxBuilder {
-	return &TxBuilder{
+	b := &TxBuilder{
 		payer:        payer,
 		blockhash:    blockhash,
 		instructions: make([]solana.Instruction, 0),
 	}
+	if tables := addressTables.Load(); tables != nil {
+		b.tables = *tables
+	}
+	return b
+}
+
+// SetAddressTables 指定这笔交易使用的地址表，nil 表示生成 legacy 交易
+func (b *TxBuilder) SetAddressTables(tables map[solana.PublicKey]solana.PublicKeySlice) {
+	b.tables = tables
+}
+
+// SetTrade 附上交易意图，只有 paper 通道使用
+func (b *TxBuilder) SetTrade(trade *Trade) {
+	b.trade = trade
+}
+
+func (b *TxBuilder) Trade() *Trade {
+	return b.trade
 }
 
 func (b *TxBuilder) AddInstruction(instrs ...solana.Instruction) {
 	b.instructions = append(b.instructions, instrs...)
 }
 
-func (b *TxBuilder) BuildTx(signers []solana.PrivateKey) (*solana.Transaction, error) {
-	return solana.NewTransaction(
-		b.instructions,
-		b.blockhash,
-		solana.TransactionPayer(b.payer),
-	)
+func (b *TxBuilder) Instructions() []solana.Instruction {
+	return b.instructions
+}
+
+// SetComputeUnitLimit 替换已有的 CU 上限指令，没有时加在最前面；
+// 生成新的指令列表，不影响已经复制出去的 TxBuilder
+func (b *TxBuilder) SetComputeUnitLimit(units uint32) {
+	limit := computebudget.NewSetComputeUnitLimitInstruction(units).Build()
+	instrs := make([]solana.Instruction, 0, len(b.instructions)+1)
+	replaced := false
+	for _, ins := range b.instructions {
+		if !replaced && IsComputeUnitLimit(ins) {
+			ins, replaced = limit, true
+		}
+		instrs = append(instrs, ins)
+	}
+	if !replaced {
+		instrs = append([]solana.Instruction{limit}, instrs...)
+	}
+	b.instructions = instrs
+}
+
+// IsComputeUnitLimit ins 是 ComputeBudget 的 SetComputeUnitLimit 指令
+func IsComputeUnitLimit(ins solana.Instruction) bool {
+	if !ins.ProgramID().Equals(solana.ComputeBudget) {
+		return false
+	}
+	data, err := ins.Data()
+	return err == nil && len(data) > 0 && data[0] == computebudget.Instruction_SetComputeUnitLimit
+}
+
+// Build 生成未签名的交易；有地址表时生成 v0 交易，签名账户、调用的程序和 SetStaticAccounts 设置的账户
+// 不会从地址表加载
+func (b *TxBuilder) Build() (*solana.Transaction, error) {
+	opts := []solana.TransactionOption{solana.TransactionPayer(b.payer)}
+	if len(b.tables) > 0 {
+		opts = append(opts, solana.TransactionAddressTables(withoutStatic(b.tables)))
+	}
+	return solana.NewTransaction(b.instructions, b.blockhash, opts...)
+}
+
+// withoutStatic 地址表里的静态账户换成地址表自己的地址，交易里不会用到它，
+// 其余账户在表里的位置不变
+func withoutStatic(tables map[solana.PublicKey]solana.PublicKeySlice) map[solana.PublicKey]solana.PublicKeySlice {
+	static := staticAccounts.Load()
+	if static == nil || len(*static) == 0 {
+		return tables
+	}
+	var out map[solana.PublicKey]solana.PublicKeySlice
+	for table, addrs := range tables {
+		if !slices.ContainsFunc(addrs, func(addr solana.PublicKey) bool { return (*static)[addr] }) {
+			continue
+		}
+		if out == nil {
+			out = maps.Clone(tables)
+		}
+		replaced := slices.Clone(addrs)
+		for i, addr := range replaced {
+			if (*static)[addr] {
+				replaced[i] = table
+			}
+		}
+		out[table] = replaced
+	}
+	if out == nil {
+		return tables
+	}
+	return out
+}
+
+// BuildTx 生成交易并由 signers 签名
+func (b *TxBuilder) BuildTx(signers ...signer.Signer) (*solana.Transaction, error) {
+	tx, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := signer.Sign(tx, signers...); err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
@@ -18,7 +18,7 @@ func WriteProfit(filename string, inputChan pubsub.Subscriber) {
 
 	stat, _ := file.Stat()
 	if stat.Size() == 0 {
-		headers := []string{"time", "token", "buy", "profit"}
+		headers := []string{"time", "token", "buy", "profit", "wallet"}
 		writer.Write(headers)
 	}
 
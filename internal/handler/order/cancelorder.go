@@ -0,0 +1,29 @@
+package order
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"solana-bot/internal/logic/order"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+)
+
+func CancelOrder(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CancelOrderRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := order.NewCancelOrder(r.Context(), svcCtx)
+		resp, err := l.CancelOrder(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
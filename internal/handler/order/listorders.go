@@ -0,0 +1,29 @@
+package order
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"solana-bot/internal/logic/order"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+)
+
+func ListOrders(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ListOrdersRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := order.NewListOrders(r.Context(), svcCtx)
+		resp, err := l.ListOrders(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package risk
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"solana-bot/internal/logic/risk"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+)
+
+func ResetRisk(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ResetRiskRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := risk.NewResetRisk(r.Context(), svcCtx)
+		resp, err := l.ResetRisk(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/zeromicro/go-zero/rest"
 
+	order "solana-bot/internal/handler/order"
+	risk "solana-bot/internal/handler/risk"
+	status "solana-bot/internal/handler/status"
 	version "solana-bot/internal/handler/version"
 )
 
@@ -18,6 +21,52 @@ var (
 )
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	{
+		server.AddRoutes(
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/orders/:id/cancel",
+					Handler: order.CancelOrder(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/orders",
+					Handler: order.ListOrders(serverCtx),
+				},
+			},
+			rest.WithPrefix("/api/v1"),
+		)
+	}
+	{
+		server.AddRoutes(
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/risk",
+					Handler: risk.GetRisk(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/risk/reset",
+					Handler: risk.ResetRisk(serverCtx),
+				},
+			},
+			rest.WithPrefix("/api/v1"),
+		)
+	}
+	{
+		server.AddRoutes(
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/status",
+					Handler: status.GetStatus(serverCtx),
+				},
+			},
+			rest.WithPrefix("/api/v1"),
+		)
+	}
 	{
 		server.AddRoutes(
 			[]rest.Route{
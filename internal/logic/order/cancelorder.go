@@ -0,0 +1,34 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"solana-bot/internal/monitor"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CancelOrder struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCancelOrder(ctx context.Context, svcCtx *svc.ServiceContext) *CancelOrder {
+	return &CancelOrder{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CancelOrder 撤销一个还没触发的条件单
+func (l *CancelOrder) CancelOrder(req *types.CancelOrderRequest) (resp *types.CancelOrderResponse, err error) {
+	if err := monitor.CancelOrder(req.Id); err != nil {
+		return nil, fmt.Errorf("order %d: %w", req.Id, err)
+	}
+	return &types.CancelOrderResponse{Message: "cancelled"}, nil
+}
@@ -0,0 +1,46 @@
+package order
+
+import (
+	"context"
+
+	"solana-bot/internal/monitor"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListOrders struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListOrders(ctx context.Context, svcCtx *svc.ServiceContext) *ListOrders {
+	return &ListOrders{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListOrders 当前挂着的条件单，all 时带上最近已经成交、撤销的
+func (l *ListOrders) ListOrders(req *types.ListOrdersRequest) (resp *types.ListOrdersResponse, err error) {
+	resp = &types.ListOrdersResponse{Orders: []types.Order{}}
+	for _, o := range monitor.Orders.List(req.Mint, req.All) {
+		resp.Orders = append(resp.Orders, types.Order{
+			Id:      o.ID,
+			Wallet:  o.Wallet,
+			Mint:    o.Mint,
+			Kind:    string(o.Kind),
+			Price:   o.Price,
+			Amount:  o.Amount,
+			Rule:    o.Rule,
+			State:   string(o.State),
+			Reason:  o.Reason,
+			Created: o.Created.UnixMilli(),
+			Updated: o.Updated.UnixMilli(),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+package risk
+
+import (
+	"context"
+
+	"solana-bot/internal/monitor"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetRisk struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetRisk(ctx context.Context, svcCtx *svc.ServiceContext) *GetRisk {
+	return &GetRisk{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetRisk 当前敞口、窗口内已实现盈亏和熔断状态
+func (l *GetRisk) GetRisk(req *types.GetRiskRequest) (resp *types.GetRiskResponse, err error) {
+	s := monitor.Risk.Status()
+	resp = &types.GetRiskResponse{
+		Exposure:   s.Exposure,
+		WindowPnl:  s.WindowPnl,
+		Halted:     s.Halted,
+		HaltReason: s.HaltReason,
+		Positions:  []types.RiskPosition{},
+	}
+	if s.Halted {
+		resp.HaltedAt = s.HaltedAt.UnixMilli()
+	}
+	for _, p := range s.Positions {
+		resp.Positions = append(resp.Positions, types.RiskPosition{
+			Ticket:    p.Ticket,
+			Mint:      p.Mint,
+			Strategy:  p.Strategy,
+			Leader:    p.Leader,
+			Launchpad: p.Launchpad,
+			Amount:    p.Amount,
+			Opened:    p.Opened.UnixMilli(),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,33 @@
+package risk
+
+import (
+	"context"
+
+	"solana-bot/internal/monitor"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResetRisk struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResetRisk(ctx context.Context, svcCtx *svc.ServiceContext) *ResetRisk {
+	return &ResetRisk{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ResetRisk 人工解除亏损熔断，恢复开仓
+func (l *ResetRisk) ResetRisk(req *types.ResetRiskRequest) (resp *types.ResetRiskResponse, err error) {
+	if !monitor.ResetRisk() {
+		return &types.ResetRiskResponse{Message: "not halted"}, nil
+	}
+	return &types.ResetRiskResponse{Message: "resumed"}, nil
+}
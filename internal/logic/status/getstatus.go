@@ -0,0 +1,65 @@
+package status
+
+import (
+	"context"
+	"time"
+
+	"solana-bot/internal/monitor"
+	"solana-bot/internal/svc"
+	"solana-bot/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetStatus struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetStatus(ctx context.Context, svcCtx *svc.ServiceContext) *GetStatus {
+	return &GetStatus{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetStatus 各发送通道的出价和落地统计，各 Geyser 来源本周期的抢先率和延迟；监控没有运行时为空
+func (l *GetStatus) GetStatus(req *types.GetStatusRequest) (resp *types.GetStatusResponse, err error) {
+	resp = &types.GetStatusResponse{
+		Channels: []types.ChannelStatus{},
+		Streams:  []types.StreamStatus{},
+	}
+	p := monitor.PumpMonitor
+	if p == nil {
+		return resp, nil
+	}
+	resp.Running = true
+	for _, r := range p.ChannelReport() {
+		c := types.ChannelStatus{
+			Channel:  r.Channel,
+			Tip:      r.Tip,
+			Sent:     r.Sent,
+			Landed:   r.Landed,
+			AvgSlots: r.AvgSlots,
+			Buckets:  []types.TipBucket{},
+		}
+		for _, b := range r.Buckets {
+			c.Buckets = append(c.Buckets, types.TipBucket{Tip: b.Tip, Samples: b.Samples, Probability: b.Probability})
+		}
+		resp.Channels = append(resp.Channels, c)
+	}
+	for _, r := range p.StreamReport() {
+		resp.Streams = append(resp.Streams, types.StreamStatus{
+			Source:   r.Source,
+			Seen:     r.Seen,
+			Wins:     r.Wins,
+			WinRate:  r.WinRate,
+			Coverage: r.Coverage,
+			AvgLagMs: float64(r.AvgLag) / float64(time.Millisecond),
+			MaxLagMs: float64(r.MaxLag) / float64(time.Millisecond),
+		})
+	}
+	return resp, nil
+}
@@ -10,7 +10,6 @@ import (
 	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
 	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
 
-	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/gagliardetto/solana-go"
 	"github.com/imzhongqi/okxos/dex"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -187,21 +186,3 @@ func calculateBreakEvenAmount(buyPrice, currentPrice *big.Float, amountBought *b
 
 	return breakEvenAmount
 }
-
-type TxDeduper struct {
-	filter *bloom.BloomFilter
-}
-
-func NewTxDeduper(n uint, fpRate float64) *TxDeduper {
-	return &TxDeduper{
-		filter: bloom.NewWithEstimates(n, fpRate), // n=预计交易数量, fpRate=可接受误判率
-	}
-}
-
-func (d *TxDeduper) SeenOrAdd(sig string) bool {
-	if d.filter.Test([]byte(sig)) {
-		return true // 已经见过（可能误判）
-	}
-	d.filter.Add([]byte(sig))
-	return false // 第一次见到
-}
@@ -1,73 +1,115 @@
 package monitor
 
 import (
+	"errors"
 	"math/big"
-	"time"
+	"strings"
+
+	"solana-bot/internal/orders"
+	"solana-bot/internal/positions"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-func (p *PumpFunMonitor) SetLimitSell(ts *TokenSwap) {
-	tokenAddress := ts.Token.TokenAddress
-	price, _ := ts.MySwap.BuyPrice.Load().Float64()
-	amount := ts.MySwap.BuyAmount.Load()
-	logx.Infof("[%s] 买入价格: %.15f,买入数量:%d", tokenAddress, price, amount.Int64())
+// Orders 所有仓位挂着的条件单，整个进程共用；池子价格每次更新时撮合，不再每个单轮询价格
+var Orders = orders.NewEngine()
 
-	//止损单，低于10%止损
-	// go p.DoLimitSell(ts, price*0.7, amount, false)
-	//止盈单
-	sellAmount := new(big.Int)
-	sellAmountFloat := new(big.Float).Mul(new(big.Float).SetInt(amount), big.NewFloat(0.8))
-	sellAmount, _ = sellAmountFloat.Int(sellAmount)
-	go p.DoLimitSell(ts, price*1.5, sellAmount, true)
+// limitRulePrefix 手动挂的限价单的规则名前缀，和退出规则挂出的单区分开
+const limitRulePrefix = "limit:"
 
-	go p.DoLimitSell(ts, price*4, new(big.Int).Mul(sellAmount, big.NewInt(5)), true)
+// placeLimit 挂一个价格触发的卖出单：profitOrLoss 为 true 时价格涨到 limitPrice 及以上止盈，否则跌到及以下止损
+func (p *PumpFunMonitor) placeLimit(ts *TokenSwap, limitPrice float64, amount *big.Int, profitOrLoss bool) uint64 {
+	tokenAddress := ts.Token.TokenAddress
+	wallet := orderWallet(ts)
+	kind := orders.StopLoss
+	if profitOrLoss {
+		kind = orders.TakeProfit
+	}
 
-	go p.DoLimitSell(ts, price*8, new(big.Int).Mul(sellAmount, big.NewInt(10)), true)
+	id := Orders.Place(orders.Order{
+		Wallet: wallet,
+		Mint:   tokenAddress,
+		Kind:   kind,
+		Price:  limitPrice,
+		Amount: amount.Uint64(),
+		Rule:   limitRulePrefix + string(kind),
+	}, func(o orders.Order) error {
+		logx.Infof("[%s] 订单 %d 触发 %s, 触发价: %.15f, 数量: %d", tokenAddress, o.ID, o.Kind, o.Price, o.Amount)
+		defer syncLimits(wallet, tokenAddress)
+		return p.ExecuteSell(ts, new(big.Int).SetUint64(o.Amount), o.Rule)
+	})
+	logx.Infof("[%s] 挂单 %d %s, 触发价: %.15f, 数量: %d", tokenAddress, id, kind, limitPrice, amount.Uint64())
+	syncLimits(wallet, tokenAddress)
 
+	watchOrders(ts)
+	if price := ts.Token.TokenPrice.Load(); price != nil {
+		f, _ := price.Float64()
+		Orders.OnPrice(tokenAddress, f)
+	}
+	return id
 }
 
-/*
- */
-func (p *PumpFunMonitor) DoLimitSell(ts *TokenSwap, limitPrice float64, amount *big.Int, profitOrLoss bool) {
-	tokenAddress := ts.Token.TokenAddress
-	logx.Infof("[%s] 开始监听止盈/止损 , 待监听价格: %.15f ", tokenAddress, limitPrice)
-	for {
-		select {
-		case <-ts.Ctx.Done():
-			logx.Info("收到停止信号，停止限价单监听")
-			return
-		default:
-			// 获取当前价格
-			nowPrice, _ := ts.Token.TokenPrice.Load().Float64()
-			if nowPrice <= 0 {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-			logx.Debugf("[%s] 当前价格: %.15f, 待监听价格: %.15f", tokenAddress, nowPrice, limitPrice)
-
-			// 触发止损
-			if !profitOrLoss && nowPrice < limitPrice {
-				logx.Infof("[%s] 当前价格: %.15f, 触发止损数量: %d", tokenAddress, nowPrice, amount.Int64())
-				err := p.ExecuteSell(ts, amount)
-				if err != nil {
-					continue
-				}
-				break
+// watchOrders 仓位结束时撤掉还没触发的单，每个仓位只启动一次
+func watchOrders(ts *TokenSwap) {
+	if !ts.ordersArmed.CompareAndSwap(false, true) {
+		return
+	}
+	tokenAddress, wallet := ts.Token.TokenAddress, orderWallet(ts)
+	go func() {
+		<-ts.Ctx.Done()
+		if n := Orders.CancelToken(wallet, tokenAddress, "position closed"); n > 0 {
+			logx.Infof("[%s] 仓位结束，撤销 %d 个订单", tokenAddress, n)
+		}
+		exitOrders.Range(func(id, rules any) bool {
+			if rules.(*exitRules).ts == ts {
+				exitOrders.Delete(id)
 			}
+			return true
+		})
+	}()
+}
 
-			// 触发止盈
-			if profitOrLoss && nowPrice > limitPrice {
-				logx.Infof("[%s] 当前价格: %.15f, 触发止盈数量: %d", tokenAddress, nowPrice, amount.Int64())
-				err := p.ExecuteSell(ts, amount)
-				if err != nil {
-					continue
-				}
-				break
-			}
+// orderWallet 订单记录的钱包地址
+func orderWallet(ts *TokenSwap) string {
+	if ts.Wallet == nil {
+		return ""
+	}
+	return ts.Wallet.PublicKey().String()
+}
 
-			time.Sleep(400 * time.Millisecond)
+// CancelOrder 撤销一个还没触发的订单；退出规则挂出的单撤销后规则不再触发
+func CancelOrder(id uint64) error {
+	if err := Orders.Cancel(id, "operator"); err != nil {
+		return err
+	}
+	// 撤销后订单在历史里，按它找回钱包和 token
+	for _, o := range Orders.List("", true) {
+		if o.ID == id {
+			logx.Infof("[%s] 撤销订单 %d", o.Mint, id)
+			disarmExitOrder(o)
+			syncLimits(o.Wallet, o.Mint)
+			break
 		}
 	}
+	return nil
+}
 
+// syncLimits 把钱包在 mint 上还挂着的限价单写回仓位，重启后据此重新挂单；退出规则的单随规则状态保存
+func syncLimits(wallet, mint string) {
+	if positionStore == nil {
+		return
+	}
+	var limits []positions.Limit
+	for _, o := range Orders.List(mint, false) {
+		if o.Wallet == wallet && o.State == orders.Pending && strings.HasPrefix(o.Rule, limitRulePrefix) {
+			limits = append(limits, positions.Limit{Price: o.Price, Amount: o.Amount})
+		}
+	}
+	err := positionStore.Update(wallet, mint, func(pos *positions.Position) error {
+		pos.Exit.Limits = limits
+		return nil
+	})
+	if err != nil && !errors.Is(err, positions.ErrNotFound) {
+		logx.Errorf("[%s]:记录订单失败: %v", mint, err)
+	}
 }
@@ -1,13 +1,14 @@
 package monitor
 
 import (
-	"fmt"
 	"math"
 	"solana-bot/internal/global/utils"
 	atomic_ "solana-bot/internal/global/utils/atomic"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
 )
 
 var Profit *ProfitStrategy
@@ -37,7 +38,7 @@ type ProfitStrategy struct {
 	lastUpdate           time.Time
 	lastBigWin           time.Time
 	lastInvestmentAmount atomic_.Float64 // 上一次实际投入金额
-	maxMultiplier        float64         // 🧱 最大倍数限制
+	maxMultiplier        float64         // 🧱 最大倍数限制，亏损后不加码
 	GetMultiplierFn      func() float64
 	SetMultiplierFn      func(float64)
 }
@@ -48,16 +49,16 @@ func NewProfitStrategy(getFn func() float64, setFn func(float64)) *ProfitStrateg
 		SetMultiplierFn: setFn,
 		profits:         []float64{},
 		lastUpdate:      time.Now(),
-		maxMultiplier:   5.0,
+		maxMultiplier:   1.0,
 	}
 }
 
-// 接收格式："time,token,buyAmount,profit"
+// 接收格式："time,token,buyAmount,profit,wallet"
 func (ps *ProfitStrategy) OnProfitInfo(info string) {
 	info = strings.ReplaceAll(info, "\"", "")
 	parts := strings.Split(info, ",")
 	if len(parts) < 4 {
-		fmt.Println("[策略解析错误] 格式错误，跳过:", info)
+		logx.Errorf("[策略解析错误] 格式错误，跳过: %s", info)
 		return
 	}
 	token := parts[1]
@@ -69,13 +70,13 @@ func (ps *ProfitStrategy) OnProfitInfo(info string) {
 	profit, err2 := strconv.ParseFloat(profitStr, 64)
 
 	if err1 != nil || err2 != nil || buyAmount == 0 {
-		fmt.Printf("[策略解析错误] buyAmount=%.4f profit=%s\n", buyAmount, profitStr)
+		logx.Errorf("[策略解析错误] buyAmount=%.4f profit=%s", buyAmount, profitStr)
 		return
 	}
 
 	// 计算收益率
 	profitRate := profit / buyAmount * 100 // 转为百分比
-	fmt.Printf("[分析] %s收益率: %.4f%%\n", token, profitRate)
+	logx.Infof("[分析] %s收益率: %.4f%%", token, profitRate)
 
 	ps.profits = append(ps.profits, profitRate)
 
@@ -99,43 +100,24 @@ func (ps *ProfitStrategy) evaluateStrategy(currentProfit float64) {
 
 	multiplier := ps.GetMultiplierFn()
 	maxMultiplier := ps.maxMultiplier
-	lastInvestAmount := ps.lastInvestmentAmount.Load()
 
 	// ✅ 回本优先：回到盈利或持平，恢复初始倍数
 	if currentProfit >= 0 && multiplier > 1.0 {
-		fmt.Println("[策略建议] 已回本，重置倍数为 1.0x")
+		logx.Info("[策略建议] 已回本，重置倍数为 1.0x")
 		ps.SetMultiplierFn(1.0)
 		ps.lastMultiplier = 1.0
 		return
 	}
 
-	// ✅ 避免无限加码：只在亏损大于 5% 时尝试加码
-	if currentProfit < -5 {
-		if time.Since(ps.lastBigWin) < 3*time.Minute {
-			fmt.Println("[策略保护] 刚刚大赚，暂不加码")
-			return
-		}
-		if lastInvestAmount <= 0 {
-			fmt.Println("[策略警告] 上次投入为 0，跳过加码")
-			return
-		}
-
-		lossRatio := -currentProfit / 100.0 // 转换为 0.05 这种比例
-		newMultiplier := multiplier * (1.0 + lossRatio)
-
-		newMultiplier = math.Min(newMultiplier, maxMultiplier)
-		if newMultiplier < 1.0 {
-			newMultiplier = 1.0
-		}
-
-		fmt.Printf("[策略建议] 当前亏损 %.2f%%，建议增仓至 %.2fx\n", -currentProfit, newMultiplier)
-		ps.lastMultiplier = multiplier
-		ps.SetMultiplierFn(newMultiplier)
+	// ✅ 亏损后不加码：倍数超过上限时压回上限，亏损由风控熔断处理
+	if multiplier > maxMultiplier {
+		logx.Infof("[策略建议] 当前收益率 %.2f%%，倍数 %.2fx 超过上限，降至 %.2fx", currentProfit, multiplier, maxMultiplier)
+		ps.lastMultiplier = maxMultiplier
+		ps.SetMultiplierFn(maxMultiplier)
 		return
 	}
 
-	// ✅ 小亏容忍，不动
-	fmt.Printf("[策略建议] 当前收益率 %.2f%%，维持当前仓位 %.2fx\n", currentProfit, multiplier)
+	logx.Infof("[策略建议] 当前收益率 %.2f%%，维持当前仓位 %.2fx", currentProfit, multiplier)
 }
 
 // 获取当前的买入系数
@@ -145,7 +127,7 @@ func (p *PumpFunMonitor) GetMultiplier() float64 {
 
 // 设置买入系数（需控制上下限）
 func (p *PumpFunMonitor) SetMultiplier(newVal float64) {
-	// 限制范围在 [0.1, 1.0]
-	newVal = math.Max(0.2, math.Min(4.0, newVal))
+	// 限制范围在 [0.2, 1.0]，不会放大配置的买入金额
+	newVal = math.Max(0.2, math.Min(1.0, newVal))
 	p.buyMultiplier.Store(newVal)
 }
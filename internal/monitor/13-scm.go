@@ -1,12 +1,9 @@
 package monitor
 
 import (
-	"context"
 	"math/big"
 	"solana-bot/internal/client"
 	"solana-bot/internal/global"
-	"solana-bot/internal/stream"
-	"sync"
 
 	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
 
@@ -17,58 +14,28 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
-func (p *PumpFunMonitor) workerForScm() {
-	p.ListenScmAccountTransation()
+func init() {
+	RegisterStrategy(&scmStrategy{})
 }
 
-func (p *PumpFunMonitor) ListenScmAccountTransation() {
-
-	logx.Infof("[%s]:监听账户交易", "all")
-	subscribe := make(chan interface{})
-	var subscription pb.SubscribeRequest
-	commitment := pb.CommitmentLevel_PROCESSED
-	subscription.Commitment = &commitment
-	subscription.Transactions = make(map[string]*pb.SubscribeRequestFilterTransactions)
-	failed := false
-	vote := false
-	subscription.Transactions["transactions_sub"] = &pb.SubscribeRequestFilterTransactions{
-		Failed: &failed,
-		Vote:   &vote,
-	}
-
-	subscription.Transactions["transactions_sub"].AccountInclude = []string{"2x4Mp5dLefbx8V684sQDxNxUwgp3bqoibAgacLqs5z19"}
-
-	var once sync.Once
-	for _, s := range p.streams {
-		s.Subscribe(p.ctx, &subscription, &once, subscribe)
-	}
-
-	p.runWithCtx(context.Background(), subscribe, func(msg interface{}) {
-		var got *pb.SubscribeUpdate
-		v := msg.(*stream.StreamMessage)
-		got = v.Data.(*pb.SubscribeUpdate)
-		tx := got.GetTransaction()
-		if tx == nil {
-			return
-		}
-		// token, err := p.ParseCreateTransaction(tx.Transaction.Transaction, tx.Transaction.Meta)
-		// if err != nil || token == nil {
-		// 	return
-		// }
-
-		swapInfo, err := ParseSwapTransaction(tx.Transaction.Transaction, tx.Transaction.Meta)
-		if err != nil || swapInfo == nil {
-			return
-		}
+// scmStrategy 监听 scm 账户的买入，按同样数量卖出自己的库存，不开新仓
+type scmStrategy struct {
+	BaseStrategy
+}
 
-		logx.Infof("[%s]:收到scm交易{%s}", swapInfo.TokenOutMint, solana.SignatureFromBytes(tx.Transaction.Signature).String())
+func (*scmStrategy) Name() string {
+	return "scm"
+}
 
-		// if canBuy.Load() {
-		go p.ScmBackRun(swapInfo, tx.Slot)
-		// }
+func (*scmStrategy) Filters() *pb.SubscribeRequest {
+	return txSubscription("2x4Mp5dLefbx8V684sQDxNxUwgp3bqoibAgacLqs5z19")
+}
 
-	})
+func (*scmStrategy) OnSwap(p *PumpFunMonitor, tx *pb.SubscribeUpdateTransaction, swapInfo *solanaswapgo.SwapInfo) *TokenSwap {
+	logx.Infof("[%s]:收到scm交易{%s}", swapInfo.TokenOutMint, solana.SignatureFromBytes(tx.Transaction.Signature).String())
 
+	go p.ScmBackRun(swapInfo, tx.Slot)
+	return nil
 }
 
 func (p *PumpFunMonitor) ScmBackRun(swapInfo *solanaswapgo.SwapInfo, slot uint64) {
@@ -77,7 +44,15 @@ func (p *PumpFunMonitor) ScmBackRun(swapInfo *solanaswapgo.SwapInfo, slot uint64
 	}
 	//别人买多少，就卖多少
 	logx.Infof("[%s]:开始回本", swapInfo.TokenOutMint)
+	// scm 的库存在可用于 scm 的第一个钱包里
+	wallet, err := p.wallets.First("scm")
+	if err != nil {
+		logx.Errorf("[%s]:%v", swapInfo.TokenOutMint, err)
+		return
+	}
 	ts := NewTokenJupiterSwap(swapInfo.TokenOutMint.String())
+	ts.Mode = "scm"
+	ts.Wallet = wallet
 	for i := 0; i < 10; i++ {
 		_, err := p.sellWithRaydium(ts, big.NewInt(int64(swapInfo.TokenOutAmount)), float32(100))
 		if err != nil {
@@ -89,10 +64,19 @@ func (p *PumpFunMonitor) ScmBackRun(swapInfo *solanaswapgo.SwapInfo, slot uint64
 }
 
 func (p *PumpFunMonitor) sellWithRaydium(ts *TokenSwap, amount *big.Int, slippage float32) (*rpc.GetTransactionResult, error) {
+	if p.paper != nil {
+		return nil, ErrPaperBroadcast
+	}
 
-	wallet, _ := solana.WalletFromPrivateKeyBase58("2rGpKU7MXBWsdrugTyTcRepa25zmEkeYYj4Bjqv68rRXkzgAvh2rcVQfSrAz2G8Bdcv3Ve3nfCftLZPErCMaH65W")
+	inputMint, err := solana.PublicKeyFromBase58(ts.Token.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	// 输入、输出 token 账户都是交易钱包的 ATA
+	inputAccount, _, _ := solana.FindAssociatedTokenAddress(ts.Wallet.PublicKey(), inputMint)
+	outputAccount, _, _ := solana.FindAssociatedTokenAddress(ts.Wallet.PublicKey(), solana.WrappedSol)
 
-	if err := client.RaySwapByApi(p.httpClient, wallet, "7YpUFdHWmjbLF559hppQcixmx5napb3gWpg9LCgQ9oyd", "So11111111111111111111111111111111111111112", "2qCcBUbmChGyvAjT9QRamZRgpWp943YXYXTix7wdJ3xB", "Dcs6Nf2MMPsMEkbBv6Vy6f76fHYerLMFhAaVXJa9gX4Q", amount.Uint64(), uint64(slippage)); err != nil {
+	if err := client.RaySwapByApi(p.httpClient, ts.Wallet, inputMint.String(), solana.WrappedSol.String(), inputAccount.String(), outputAccount.String(), amount.Uint64(), uint64(slippage)); err != nil {
 		logx.Errorf("[%s]:Raydium swap error: %v", ts.Token.TokenAddress, err)
 		return nil, err
 	}
@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"solana-bot/internal/dex/meteora"
+	"solana-bot/internal/dex/pump"
+	"solana-bot/internal/dex/raydium"
+	"solana-bot/internal/fee"
+	"solana-bot/internal/stream"
+
+	"github.com/gagliardetto/solana-go"
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SubPoolState 订阅持仓对应的池子账户，每次链上写入都用真实布局解析并刷新 TokenInfo，
+// 不再只依赖解析到的成交来推算储备
+func (t *TokenSwap) SubPoolState() {
+	accounts := t.poolAccounts()
+	if len(accounts) == 0 {
+		return
+	}
+	logx.Infof("[%s]:监听池子账户 %v", t.Token.TokenAddress, accounts)
+	// 提前跟踪池子账户的费用，买卖时才有足够的本地样本
+	fee.Track(accounts...)
+
+	subscribe := make(chan interface{})
+	t.poolSub.Store(t.hub.Subscribe(t.Ctx, poolSubscription(accounts), subscribe))
+
+	for msg := range subscribe {
+		got := msg.(*stream.StreamMessage).Data.(*pb.SubscribeUpdate)
+		acc := got.GetAccount().GetAccount()
+		if acc == nil {
+			continue
+		}
+		if t.applyPoolAccount(solana.PublicKeyFromBytes(acc.Pubkey), acc.Data) {
+			t.poolLive.Store(true)
+		}
+	}
+}
+
+func poolSubscription(accounts []string) *pb.SubscribeRequest {
+	return &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"pool_sub": {Account: accounts},
+		},
+	}
+}
+
+// resubscribePool 池子类型变化（如迁移）后切换订阅的账户
+func (t *TokenSwap) resubscribePool() {
+	t.poolLive.Store(false)
+	sub := t.poolSub.Load()
+	if sub == nil {
+		go t.SubPoolState()
+		return
+	}
+	accounts := t.poolAccounts()
+	logx.Infof("[%s]:池子变更，切换监听账户 %v", t.Token.TokenAddress, accounts)
+	fee.Track(accounts...)
+	sub.Update(poolSubscription(accounts))
+}
+
+// poolAccounts 当前池子类型需要监听的账户
+func (t *TokenSwap) poolAccounts() []string {
+	poolData := t.Token.PoolData.Load()
+	if t.hub == nil || poolData == nil {
+		return nil
+	}
+	switch pool := poolData.Data.(type) {
+	case *solanaswapgo.PumpFunPool:
+		return []string{pool.BondingCurve.String()}
+	case *solanaswapgo.PumpAmmPool:
+		return []string{pool.PoolBaseTokenAccount.String(), pool.PoolQuoteTokenAccount.String()}
+	case *solanaswapgo.MeteoraDbcPool:
+		return []string{pool.Pool.String()}
+	case *solanaswapgo.RaydiumLaunchpadPool:
+		return []string{pool.PoolState.String()}
+	}
+	return nil
+}
+
+// applyPoolAccount 解析池子账户并更新储备和价格，返回是否成功应用
+func (t *TokenSwap) applyPoolAccount(pubkey solana.PublicKey, data []byte) bool {
+	poolData := t.Token.PoolData.Load()
+	if poolData == nil {
+		return false
+	}
+	switch pool := poolData.Data.(type) {
+	case *solanaswapgo.PumpFunPool:
+		if !pubkey.Equals(pool.BondingCurve) {
+			return false
+		}
+		curve, err := pump.DecodeBondingCurve(data)
+		if err != nil {
+			logx.Errorf("[%s]:解析 bonding curve 失败: %v", t.Token.TokenAddress, err)
+			return false
+		}
+		t.UpdateBondingCurve(curve.VirtualSOLReserves, curve.VirtualTokenReserves, curve.RealSOLReserves, curve.RealTokenReserves)
+		t.setMigrated(curve.Complete)
+
+	case *solanaswapgo.PumpAmmPool:
+		amount, ok := tokenAccountAmount(data)
+		if !ok {
+			return false
+		}
+		base, quote := t.Token.PoolTokenBalance.Load(), t.Token.PoolSolBalance.Load()
+		switch {
+		case pubkey.Equals(pool.PoolBaseTokenAccount):
+			base = amount
+		case pubkey.Equals(pool.PoolQuoteTokenAccount):
+			quote = amount
+		default:
+			return false
+		}
+		t.UpdateAmmPool(base, quote)
+
+	case *solanaswapgo.MeteoraDbcPool:
+		if !pubkey.Equals(pool.Pool) {
+			return false
+		}
+		state, err := meteora.DecodeVirtualPool(data)
+		if err != nil {
+			logx.Errorf("[%s]:解析 DBC 池子失败: %v", t.Token.TokenAddress, err)
+			return false
+		}
+		t.Token.PoolTokenBalance.Store(state.BaseReserve)
+		t.Token.PoolSolBalance.Store(state.QuoteReserve)
+		t.setSqrtPrice(state.SqrtPrice)
+		t.setMigrated(state.IsMigrated)
+
+	case *solanaswapgo.RaydiumLaunchpadPool:
+		if !pubkey.Equals(pool.PoolState) {
+			return false
+		}
+		state, err := raydium.DecodeLaunchpadPool(data)
+		if err != nil {
+			logx.Errorf("[%s]:解析 LaunchLab 池子失败: %v", t.Token.TokenAddress, err)
+			return false
+		}
+		t.UpdateAmmPool(state.Reserves())
+		t.setMigrated(state.Migrated())
+
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *TokenSwap) setMigrated(migrated bool) {
+	if migrated && !t.Token.Migrated.Swap(true) {
+		logx.Infof("[%s]:⚠️ 池子已完成/迁移，等待新池子的成交切换", t.Token.TokenAddress)
+	}
+}
+
+// tokenAccountAmount 读取 SPL Token / Token-2022 账户的 amount
+func tokenAccountAmount(data []byte) (uint64, bool) {
+	if len(data) < 72 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(data[64:72]), true
+}
+
+// poolPrice 按储备计算每个整 token（6 位精度）值多少 SOL
+func poolPrice(tokenReserves, solReserves uint64) *big.Float {
+	if tokenReserves == 0 {
+		return new(big.Float)
+	}
+	price := new(big.Float).Quo(new(big.Float).SetUint64(solReserves), new(big.Float).SetUint64(solana.LAMPORTS_PER_SOL))
+	price.Quo(price, new(big.Float).SetUint64(tokenReserves))
+	return price.Mul(price, big.NewFloat(1e6))
+}
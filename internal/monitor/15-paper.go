@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"errors"
+	"math/big"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/rpcs"
+	"solana-bot/internal/shot"
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ErrPaperBroadcast paper 模式下拒绝所有会真正发出交易的路径
+var ErrPaperBroadcast = errors.New("paper mode: refusing to broadcast")
+
+// paperSwap 把一笔买卖交给 paper 通道：不构建链上指令，成交时按本地池子状态报价，
+// 报价低于发送时按 slippage 算出的下限时和链上一样失败；
+// 结果和真实交易一样经过确认、竞价记录，再交给 BuyDone / SellDone
+func (p *PumpFunMonitor) paperSwap(ts *TokenSwap, side string, amountIn uint64, slippage float32) (*rpc.GetTransactionResult, error) {
+	swapType := ts.SwapType.Load()
+	buy := side == rpcs.Buy
+	minAmountOut := shot.ApplySlippage(new(big.Int).SetUint64(paperQuote(ts, swapType, buy, amountIn)), slippage)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), global.GetBlockHash())
+	txBuilder.SetTrade(&global.Trade{
+		Mint:         solana.MustPublicKeyFromBase58(ts.Token.TokenAddress),
+		Program:      poolProgram(swapType),
+		Buy:          buy,
+		AmountIn:     amountIn,
+		MinAmountOut: minAmountOut.Uint64(),
+		Quote: func(amountIn uint64) uint64 {
+			return paperQuote(ts, swapType, buy, amountIn)
+		},
+	})
+	logx.Infof("[%s]: paper %s %d via %s", ts.Token.TokenAddress, side, amountIn, poolTypeName(swapType))
+	return p.SendAndWait2(ts, side, 0, txBuilder)
+}
+
+// poolProgram 各池子类型交易调用的程序，paper 成交的错误码按它解析
+func poolProgram(swapType int32) solana.PublicKey {
+	switch swapType {
+	case PumpFunType:
+		return txerr.PumpProgramID
+	case PumpAmmType:
+		return txerr.PumpAmmProgramID
+	case MeteoraDbcType:
+		return txerr.DbcProgramID
+	case RaydiumLaunchpadType:
+		return txerr.LaunchLabProgramID
+	}
+	return solana.PublicKey{}
+}
+
+// paperQuote 用各池子的曲线在当前储备上估算成交数量，和 adapter 报价用的公式一致；
+// DBC 本地只有价格，按当前价格成交，不计价格冲击
+func paperQuote(ts *TokenSwap, swapType int32, buy bool, amountIn uint64) uint64 {
+	in := new(big.Int).SetUint64(amountIn)
+	solReserves := new(big.Int).SetUint64(ts.Token.PoolSolBalance.Load())
+	tokenReserves := new(big.Int).SetUint64(ts.Token.PoolTokenBalance.Load())
+
+	var out *big.Int
+	switch swapType {
+	case PumpFunType:
+		if buy {
+			fee := new(big.Int).Div(new(big.Int).Mul(in, big.NewInt(int64(shot.FeeBasisPoints))), global.Big10000)
+			out = shot.QuoteBuyByCurve(new(big.Int).Sub(in, fee), solReserves, tokenReserves)
+		} else {
+			out = shot.QuoteSellByCurve(in, solReserves, tokenReserves)
+		}
+	case PumpAmmType, RaydiumLaunchpadType:
+		if buy {
+			out = shot.EstimateSwapOut(in, solReserves, tokenReserves, shot.FeeBasisPoints)
+		} else {
+			out = shot.EstimateSwapOut(in, tokenReserves, solReserves, shot.FeeBasisPoints)
+		}
+	case MeteoraDbcType:
+		price := ts.Token.TokenPrice.Load()
+		if price == nil || price.Sign() <= 0 {
+			return 0
+		}
+		// price 为每个整 token 值多少 SOL
+		net := new(big.Float).SetUint64(amountIn * (10000 - shot.FeeBasisPoints) / 10000)
+		if buy {
+			net.Quo(net, price).Quo(net, big.NewFloat(1e3))
+		} else {
+			net.Mul(net, price).Mul(net, big.NewFloat(1e3))
+		}
+		out, _ = net.Int(nil)
+	default:
+		return 0
+	}
+	if out == nil || out.Sign() <= 0 {
+		return 0
+	}
+	return out.Uint64()
+}
@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"solana-bot/internal/stream"
+
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Order 策略给出的买入单
+type Order struct {
+	Amount   *big.Float    // 买入金额，和 buyToken 的 maxAmountIn 同单位
+	Slippage float32       // 百分比
+	Delay    time.Duration // 下单前等待的时间
+}
+
+// Strategy 一种交易策略：声明需要的流过滤器，从流里产生开仓信号，决定买入数量和开仓后的退出方式。
+// 策略按名字注册，通过 bot.strategies 或命令行启用
+type Strategy interface {
+	// Name 策略名，也是 ts.Mode、钱包 strategies 和通道 routes 里使用的模式名
+	Name() string
+	// Filters 需要订阅的交易、账户过滤器
+	Filters() *pb.SubscribeRequest
+	// OnSwap 收到过滤器匹配的 swap 交易，返回非 nil 时按该仓位开仓
+	OnSwap(p *PumpFunMonitor, tx *pb.SubscribeUpdateTransaction, swapInfo *solanaswapgo.SwapInfo) *TokenSwap
+	// OnAccount 收到过滤器匹配的账户更新，返回非 nil 时按该仓位开仓
+	OnAccount(p *PumpFunMonitor, account *pb.SubscribeUpdateAccount) *TokenSwap
+	// Entry 开仓前决定买入数量，返回错误时放弃
+	Entry(p *PumpFunMonitor, ts *TokenSwap) (*Order, error)
+	// Exit 买入成功后设置该仓位的退出方式
+	Exit(p *PumpFunMonitor, ts *TokenSwap, order *Order, resp *rpc.GetTransactionResult)
+}
+
+// BaseStrategy 嵌入后只需实现用到的回调
+type BaseStrategy struct{}
+
+func (BaseStrategy) OnSwap(*PumpFunMonitor, *pb.SubscribeUpdateTransaction, *solanaswapgo.SwapInfo) *TokenSwap {
+	return nil
+}
+
+func (BaseStrategy) OnAccount(*PumpFunMonitor, *pb.SubscribeUpdateAccount) *TokenSwap {
+	return nil
+}
+
+func (BaseStrategy) Entry(*PumpFunMonitor, *TokenSwap) (*Order, error) {
+	return nil, fmt.Errorf("策略不开仓")
+}
+
+func (BaseStrategy) Exit(*PumpFunMonitor, *TokenSwap, *Order, *rpc.GetTransactionResult) {}
+
+var (
+	strategies   = make(map[string]Strategy)
+	strategiesMu sync.RWMutex
+
+	EnabledStrategies []string // 启用的策略名
+)
+
+// RegisterStrategy 注册策略，一般在策略文件的 init 中调用；重名时 panic
+func RegisterStrategy(s Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if _, ok := strategies[s.Name()]; ok {
+		panic(fmt.Sprintf("strategy %s already registered", s.Name()))
+	}
+	strategies[s.Name()] = s
+}
+
+// Strategies 已注册的策略名
+func Strategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetStrategies 设置启用的策略，重复的名字只保留一个
+func SetStrategies(names []string) {
+	EnabledStrategies = nil
+	for _, name := range names {
+		if name != "" && !slices.Contains(EnabledStrategies, name) {
+			EnabledStrategies = append(EnabledStrategies, name)
+		}
+	}
+}
+
+// enabledStrategies 按 EnabledStrategies 取出已注册的策略，有未注册的名字时报错
+func enabledStrategies() ([]Strategy, error) {
+	var out []Strategy
+	for _, name := range EnabledStrategies {
+		strategiesMu.RLock()
+		s, ok := strategies[name]
+		strategiesMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("未注册的策略 %s，可用: %v", name, Strategies())
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// txSubscription 只订阅成功的非投票交易，accounts 为需要包含的账户
+func txSubscription(accounts ...string) *pb.SubscribeRequest {
+	commitment := pb.CommitmentLevel_PROCESSED
+	failed := false
+	vote := false
+	return &pb.SubscribeRequest{
+		Commitment: &commitment,
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"transactions_sub": {
+				Failed:         &failed,
+				Vote:           &vote,
+				AccountInclude: accounts,
+			},
+		},
+	}
+}
+
+// runStrategy 按策略的过滤器订阅，把流里的交易、账户更新交给策略，产生的仓位按 Entry、Exit 开仓
+func (p *PumpFunMonitor) runStrategy(s Strategy) {
+	logx.Infof("[%s]:启动策略", s.Name())
+	subscribe := make(chan interface{})
+	p.hub.Subscribe(p.ctx, s.Filters(), subscribe)
+
+	p.runWithCtx(context.Background(), subscribe, func(msg interface{}) {
+		got := msg.(*stream.StreamMessage).Data.(*pb.SubscribeUpdate)
+
+		var ts *TokenSwap
+		if tx := got.GetTransaction(); tx != nil {
+			swapInfo, err := ParseSwapTransaction(tx.Transaction.Transaction, tx.Transaction.Meta)
+			if err != nil || swapInfo == nil {
+				return
+			}
+			ts = s.OnSwap(p, tx, swapInfo)
+		} else if account := got.GetAccount(); account != nil {
+			ts = s.OnAccount(p, account)
+		}
+		if ts == nil {
+			return
+		}
+		ts.Mode = s.Name()
+		go p.watchRetract(ts)
+		go p.enter(s, ts)
+	})
+}
+
+// enter 按策略的买入单开仓，成功后交给策略的退出方式
+func (p *PumpFunMonitor) enter(s Strategy, ts *TokenSwap) {
+	tokenAddress := ts.Token.TokenAddress
+	logx.Infof("[%s]:BackRun %s 交易 ", tokenAddress, s.Name())
+
+	order, err := s.Entry(p, ts)
+	if err != nil {
+		logx.Errorf("[%s]:%s 不开仓: %v", tokenAddress, s.Name(), err)
+		ts.Cancel()
+		ts.Wallet.Close(tokenAddress, 0)
+		return
+	}
+	if order.Delay > 0 {
+		time.Sleep(order.Delay)
+	}
+
+	if err := p.BuyBefore(ts); err != nil {
+		logx.Errorf("[%s]:买入校验失败: %v", tokenAddress, err)
+		p.BuyError(ts)
+		return
+	}
+
+	resp, err := p.buyToken(ts, order.Amount, order.Slippage)
+	if err != nil {
+		logx.Errorf("[%s] 买入失败,err:%v", tokenAddress, err)
+		p.BuyError(ts)
+		return
+	}
+
+	p.BuyDone(ts, resp)
+	s.Exit(p, ts, order, resp)
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/positions"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var (
+	// positionStore 整个进程共用；配置变更重建 monitor 时不关闭，旧 monitor 上未平的仓位仍然写得进去。
+	// paper 模式不落盘，为 nil
+	positionStore   *positions.Store
+	positionStoreMu sync.Mutex
+)
+
+// openPositionStore 第一次调用时打开仓位库，之后直接返回
+func openPositionStore(path string) error {
+	positionStoreMu.Lock()
+	defer positionStoreMu.Unlock()
+	if positionStore != nil {
+		return nil
+	}
+	store, err := positions.Open(path)
+	if err != nil {
+		return err
+	}
+	positionStore = store
+	return nil
+}
+
+// savePosition 买入落地后写入仓位
+func savePosition(ts *TokenSwap, resp *rpc.GetTransactionResult) {
+	if positionStore == nil {
+		return
+	}
+	wallet := ts.Wallet.PublicKey().String()
+	price, _ := ts.MySwap.BuyPrice.Load().Float64()
+	amount := global.GetTokenChange(resp, wallet, ts.Token.TokenAddress)
+	costSol, _ := global.GetBalacneChange(resp)
+
+	pos := &positions.Position{
+		Wallet:       wallet,
+		Mint:         ts.Token.TokenAddress,
+		Strategy:     ts.Mode,
+		PoolType:     poolTypeName(ts.SwapType.Load()),
+		PoolAccounts: ts.poolAccounts(),
+		EntrySlot:    resp.Slot,
+		EntryPrice:   price,
+		EntryAmount:  uint64(max(amount, 0)),
+		EntryCost:    uint64(max(-costSol, 0)),
+		Remaining:    uint64(max(amount, 0)),
+		OpenedAt:     time.Now(),
+	}
+	if poolData := ts.Token.PoolData.Load(); poolData != nil {
+		pos.Pool, _ = json.Marshal(poolData.Data)
+	}
+	if err := positionStore.Put(pos); err != nil {
+		logx.Errorf("[%s]:写入仓位失败: %v", ts.Token.TokenAddress, err)
+	}
+}
+
+// recordSell 卖出落地后记录触发的规则，卖完时平仓
+func recordSell(ts *TokenSwap, resp *rpc.GetTransactionResult, rule string) {
+	if positionStore == nil || resp == nil {
+		return
+	}
+	wallet := ts.Wallet.PublicKey().String()
+	costSol, _ := global.GetBalacneChange(resp)
+	sell := positions.Sell{
+		Slot:     resp.Slot,
+		Amount:   uint64(max(-global.GetTokenChange(resp, wallet, ts.Token.TokenAddress), 0)),
+		Lamports: int64(costSol),
+		Rule:     rule,
+		Time:     time.Now(),
+	}
+	if _, err := positionStore.RecordSell(wallet, ts.Token.TokenAddress, sell); err != nil && !errors.Is(err, positions.ErrNotFound) {
+		logx.Errorf("[%s]:记录卖出失败: %v", ts.Token.TokenAddress, err)
+	}
+}
+
+// finishPosition 持仓已经清零，平仓
+func finishPosition(ts *TokenSwap) {
+	if positionStore == nil || ts.Wallet == nil {
+		return
+	}
+	if err := positionStore.Finish(ts.Wallet.PublicKey().String(), ts.Token.TokenAddress); err != nil && !errors.Is(err, positions.ErrNotFound) {
+		logx.Errorf("[%s]:平仓失败: %v", ts.Token.TokenAddress, err)
+	}
+}
+
+// updateExitPlan 记录仓位的退出方式，重启后按它恢复
+func updateExitPlan(ts *TokenSwap, fn func(*positions.ExitPlan)) {
+	if positionStore == nil || ts.Wallet == nil {
+		return
+	}
+	err := positionStore.Update(ts.Wallet.PublicKey().String(), ts.Token.TokenAddress, func(pos *positions.Position) error {
+		fn(&pos.Exit)
+		return nil
+	})
+	if err != nil && !errors.Is(err, positions.ErrNotFound) {
+		logx.Errorf("[%s]:记录退出方式失败: %v", ts.Token.TokenAddress, err)
+	}
+}
@@ -0,0 +1,335 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/dex/pump"
+	"solana-bot/internal/positions"
+	"solana-bot/internal/wallets"
+
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 启动核对后对每个持仓的处理
+const (
+	reconcileResume    = "resume"    // 按仓位库里的退出方式继续
+	reconcileLiquidate = "liquidate" // 直接清仓
+	reconcileIgnore    = "ignore"    // 只记日志
+	reconcileFinish    = "finish"    // 钱包里已经没有这个 token，平仓
+)
+
+// reconcileMode 核对时接管的、仓位库里没有的持仓使用的通道模式
+const reconcileMode = "reconcile"
+
+// 配置变更会重建 monitor 再 Start，核对只在进程启动时做一次
+var reconcileOnce sync.Once
+
+// tokenHolding 钱包里一个余额不为 0 的 token 账户
+type tokenHolding struct {
+	Wallet  string
+	Mint    string
+	Account string
+	Amount  uint64
+}
+
+// reconcileStep 一个持仓或仓位的处理方式；finish 时 Holding 为零值，仓位库里没有时 Position 为 nil
+type reconcileStep struct {
+	Action   string
+	Holding  tokenHolding
+	Position *positions.Position
+}
+
+// planReconcile 把一个钱包的持仓和仓位库里的仓位对上，决定每个持仓怎么处理
+func planReconcile(holdings []tokenHolding, open []*positions.Position, conf config.ReconcileConf) []reconcileStep {
+	byMint := make(map[string]*positions.Position, len(open))
+	for _, pos := range open {
+		byMint[pos.Mint] = pos
+	}
+
+	var steps []reconcileStep
+	for _, h := range holdings {
+		pos, ok := byMint[h.Mint]
+		if !ok {
+			// 库里没有的持仓只有明确配置 liquidate 时才清仓
+			action := reconcileIgnore
+			if conf.Unknown == reconcileLiquidate {
+				action = reconcileLiquidate
+			}
+			steps = append(steps, reconcileStep{Action: action, Holding: h})
+			continue
+		}
+		delete(byMint, h.Mint)
+
+		action := reconcileResume
+		// 没有记录退出方式的仓位恢复后不会卖出，只能清仓
+		if conf.Known == reconcileLiquidate || (pos.Exit.HoldUntil.IsZero() && len(pos.Exit.Limits) == 0 && pos.Exit.Rules == nil) {
+			action = reconcileLiquidate
+		}
+		steps = append(steps, reconcileStep{Action: action, Holding: h, Position: pos})
+	}
+
+	for _, pos := range open {
+		if _, ok := byMint[pos.Mint]; ok {
+			steps = append(steps, reconcileStep{Action: reconcileFinish, Position: pos})
+		}
+	}
+	return steps
+}
+
+// reconcile 启动时核对各钱包的 SPL Token、Token-2022 持仓和仓位库：
+// 库里有的按配置恢复退出方式或清仓，库里没有的找到池子后清仓，库里有但钱包里已经没有的平仓
+func (p *PumpFunMonitor) reconcile() {
+	// paper 模式没有仓位库，钱包里的真实持仓也不归它管
+	if positionStore == nil {
+		return
+	}
+	reconcileOnce.Do(func() {
+		open, err := positionStore.List()
+		if err != nil {
+			logx.Errorf("[reconcile] 读取仓位库失败: %v", err)
+			return
+		}
+		byWallet := make(map[string][]*positions.Position)
+		for _, pos := range open {
+			byWallet[pos.Wallet] = append(byWallet[pos.Wallet], pos)
+		}
+
+		for _, w := range p.wallets.All() {
+			wallet := w.PublicKey().String()
+			holdings, err := p.listHoldings(w.PublicKey())
+			if err != nil {
+				// 查不到持仓时不能据此平仓，留到下次启动
+				logx.Errorf("[reconcile] %s 查询持仓失败: %v", w, err)
+				delete(byWallet, wallet)
+				continue
+			}
+			for _, step := range planReconcile(holdings, byWallet[wallet], config.C.Bot.Reconcile) {
+				p.applyReconcile(w, step)
+			}
+			delete(byWallet, wallet)
+		}
+		for wallet, left := range byWallet {
+			logx.Errorf("[reconcile] 钱包 %s 不在配置里，%d 个仓位未处理", wallet, len(left))
+		}
+	})
+}
+
+// listHoldings 钱包在 Token、Token-2022 下余额不为 0 的 token 账户，不含 WSOL
+func (p *PumpFunMonitor) listHoldings(owner solana.PublicKey) ([]tokenHolding, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, 10*time.Second)
+	defer cancel()
+
+	var holdings []tokenHolding
+	for _, program := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		res, err := p.httpClient.GetTokenAccountsByOwner(ctx, owner,
+			&rpc.GetTokenAccountsConfig{ProgramId: &program},
+			&rpc.GetTokenAccountsOpts{Commitment: rpc.CommitmentConfirmed, Encoding: solana.EncodingBase64})
+		if err != nil {
+			return nil, err
+		}
+		for _, acc := range res.Value {
+			if acc == nil || acc.Account.Data == nil {
+				continue
+			}
+			data := acc.Account.Data.GetBinary()
+			amount, ok := tokenAccountAmount(data)
+			if !ok || amount == 0 {
+				continue
+			}
+			mint := solana.PublicKeyFromBytes(data[:32])
+			if mint.Equals(solana.WrappedSol) {
+				continue
+			}
+			holdings = append(holdings, tokenHolding{
+				Wallet:  owner.String(),
+				Mint:    mint.String(),
+				Account: acc.Pubkey.String(),
+				Amount:  amount,
+			})
+		}
+	}
+	return holdings, nil
+}
+
+func (p *PumpFunMonitor) applyReconcile(w *wallets.Wallet, step reconcileStep) {
+	h, pos := step.Holding, step.Position
+	switch step.Action {
+	case reconcileFinish:
+		logx.Infof("[%s]:钱包 %s 已经没有持仓，平仓", pos.Mint, w)
+		if err := positionStore.Finish(pos.Wallet, pos.Mint); err != nil && !errors.Is(err, positions.ErrNotFound) {
+			logx.Errorf("[%s]:平仓失败: %v", pos.Mint, err)
+		}
+
+	case reconcileIgnore:
+		logx.Infof("[%s]:钱包 %s 持有 %d，仓位库里没有，忽略", h.Mint, w, h.Amount)
+
+	case reconcileResume:
+		ts, err := p.restoreSwap(w, h, pos)
+		if err != nil {
+			logx.Errorf("[%s]:恢复仓位失败，改为清仓: %v", h.Mint, err)
+			p.liquidate(w, h, pos)
+			return
+		}
+		p.resumeExit(ts, pos)
+
+	case reconcileLiquidate:
+		p.liquidate(w, h, pos)
+	}
+}
+
+// restoreSwap 按仓位库里的池子重建 TokenSwap，数量以钱包里的实际余额为准
+func (p *PumpFunMonitor) restoreSwap(w *wallets.Wallet, h tokenHolding, pos *positions.Position) (*TokenSwap, error) {
+	poolData, err := decodePoolData(pos.PoolType, pos.Pool)
+	if err != nil {
+		return nil, err
+	}
+	ts := p.reconcileSwap(w, h, pos.Strategy, poolData)
+	ts.MySwap.BuyPrice.Store(big.NewFloat(pos.EntryPrice))
+	ts.MySwap.BuyAmount.Store(new(big.Int).SetUint64(pos.EntryAmount))
+	ts.MySwap.BuyBalanceChange.Store(big.NewFloat(-float64(pos.EntryCost)))
+	for _, s := range pos.Sells {
+		ts.MySwap.AppendSellBalanceChange(big.NewFloat(float64(s.Lamports)))
+	}
+	w.Resume(h.Mint, pos.EntryCost)
+	trackRisk(ts, pos.EntryCost)
+
+	if pos.Remaining != h.Amount {
+		err := positionStore.Update(pos.Wallet, pos.Mint, func(stored *positions.Position) error {
+			stored.Remaining = h.Amount
+			return nil
+		})
+		if err != nil {
+			logx.Errorf("[%s]:更新剩余数量失败: %v", h.Mint, err)
+		}
+	}
+	return ts, nil
+}
+
+// resumeExit 按记录的退出方式继续：退出规则从上次执行到的位置接着算，持有到期的立即分批卖出，止盈止损单重新挂上
+func (p *PumpFunMonitor) resumeExit(ts *TokenSwap, pos *positions.Position) {
+	logx.Infof("[%s]:恢复仓位，剩余 %d，持有到 %v，限价单 %d 个", pos.Mint, ts.GetRemainingAmount().Uint64(), pos.Exit.HoldUntil.Format(time.DateTime), len(pos.Exit.Limits))
+	if rules := pos.Exit.Rules; rules != nil {
+		// 已经触发清仓但没卖完
+		if rules.Done {
+			go p.ExecuteSell(ts, ts.GetRemainingAmount(), reconcileMode)
+			return
+		}
+		p.armExit(ts, rules)
+	}
+	if !pos.Exit.HoldUntil.IsZero() {
+		p.StartHoldTimer(ts, max(time.Until(pos.Exit.HoldUntil), time.Millisecond))
+	}
+	for _, l := range pos.Exit.Limits {
+		p.placeLimit(ts, l.Price, new(big.Int).SetUint64(l.Amount), l.Price > pos.EntryPrice)
+	}
+	go p.ListenSell(ts)
+}
+
+// liquidate 清仓；仓位库里没有池子时先在链上找 pump 曲线和 PumpAMM 池子，都找不到走 Jupiter
+func (p *PumpFunMonitor) liquidate(w *wallets.Wallet, h tokenHolding, pos *positions.Position) {
+	mode := reconcileMode
+	var poolData *solanaswapgo.PoolData
+	var err error
+	if pos != nil {
+		mode = pos.Strategy
+		poolData, err = decodePoolData(pos.PoolType, pos.Pool)
+	}
+	if pos == nil || err != nil || (poolData == nil && pos.PoolType != "Jupiter") {
+		poolData = p.lookupPool(solana.MustPublicKeyFromBase58(h.Mint))
+	}
+
+	ts := p.reconcileSwap(w, h, mode, poolData)
+	if pos != nil {
+		ts.MySwap.BuyBalanceChange.Store(big.NewFloat(-float64(pos.EntryCost)))
+		w.Resume(h.Mint, pos.EntryCost)
+		trackRisk(ts, pos.EntryCost)
+	} else {
+		ts.MySwap.BuyBalanceChange.Store(new(big.Float))
+		trackRisk(ts, 0)
+	}
+	logx.Infof("[%s]:清仓钱包 %s 的 %d，池子 %s", h.Mint, w, h.Amount, poolTypeName(ts.SwapType.Load()))
+	go func() {
+		if err := p.ExecuteSell(ts, ts.GetRemainingAmount(), reconcileMode); err != nil {
+			logx.Errorf("[%s]:清仓失败: %v", h.Mint, err)
+		}
+	}()
+}
+
+// reconcileSwap 为钱包里已有的持仓建一个 TokenSwap，poolData 为 nil 时走 Jupiter
+func (p *PumpFunMonitor) reconcileSwap(w *wallets.Wallet, h tokenHolding, mode string, poolData *solanaswapgo.PoolData) *TokenSwap {
+	var ts *TokenSwap
+	if poolData == nil {
+		ts = NewTokenJupiterSwap(h.Mint)
+		ts.hub = p.hub
+		ts.MySwap.AtaAddress.Store(h.Account)
+	} else {
+		ts = NewTokenSwap(p.hub, false, "", h.Mint, nil, h.Account, poolData)
+	}
+	ts.Wallet = w
+	ts.Mode = mode
+	if tokenReserves := ts.Token.PoolTokenBalance.Load(); tokenReserves > 0 {
+		ts.Token.TokenPrice.Store(poolPrice(tokenReserves, ts.Token.PoolSolBalance.Load()))
+	}
+	ts.MySwap.RemainingAmount.Store(new(big.Int).SetUint64(h.Amount))
+	// 和买入一样占一个名额，SellDone 卖完时释放
+	buyCount.Increment()
+	BuyCache.Set(h.Mint, true)
+	return ts
+}
+
+// lookupPool 在链上找 mint 的 pump 曲线，曲线已完成或不存在时找迁移后的 PumpAMM 池子
+func (p *PumpFunMonitor) lookupPool(mint solana.PublicKey) *solanaswapgo.PoolData {
+	ctx, cancel := context.WithTimeout(p.ctx, 10*time.Second)
+	defer cancel()
+
+	curve, complete, err := pump.FindPumpFunPool(ctx, p.httpClient, mint)
+	if err == nil && !complete {
+		return &solanaswapgo.PoolData{PoolType: "PumpFun", Data: curve}
+	}
+	if err != nil && !errors.Is(err, pump.ErrPoolNotFound) {
+		logx.Errorf("[%s]:查询 pump 曲线失败: %v", mint, err)
+	}
+	amm, err := pump.FindPumpAmmPool(ctx, p.httpClient, mint)
+	if err == nil {
+		return &solanaswapgo.PoolData{PoolType: "PumpAmm", Data: amm}
+	}
+	if !errors.Is(err, pump.ErrPoolNotFound) {
+		logx.Errorf("[%s]:查询 PumpAMM 池子失败: %v", mint, err)
+	}
+	return nil
+}
+
+// decodePoolData 把仓位库里的池子还原成 solanaswapgo 的结构，Jupiter 或没有记录时返回 nil
+func decodePoolData(poolType string, raw json.RawMessage) (*solanaswapgo.PoolData, error) {
+	if len(raw) == 0 || poolType == "Jupiter" {
+		return nil, nil
+	}
+	var data any
+	switch poolType {
+	case "PumpFun":
+		data = &solanaswapgo.PumpFunPool{}
+	case "PumpAmm":
+		data = &solanaswapgo.PumpAmmPool{}
+	case "MeteoraDbc":
+		data = &solanaswapgo.MeteoraDbcPool{}
+	case "RaydiumLaunchpad":
+		data = &solanaswapgo.RaydiumLaunchpadPool{}
+	default:
+		return nil, fmt.Errorf("unknown pool type %q", poolType)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, err
+	}
+	return &solanaswapgo.PoolData{PoolType: poolType, Data: data}, nil
+}
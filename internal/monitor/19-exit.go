@@ -0,0 +1,266 @@
+package monitor
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"solana-bot/internal/exitplan"
+	"solana-bot/internal/orders"
+	"solana-bot/internal/positions"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var errRuleNotArmed = errors.New("exit rule already fired or disarmed")
+
+// exitOrders 退出规则挂在 Orders 里的条件单，订单号 -> 所属仓位的规则；撤单时据此撤销规则
+var exitOrders sync.Map
+
+// exitRules 一个仓位的退出规则和执行状态。止盈、止损挂成 Orders 里的条件单，由价格撮合；
+// 移动止损、跟随钱包卖出、持有到期在价格更新等时集中计算
+type exitRules struct {
+	mu     sync.Mutex
+	ts     *TokenSwap
+	state  *exitplan.State
+	sell   func(exitplan.Signal) error
+	orders map[string]uint64 // 规则名 -> 挂着的订单号
+}
+
+// newExitState 按 ts.Mode 对应策略配置的退出规则，从买入价、买入数量开始
+func newExitState(ts *TokenSwap) *exitplan.State {
+	price, _ := ts.MySwap.BuyPrice.Load().Float64()
+	var amount uint64
+	if bought := ts.MySwap.BuyAmount.Load(); bought != nil {
+		amount = bought.Uint64()
+	}
+	return exitplan.NewState(GetExitPlan(ts.Mode), price, amount, time.Now())
+}
+
+// armExit 开始按 state 管理仓位的退出：止盈止损挂单，之后每次价格更新重新计算其余规则；设有最长持有时间时到期清仓
+func (p *PumpFunMonitor) armExit(ts *TokenSwap, state *exitplan.State) {
+	tokenAddress := ts.Token.TokenAddress
+	rules := &exitRules{ts: ts, state: state, orders: make(map[string]uint64)}
+	rules.sell = func(s exitplan.Signal) error {
+		logx.Infof("[%s]:触发退出规则 %s，卖出 %d", tokenAddress, s.Rule, s.Amount)
+		if err := p.ExecuteSell(ts, new(big.Int).SetUint64(s.Amount), s.Rule); err != nil {
+			logx.Errorf("[%s]:%s 卖出失败，重新启用规则: %v", tokenAddress, s.Rule, err)
+			saveExitState(ts, rules.rearm(s.Rule))
+			rules.placeOrders()
+			return err
+		}
+		return nil
+	}
+	state.Orders = true
+	ts.exit.Store(rules)
+	saveExitState(ts, rules.snapshot())
+	logx.Infof("[%s]:退出规则 %+v", tokenAddress, state.Plan)
+	rules.placeOrders()
+
+	if deadline := state.Deadline(); !deadline.IsZero() {
+		go func() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			select {
+			case <-ts.Ctx.Done():
+			case <-timer.C:
+				ts.evaluateExit()
+			}
+		}()
+	}
+	ts.evaluateExit()
+}
+
+// placeOrders 把还没挂出的止盈、止损挂到 Orders，已经挂着的不重复挂
+func (r *exitRules) placeOrders() {
+	t := r.ts
+	if t.Ctx.Err() != nil {
+		return
+	}
+	tokenAddress, wallet := t.Token.TokenAddress, orderWallet(t)
+	remaining := t.remaining()
+	if remaining == 0 {
+		// 刚买入还没记录持仓
+		remaining = r.state.EntryAmount
+	}
+
+	r.mu.Lock()
+	var placed bool
+	for _, trigger := range r.state.Triggers(remaining) {
+		if _, ok := r.orders[trigger.Rule]; ok {
+			continue
+		}
+		kind := orders.StopLoss
+		if trigger.Above {
+			kind = orders.TakeProfit
+		}
+		id := Orders.Place(orders.Order{
+			Wallet: wallet,
+			Mint:   tokenAddress,
+			Kind:   kind,
+			Price:  trigger.Price,
+			Amount: trigger.Amount,
+			Rule:   trigger.Rule,
+		}, r.fill)
+		r.orders[trigger.Rule] = id
+		exitOrders.Store(id, r)
+		placed = true
+		logx.Infof("[%s] 退出规则 %s 挂单 %d, 触发价: %.15f, 数量: %d", tokenAddress, trigger.Rule, id, trigger.Price, trigger.Amount)
+	}
+	r.mu.Unlock()
+	if !placed {
+		return
+	}
+
+	watchOrders(t)
+	if price := t.Token.TokenPrice.Load(); price != nil {
+		f, _ := price.Float64()
+		Orders.OnPrice(tokenAddress, f)
+	}
+}
+
+// fill 条件单触发，按规则当前的状态算出卖出数量；规则已经触发过或撤销时不卖，订单记为取消
+func (r *exitRules) fill(o orders.Order) error {
+	exitOrders.Delete(o.ID)
+	r.mu.Lock()
+	if r.orders[o.Rule] == o.ID {
+		delete(r.orders, o.Rule)
+	}
+	signals := r.state.Fire(o.Rule, r.ts.remaining())
+	var snapshot *exitplan.State
+	if len(signals) > 0 {
+		snapshot = r.snapshotLocked()
+	}
+	r.mu.Unlock()
+	if len(signals) == 0 {
+		return errRuleNotArmed
+	}
+
+	saveExitState(r.ts, snapshot)
+	var err error
+	for _, s := range signals {
+		if sellErr := r.sell(s); sellErr != nil {
+			err = sellErr
+		}
+	}
+	return err
+}
+
+// disarmExitOrder 撤单时一并撤销对应的退出规则，之后不会再触发；不是退出规则的单时什么都不做
+func disarmExitOrder(o orders.Order) {
+	v, ok := exitOrders.LoadAndDelete(o.ID)
+	if !ok {
+		return
+	}
+	r := v.(*exitRules)
+	r.mu.Lock()
+	if r.orders[o.Rule] == o.ID {
+		delete(r.orders, o.Rule)
+	}
+	r.state.Disarm(o.Rule)
+	snapshot := r.snapshotLocked()
+	r.mu.Unlock()
+
+	saveExitState(r.ts, snapshot)
+	logx.Infof("[%s]:撤销退出规则 %s", o.Mint, o.Rule)
+}
+
+// evaluateExit 按当前价格计算退出规则，触发的卖出依次执行
+func (t *TokenSwap) evaluateExit() {
+	rules := t.exit.Load()
+	if rules == nil {
+		return
+	}
+	var price float64
+	if current := t.Token.TokenPrice.Load(); current != nil {
+		price, _ = current.Float64()
+	}
+	remaining := t.remaining()
+	t.applyExit(rules, func(s *exitplan.State) []exitplan.Signal {
+		return s.OnPrice(price, remaining, time.Now())
+	})
+}
+
+// leaderExit 跟随的钱包卖出 sold 个 token，按 leader_exit 规则跟着卖出
+func (t *TokenSwap) leaderExit(sold uint64) {
+	held := t.Tracked.RemainingAmount.Load()
+	ratio := 1.0
+	if held != nil && held.Sign() > 0 {
+		ratio = min(float64(sold)/float64(held.Uint64()), 1)
+		t.Tracked.RemainingAmount.Sub(new(big.Int).SetUint64(min(sold, held.Uint64())))
+	}
+
+	rules := t.exit.Load()
+	if rules == nil {
+		return
+	}
+	remaining := t.remaining()
+	t.applyExit(rules, func(s *exitplan.State) []exitplan.Signal {
+		return s.OnLeaderExit(ratio, remaining)
+	})
+}
+
+func (t *TokenSwap) applyExit(rules *exitRules, eval func(*exitplan.State) []exitplan.Signal) {
+	rules.mu.Lock()
+	signals := eval(rules.state)
+	var snapshot *exitplan.State
+	if len(signals) > 0 {
+		snapshot = rules.snapshotLocked()
+	}
+	rules.mu.Unlock()
+	if len(signals) == 0 {
+		return
+	}
+
+	saveExitState(t, snapshot)
+	go func() {
+		for _, s := range signals {
+			rules.sell(s)
+		}
+	}()
+}
+
+// holdSteps 持有到期分几批卖出，没有退出规则时为 3 批
+func (t *TokenSwap) holdSteps() int {
+	if rules := t.exit.Load(); rules != nil {
+		return rules.state.HoldSteps()
+	}
+	return 3
+}
+
+// remaining 当前记录的持仓；价格每次更新都会用到，不像 GetRemainingAmount 那样再去查链上余额
+func (t *TokenSwap) remaining() uint64 {
+	if amount := t.MySwap.RemainingAmount.Load(); amount != nil && amount.Sign() > 0 {
+		return amount.Uint64()
+	}
+	return 0
+}
+
+// rearm 撤销 rule 的触发，返回之后的状态用于保存
+func (r *exitRules) rearm(rule string) *exitplan.State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Rearm(rule)
+	return r.snapshotLocked()
+}
+
+func (r *exitRules) snapshot() *exitplan.State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshotLocked()
+}
+
+func (r *exitRules) snapshotLocked() *exitplan.State {
+	s := *r.state
+	s.Fired = slices.Clone(r.state.Fired)
+	return &s
+}
+
+// saveExitState 记录退出规则执行到的位置，重启后从这里继续
+func saveExitState(ts *TokenSwap, state *exitplan.State) {
+	updateExitPlan(ts, func(plan *positions.ExitPlan) {
+		plan.Rules = state
+	})
+}
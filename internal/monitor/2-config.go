@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"solana-bot/internal/exitplan"
+
 	"github.com/fsnotify/fsnotify"
 	"gopkg.in/yaml.v2"
 )
@@ -40,6 +42,7 @@ type StrategyParams struct {
 type StrategyConfig struct {
 	Default StrategyParams            `yaml:"default"`
 	Hourly  map[string]StrategyParams `yaml:"hourly"`
+	Exits   map[string]exitplan.Plan  `yaml:"exits"` // 按策略名配置的退出规则
 }
 
 var (
@@ -113,6 +116,12 @@ func ReloadConfig() error {
 		log.Fatalf("加载策略配置失败: %v", err)
 		return err
 	}
+	for name, plan := range strategyCfg.Exits {
+		if err := plan.Validate(); err != nil {
+			log.Fatalf("策略 %s 的退出规则配置错误: %v", name, err)
+			return err
+		}
+	}
 	strategyConfig = &strategyCfg
 
 	var mintCfg MintConfig
@@ -237,6 +246,16 @@ func GetRobotConfig() (*RobotConfig, error) {
 	return nil, errors.New("未加载配置文件")
 }
 
+// GetExitPlan 策略配置的退出规则，没有配置时只按策略自己的持有时间卖出
+func GetExitPlan(strategy string) exitplan.Plan {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	if strategyConfig == nil {
+		return exitplan.Plan{}
+	}
+	return strategyConfig.Exits[strategy]
+}
+
 func LoadYAMLConfig(path string, cfg any) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"errors"
+	"sync"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/positions"
+	"solana-bot/internal/risk"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Risk 开仓风控，整个进程共用；监控重启后敞口、熔断状态都还在
+var Risk = risk.New(config.RiskConf{})
+
+func init() {
+	// 熔断时暂停当前监控的所有策略，人工 ResetRisk 后恢复
+	Risk.OnHalt(func(reason string) {
+		if p := PumpMonitor; p != nil {
+			p.paused.Store(true)
+		}
+		logx.Errorf("[risk] 已暂停开仓: %s", reason)
+	})
+}
+
+// riskStateKey 风控状态在仓位库里的名字
+const riskStateKey = "risk"
+
+// 风控状态只在进程启动时从仓位库恢复一次
+var restoreRiskOnce sync.Once
+
+// restoreRisk 从仓位库恢复熔断状态和窗口内的已实现盈亏，之后每次变化都写回；
+// 在启动核对和开仓之前调用，重启不会解除熔断
+func restoreRisk() {
+	restoreRiskOnce.Do(func() {
+		if positionStore == nil {
+			return
+		}
+		var saved risk.Saved
+		err := positionStore.GetState(riskStateKey, &saved)
+		switch {
+		case err == nil:
+			Risk.Restore(saved)
+		case !errors.Is(err, positions.ErrNotFound):
+			logx.Errorf("[risk] 读取风控状态失败: %v", err)
+		}
+		Risk.OnChange(func(s risk.Saved) {
+			if err := positionStore.PutState(riskStateKey, s); err != nil {
+				logx.Errorf("[risk] 保存风控状态失败: %v", err)
+			}
+		})
+	})
+}
+
+// checkRisk 买入前按实际投入的 lamports 检查风控，通过后 ticket 记在 ts 上，平仓或买入失败时归还
+func checkRisk(ts *TokenSwap, amountIn uint64) error {
+	var leader string
+	if len(ts.Tracked.TrackedAddress) > 0 {
+		leader = ts.Tracked.TrackedAddress[0]
+	}
+	ticket, err := Risk.Check(risk.Entry{
+		Mint:      ts.Token.TokenAddress,
+		Strategy:  ts.Mode,
+		Leader:    leader,
+		Launchpad: poolTypeName(ts.SwapType.Load()),
+		Amount:    amountIn,
+		PoolSol:   ts.Token.PoolSolBalance.Load(),
+	})
+	if err != nil {
+		return err
+	}
+	ts.riskTicket.Store(ticket)
+	return nil
+}
+
+// trackRisk 启动时接管的持仓不做检查，只计入敞口和仓位数
+func trackRisk(ts *TokenSwap, cost uint64) {
+	ticket := Risk.Track(risk.Entry{
+		Mint:      ts.Token.TokenAddress,
+		Strategy:  ts.Mode,
+		Launchpad: poolTypeName(ts.SwapType.Load()),
+		Amount:    cost,
+	})
+	ts.riskTicket.Store(ticket)
+}
+
+// releaseRisk 买入没有成交，归还占用的额度
+func releaseRisk(ts *TokenSwap) {
+	if ticket := ts.riskTicket.Swap(0); ticket != 0 {
+		Risk.Release(ticket)
+	}
+}
+
+// closeRisk 仓位卖完，pnl 计入已实现盈亏
+func closeRisk(ts *TokenSwap, pnl int64) {
+	if ticket := ts.riskTicket.Swap(0); ticket != 0 {
+		Risk.Close(ticket, pnl)
+	}
+}
+
+// ResetRisk 人工解除熔断并恢复当前监控，返回解除前是否处于熔断
+func ResetRisk() bool {
+	halted := Risk.Reset()
+	if p := PumpMonitor; p != nil {
+		p.paused.Store(false)
+	}
+	return halted
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"solana-bot/internal/config"
+	"solana-bot/internal/dex/meteora"
 	"solana-bot/internal/dex/pump"
+	"solana-bot/internal/dex/raydium"
 	"solana-bot/internal/global"
 	"solana-bot/internal/stream"
+	"solana-bot/internal/wallets"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -43,6 +45,30 @@ var (
 	}
 )
 
+// poolTypeName SwapType 对应的池子类型名
+func poolTypeName(swapType int32) string {
+	for name, t := range PumpSwapType {
+		if t == swapType {
+			return name
+		}
+	}
+	return fmt.Sprintf("SwapType(%d)", swapType)
+}
+
+// loadPool 按 T 取出当前的池子快照；还没加载或池子已经换成别的类型（迁移）时返回错误
+func loadPool[T any](ts *TokenSwap) (T, error) {
+	var pool T
+	poolData := ts.Token.PoolData.Load()
+	if poolData == nil {
+		return pool, fmt.Errorf("[%s] pool data not loaded", ts.Token.TokenAddress)
+	}
+	pool, ok := poolData.Data.(T)
+	if !ok {
+		return pool, fmt.Errorf("[%s] pool data is %T, want %T", ts.Token.TokenAddress, poolData.Data, pool)
+	}
+	return pool, nil
+}
+
 var (
 	holdInfoMap   = make(map[string]*TokenHoldInfo)
 	holdInfoMutex sync.RWMutex
@@ -51,11 +77,13 @@ var (
 // 代币本身的链上和市场状态
 type TokenInfo struct {
 	TokenAddress     string
-	PoolData         *solanaswapgo.PoolData
+	PoolData         atomic.Pointer[solanaswapgo.PoolData] // 流里成交解析出的池子，迁移时整体替换
 	BondingCurveData atomic.Value
 	PoolTokenBalance atomic.Uint64
 	PoolSolBalance   atomic.Uint64
 	TokenPrice       atomic_.BigFloat // 当前估算价格
+	SqrtPrice        atomic_.BigInt   // Meteora DBC 的 sqrt_price (Q64.64)
+	Migrated         atomic.Bool      // 池子已完成/迁移
 }
 
 // 作为狙击者的状态和行为
@@ -100,19 +128,28 @@ type TokenHoldInfo struct {
 }
 
 type TokenSwap struct {
-	streams         []*stream.GrpcStream
-	Ctx             context.Context
-	Cancel          context.CancelFunc
-	Cmd             chan string
-	IsMint          bool
-	SwapType        atomic.Int32
-	BundleTx        string
-	readyToSell     atomic.Bool
-	Token           *TokenInfo
-	MySwap          *MySwapState
-	Tracked         *TrackedWalletInfo
-	FollowChan      chan *solanaswapgo.SwapInfo
-	SellSignalCount atomic.Int32 // 新增字段，用于记录卖出信号次数
+	hub         *stream.Hub
+	Ctx         context.Context
+	Cancel      context.CancelFunc
+	Cmd         chan string
+	IsMint      bool
+	Mode        string          // 触发的监控模式：mint、smart、scm，用于选择发送通道
+	Wallet      *wallets.Wallet `json:"-"` // 这笔仓位使用的交易钱包
+	SwapType    atomic.Int32
+	BundleTx    string
+	Slot        uint64      // 触发这次跟单的源交易所在 slot
+	Retracted   atomic.Bool // 源交易所在 slot 已回滚
+	readyToSell atomic.Bool
+	Token       *TokenInfo
+	MySwap      *MySwapState
+	Tracked     *TrackedWalletInfo
+	FollowChan  chan *solanaswapgo.SwapInfo
+	poolSub     atomic.Pointer[stream.HubConsumer]
+	decimalsReq atomic.Bool               // 正在查询 token 精度
+	poolLive    atomic.Bool               // 已收到池子账户更新，成交推算的储备不再覆盖
+	exit        atomic.Pointer[exitRules] // 买入后按策略配置的退出规则，价格每次更新时计算
+	ordersArmed atomic.Bool               // 已在 Orders 挂过单，仓位结束时撤掉
+	riskTicket  atomic.Uint64             // 风控占用的额度，平仓或买入失败时归还
 }
 
 func NewTokenJupiterSwap(tokenAddress string) *TokenSwap {
@@ -133,23 +170,18 @@ func NewTokenJupiterSwap(tokenAddress string) *TokenSwap {
 	return ts
 }
 
-func NewTokenSwap(isMint bool, bundleTx, tokenAddress string, trackedAddress []string, ata string, poolData *solanaswapgo.PoolData) *TokenSwap {
-
-	streams := []*stream.GrpcStream{
-		stream.NewBlzStream(),
-	}
+func NewTokenSwap(hub *stream.Hub, isMint bool, bundleTx, tokenAddress string, trackedAddress []string, ata string, poolData *solanaswapgo.PoolData) *TokenSwap {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	ts := &TokenSwap{
 		IsMint:   isMint,
 		BundleTx: bundleTx,
-		streams:  streams,
+		hub:      hub,
 		Ctx:      ctx,
 		Cancel:   cancel,
 		Cmd:      make(chan string),
 		Token: &TokenInfo{
 			TokenAddress: tokenAddress,
-			PoolData:     poolData,
 		},
 		MySwap: &MySwapState{
 			AtaAddress: *atomic_.NewString(ata),
@@ -163,47 +195,45 @@ func NewTokenSwap(isMint bool, bundleTx, tokenAddress string, trackedAddress []s
 	if poolData == nil {
 		return ts
 	}
+	ts.Token.PoolData.Store(poolData)
 
 	ts.SwapType.Store(PumpSwapType[poolData.PoolType])
 
-	switch ts.SwapType.Load() {
-	case PumpFunType:
-		fumPool := poolData.Data.(*solanaswapgo.PumpFunPool)
+	switch pool := poolData.Data.(type) {
+	case *solanaswapgo.PumpFunPool:
 		bondingCurveData := &pump.PUMPBondingCurveData{
 			BondingCurve: &pump.BondingCurveLayout{
-				VirtualTokenReserves: fumPool.VirtualTokenReserves,
-				VirtualSOLReserves:   fumPool.VirtualSolReserves,
-				RealTokenReserves:    fumPool.RealTokenReserves,
-				RealSOLReserves:      fumPool.RealSOLReserves,
+				VirtualTokenReserves: pool.VirtualTokenReserves,
+				VirtualSOLReserves:   pool.VirtualSolReserves,
+				RealTokenReserves:    pool.RealTokenReserves,
+				RealSOLReserves:      pool.RealSOLReserves,
 			},
-			BondingCurvePk:           fumPool.BondingCurve,
-			AssociatedBondingCurvePk: fumPool.AssociatedBondingCurve,
-			GlobalSettingsPk:         fumPool.Global,
-			MintAuthority:            fumPool.EventAuthority,
+			BondingCurvePk:           pool.BondingCurve,
+			AssociatedBondingCurvePk: pool.AssociatedBondingCurve,
+			GlobalSettingsPk:         pool.Global,
+			MintAuthority:            pool.EventAuthority,
 		}
 		ts.Token.BondingCurveData.Store(bondingCurveData)
-		ts.Token.PoolTokenBalance.Store(fumPool.VirtualTokenReserves)
-		ts.Token.PoolSolBalance.Store(fumPool.VirtualSolReserves)
+		ts.Token.PoolTokenBalance.Store(pool.VirtualTokenReserves)
+		ts.Token.PoolSolBalance.Store(pool.VirtualSolReserves)
 
-	case PumpAmmType:
-		ammPool := poolData.Data.(*solanaswapgo.PumpAmmPool)
-		ts.Token.PoolTokenBalance.Store(ammPool.PoolBaseTokenReserves)
-		ts.Token.PoolSolBalance.Store(ammPool.PoolQuoteTokenReserves)
+	case *solanaswapgo.PumpAmmPool:
+		ts.Token.PoolTokenBalance.Store(pool.PoolBaseTokenReserves)
+		ts.Token.PoolSolBalance.Store(pool.PoolQuoteTokenReserves)
 
-	case MeteoraDbcType:
-		pool := poolData.Data.(*solanaswapgo.MeteoraDbcPool)
-		sqrtP := new(big.Float).SetFloat64(float64(pool.NextSqrtPrice))
-		ts.Token.TokenPrice.Store(sqrtP)
+	case *solanaswapgo.MeteoraDbcPool:
+		ts.UpdatePricePool(pool.NextSqrtPrice)
 
-	case RaydiumLaunchpadType:
-		pool := poolData.Data.(*solanaswapgo.RaydiumLaunchpadPool)
-		ts.Token.PoolTokenBalance.Store(pool.RealBaseBefore)
-		ts.Token.PoolSolBalance.Store(pool.RealQuoteBefore)
+	case *solanaswapgo.RaydiumLaunchpadPool:
+		base, quote := launchpadReserves(pool)
+		ts.Token.PoolTokenBalance.Store(base)
+		ts.Token.PoolSolBalance.Store(quote)
 
 	}
 
 	// spew.Dump(ts)
 	go ts.SubTokenSwap()
+	go ts.SubPoolState()
 
 	return ts
 }
@@ -224,21 +254,17 @@ func (t *TokenSwap) SubTokenSwap() {
 
 	subscription.Transactions["transactions_sub"].AccountInclude = []string{t.Token.TokenAddress}
 
-	var once sync.Once
-	for _, s := range t.streams {
-		s.Subscribe(t.Ctx, &subscription, &once, subscribe)
-	}
-
-	deduper := NewTxDeduper(1_000_000, 0.001) // 预计 100 万交易，误判率 0.1%
+	// 只是在共享流上追加过滤器，不新建连接
+	t.hub.Subscribe(t.Ctx, &subscription, subscribe)
 
 	for {
 		select {
 		case <-t.Ctx.Done():
 			logx.Infof("[%s] 停止监听Token交易", t.Token.TokenAddress)
 			return
-		case msg := <-subscribe:
-			if msg == nil {
-				continue
+		case msg, ok := <-subscribe:
+			if !ok {
+				return
 			}
 			// 检查消息是否包含来源信息
 			var got *pb.SubscribeUpdate
@@ -250,16 +276,15 @@ func (t *TokenSwap) SubTokenSwap() {
 			if tx == nil || tx.Transaction.Transaction == nil || tx.Transaction.Meta == nil {
 				continue
 			}
+			if t.hub.Slots().Status(tx.Slot) == stream.SlotRolledBack {
+				continue
+			}
 
 			swapInfo, err := ParseSwapTransaction(tx.Transaction.Transaction, tx.Transaction.Meta)
 			if err != nil || swapInfo == nil {
 				continue
 			}
 
-			if deduper.SeenOrAdd(swapInfo.Signatures[0].String()) {
-				// logx.Infof("Skip duplicate:%s", swapInfo.Signatures[0].String())
-				continue
-			}
 			// 处理交易
 			// logx.Infof("[%s]:Token交易:%v", t.Token.TokenAddress, swapInfo)
 
@@ -271,7 +296,10 @@ func (t *TokenSwap) SubTokenSwap() {
 }
 
 func (t *TokenSwap) OnTrade(swapInfo *solanaswapgo.SwapInfo) {
-	go t.UpdatePoolData(swapInfo.PoolData)
+	// 池子账户订阅生效后以链上状态为准，只有池子类型变化（迁移）时才用成交里的池子数据
+	if swapInfo.PoolData != nil && (!t.poolLive.Load() || PumpSwapType[swapInfo.PoolData.PoolType] != t.SwapType.Load()) {
+		go t.UpdatePoolData(swapInfo.PoolData)
+	}
 
 	select {
 	case <-t.Ctx.Done():
@@ -286,7 +314,6 @@ func (t *TokenSwap) OnTrade(swapInfo *solanaswapgo.SwapInfo) {
 func (t *TokenSwap) doTrade(swapInfo *solanaswapgo.SwapInfo) {
 
 	buyPrice := t.MySwap.BuyPrice.Load()
-	sellPrice := t.MySwap.SellPrice.Load()
 	currentPrice := t.Token.TokenPrice.Load()
 	buyAmount := t.MySwap.BuyAmount.Load()
 
@@ -294,7 +321,7 @@ func (t *TokenSwap) doTrade(swapInfo *solanaswapgo.SwapInfo) {
 
 	buyer := swapInfo.Signers[0].String()
 
-	if buyer == config.C.Bot.Player {
+	if t.Wallet != nil && buyer == t.Wallet.PublicKey().String() {
 		if swapInfo.TokenInMint.String() == global.Solana {
 			t.readyToSell.Store(true)
 			fmt.Printf("[准备卖出] 我在 %s 买入 %d token\n", time.Now().Format(time.RFC822), swapInfo.TokenOutAmount)
@@ -313,16 +340,6 @@ func (t *TokenSwap) doTrade(swapInfo *solanaswapgo.SwapInfo) {
 		return
 	}
 
-	// 在止损部分增加计数器逻辑
-	if sellPrice != nil && currentPrice.Cmp(new(big.Float).Mul(sellPrice, big.NewFloat(0.8))) < 0 {
-		// 当前价格 < 卖出价格的 80%，触发卖出
-		logx.Infof("[%s] ⛔ 部分止损 ", t.Token.TokenAddress)
-		t.Cmd <- "sell"
-		t.readyToSell.Store(false)
-		return
-
-	}
-
 	//监听钱包卖出
 	if slices.Contains(t.Tracked.TrackedAddress, buyer) {
 		if swapInfo.TokenOutMint.String() == global.Solana {
@@ -333,22 +350,8 @@ func (t *TokenSwap) doTrade(swapInfo *solanaswapgo.SwapInfo) {
 				return
 			}
 
-			logx.Infof("[%s] ‼️ 监听到钱包卖出，立即跟随 ", t.Token.TokenAddress)
-			logx.Infof("[%s] ‼️ 跟随钱包持有 %d，监听钱包卖出部分 %d ", t.Token.TokenAddress, t.Tracked.RemainingAmount.Load(), swapInfo.TokenInAmount)
-			if t.Tracked.RemainingAmount.Load().Cmp(big.NewInt(int64(swapInfo.TokenInAmount))) <= 0 {
-				logx.Infof("[%s] ‼️ 跟随钱包卖出，监听钱包卖出全部 ", t.Token.TokenAddress)
-
-				t.Cmd <- "sell"
-				t.readyToSell.Store(false)
-				return
-			}
-			logx.Infof("[%s] ‼️ 跟随钱包卖出，监听钱包卖出部分 ", t.Token.TokenAddress)
-			t.SellSignalCount.Add(1)
-			if t.SellSignalCount.Load() >= 2 {
-				t.Cmd <- "sell"
-				t.readyToSell.Store(false)
-				return
-			}
+			logx.Infof("[%s] ‼️ 跟随钱包持有 %d，监听到钱包卖出 %d ", t.Token.TokenAddress, t.Tracked.RemainingAmount.Load(), swapInfo.TokenInAmount)
+			t.leaderExit(swapInfo.TokenInAmount)
 		}
 
 	}
@@ -464,6 +467,15 @@ func (t *TokenSwap) UpdatePoolData(poolData *solanaswapgo.PoolData) {
 	if poolData == nil {
 		return
 	}
+	// 池子类型变了说明已迁移，换成新池子并切换账户订阅
+	if swapType, ok := PumpSwapType[poolData.PoolType]; ok && swapType != JupiterType && swapType != t.SwapType.Load() {
+		// 并发的两次更新只有一次完成切换
+		if old := t.Token.PoolData.Load(); old != nil && t.Token.PoolData.CompareAndSwap(old, poolData) {
+			logx.Infof("[%s]:池子从 %s 迁移到 %s", t.Token.TokenAddress, old.PoolType, poolData.PoolType)
+			t.Token.Migrated.Store(false)
+			defer t.resubscribePool()
+		}
+	}
 	switch pool := poolData.Data.(type) {
 	case *solanaswapgo.PumpFunPool:
 		t.UpdateBondingCurve(pool.VirtualSolReserves, pool.VirtualTokenReserves, pool.RealSOLReserves, pool.RealTokenReserves)
@@ -475,35 +487,61 @@ func (t *TokenSwap) UpdatePoolData(poolData *solanaswapgo.PoolData) {
 		t.UpdatePricePool(pool.NextSqrtPrice)
 		t.SwapType.Store(MeteoraDbcType)
 	case *solanaswapgo.RaydiumLaunchpadPool:
-		t.UpdateAmmPool(pool.RealBaseBefore, pool.RealQuoteBefore)
+		t.UpdateAmmPool(launchpadReserves(pool))
 		t.SwapType.Store(RaydiumLaunchpadType)
 	}
 
 }
 
-func (t *TokenSwap) UpdatePricePool(nextSqrtPrice uint64) {
-	sqrtP := new(big.Float).SetFloat64(float64(nextSqrtPrice))
-	t.Token.TokenPrice.Store(sqrtP)
+// launchpadReserves 成交事件里的交易前储备换算成曲线上的有效储备
+func launchpadReserves(pool *solanaswapgo.RaydiumLaunchpadPool) (base, quote uint64) {
+	state := raydium.LaunchpadPoolState{
+		VirtualBase:  pool.VirtualBase,
+		VirtualQuote: pool.VirtualQuote,
+		RealBase:     pool.RealBaseBefore,
+		RealQuote:    pool.RealQuoteBefore,
+	}
+	return state.Reserves()
 }
 
-func (t *TokenSwap) UpdateAmmPool(baseBalance, quoteBalance uint64) {
-
-	t.Token.PoolTokenBalance.Store(quoteBalance)
-
-	t.Token.PoolSolBalance.Store(baseBalance)
+func (t *TokenSwap) UpdatePricePool(nextSqrtPrice uint64) {
+	t.setSqrtPrice(new(big.Int).SetUint64(nextSqrtPrice))
+}
 
-	// 原始数据
-	solReserves := new(big.Float).SetUint64(t.Token.PoolSolBalance.Load())
-	lamportsPerSol := new(big.Float).SetUint64(solana.LAMPORTS_PER_SOL)
-	tokenReserves := new(big.Float).SetUint64(t.Token.PoolTokenBalance.Load())
+// setSqrtPrice 按 Meteora DBC 的 sqrt_price 更新价格；token 精度还没查到时先去查，查到后再算价格
+func (t *TokenSwap) setSqrtPrice(sqrtPrice *big.Int) {
+	t.Token.SqrtPrice.Store(sqrtPrice)
+	mint, err := solana.PublicKeyFromBase58(t.Token.TokenAddress)
+	if err != nil {
+		return
+	}
+	decimals, ok := global.CachedMintDecimals(mint)
+	if !ok {
+		if t.decimalsReq.CompareAndSwap(false, true) {
+			go t.loadDecimals(mint)
+		}
+		return
+	}
+	state := meteora.VirtualPoolState{SqrtPrice: sqrtPrice}
+	t.setPrice(state.Price(int(decimals), 9))
+}
 
-	// 计算：VirtualSOLReserves / LAMPORTS_PER_SOL / VirtualTokenReserves * 1e6
-	var solTokenPrice big.Float
-	solTokenPrice.Quo(solReserves, lamportsPerSol)       // SOL数量
-	solTokenPrice.Quo(&solTokenPrice, tokenReserves)     // 单位token价格
-	solTokenPrice.Mul(&solTokenPrice, big.NewFloat(1e6)) // 转换为每百万单位token的价格
+func (t *TokenSwap) loadDecimals(mint solana.PublicKey) {
+	defer t.decimalsReq.Store(false)
+	if _, err := global.GetMintDecimals(mint); err != nil {
+		logx.Errorf("[%s]:查询 token 精度失败: %v", t.Token.TokenAddress, err)
+		return
+	}
+	if sqrtPrice := t.Token.SqrtPrice.Load(); sqrtPrice != nil {
+		t.setSqrtPrice(sqrtPrice)
+	}
+}
 
-	t.Token.TokenPrice.Store(&solTokenPrice)
+// UpdateAmmPool base 为 token 储备，quote 为 SOL 储备
+func (t *TokenSwap) UpdateAmmPool(baseBalance, quoteBalance uint64) {
+	t.Token.PoolTokenBalance.Store(baseBalance)
+	t.Token.PoolSolBalance.Store(quoteBalance)
+	t.setPrice(poolPrice(baseBalance, quoteBalance))
 }
 
 func (t *TokenSwap) UpdateBondingCurve(virtualSolReserves, virtualTokenReserves, realSolReserves, realTokenReserves uint64) {
@@ -520,19 +558,20 @@ func (t *TokenSwap) UpdateBondingCurve(virtualSolReserves, virtualTokenReserves,
 		t.Token.BondingCurveData.Store(data)
 	}
 
-	t.Token.PoolTokenBalance.Store(realTokenReserves)
-	t.Token.PoolSolBalance.Store(realSolReserves)
-
-	lamportsPerSol := new(big.Float).SetUint64(solana.LAMPORTS_PER_SOL)
+	// 曲线价格由虚拟储备决定
+	t.Token.PoolTokenBalance.Store(virtualTokenReserves)
+	t.Token.PoolSolBalance.Store(virtualSolReserves)
+	t.setPrice(poolPrice(virtualTokenReserves, virtualSolReserves))
 
-	// 计算：VirtualSOLReserves / LAMPORTS_PER_SOL / VirtualTokenReserves * 1e6
-	var solTokenPrice big.Float
-	solTokenPrice.Quo(new(big.Float).SetUint64(realSolReserves), lamportsPerSol)   // SOL数量
-	solTokenPrice.Quo(&solTokenPrice, new(big.Float).SetUint64(realTokenReserves)) // 单位token价格
-	solTokenPrice.Mul(&solTokenPrice, big.NewFloat(1e6))                           // 转换为每百万单位token的价格
-
-	t.Token.TokenPrice.Store(&solTokenPrice)
+}
 
+// setPrice 更新当前价格，按退出规则重新计算并撮合挂着的条件单
+func (t *TokenSwap) setPrice(price *big.Float) {
+	t.Token.TokenPrice.Store(price)
+	t.evaluateExit()
+	if f, _ := price.Float64(); f > 0 {
+		Orders.OnPrice(t.Token.TokenAddress, f)
+	}
 }
 
 func (t *TokenSwap) GetBondingCurveData() *pump.PUMPBondingCurveData {
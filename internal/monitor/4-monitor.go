@@ -2,20 +2,28 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"solana-bot/internal/alt"
 	"solana-bot/internal/client"
+	"solana-bot/internal/config"
+	"solana-bot/internal/confirm"
 	"solana-bot/internal/dex/pump"
+	"solana-bot/internal/fee"
 	"solana-bot/internal/global"
 	atomic_ "solana-bot/internal/global/utils/atomic"
 	"solana-bot/internal/global/utils/fifomap"
 	"solana-bot/internal/global/utils/pubsub"
 
+	"solana-bot/internal/preflight"
 	"solana-bot/internal/rpcs"
 	"solana-bot/internal/stream"
+	"solana-bot/internal/txerr"
+	"solana-bot/internal/wallets"
 
 	"sync"
 
@@ -28,16 +36,31 @@ import (
 var (
 	PumpTokenMintAuthority    = "TSLvdd1pWpHVjahSpsvCXUbgwsL3JAcvokwaKt1eokM"
 	RaydiumLaunchpadAuthority = "WLHv2UAZm6z4KyaaELi5pjdbJh6RESMva1Rnn8pJVVh"
-	MonitorType               = map[string]bool{
-		"mint":  false,
-		"smart": true,
-		"scm":   false,
-	}
-	BuyCache *fifomap.FIFOMap
+	BuyCache                  *fifomap.FIFOMap
+
+	RecordFile  string  // 非空时把收到的所有流更新录制到该文件
+	ReplayFile  string  // 非空时不连接 GRPC，改为回放该录制文件
+	ReplaySpeed float64 // 回放速度，1 为原速，<=0 尽快回放
+
+	Paper        bool   // 不广播交易，按本地池子状态模拟成交
+	PaperBalance uint64 // paper 模式下每个钱包的初始 lamports
 )
 
-func SetMonitorType(mt map[string]bool) {
-	MonitorType = mt
+// SetRecord 开启流录制
+func SetRecord(path string) {
+	RecordFile = path
+}
+
+// SetReplay 使用录制文件代替实时流
+func SetReplay(path string, speed float64) {
+	ReplayFile = path
+	ReplaySpeed = speed
+}
+
+// SetPaper 开启 paper 模式，balance 为每个钱包的初始 lamports
+func SetPaper(balance uint64) {
+	Paper = true
+	PaperBalance = balance
 }
 
 // PumpFunMonitor 监控Pump.fun上的交易
@@ -47,10 +70,16 @@ type PumpFunMonitor struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	paused        atomic_.Bool // 新增字段，用于控制暂停状态
-	rpcs          []rpcs.RpcChannel
-	streams       []*stream.GrpcStream
+	channels      *rpcs.Registry
+	bidder        *rpcs.Bidder     // 按通道的小费竞价
+	confirms      *confirm.Tracker // 从流里确认自己发出的交易
+	hub           *stream.Hub
+	recorder      *stream.Recorder
+	replay        *stream.ReplaySource
 	httpClient    *rpc.Client
-	wallet        *solana.Wallet
+	wallets       *wallets.Set
+	preflight     *preflight.Preflight // 发送前模拟，未开启时为 nil
+	paper         *rpcs.PaperChannel   // paper 模式的模拟通道，实盘时为 nil
 	pubsub        *pubsub.PubSub
 	buyMultiplier *atomic_.Float64 // 买入系数
 	lastBuyTime   *atomic_.Map     // string -> time.Time
@@ -61,48 +90,152 @@ var PumpMonitor *PumpFunMonitor
 // NewPumpFunMonitor 创建监控实例
 func NewPumpFunMonitor() (*PumpFunMonitor, error) {
 
-	wallet, err := solana.WalletFromPrivateKeyBase58(os.Getenv("PRIVATE_KEY"))
+	global.ConnectToEndpoints()
+
+	BuyCache = fifomap.NewFIFOMap(5)
+	// 风控上限随配置热重载更新，敞口和熔断状态保留
+	Risk.SetLimits(config.C.Bot.Risk)
+
+	bidder := rpcs.NewBidder(config.C.Bot.Tip)
+	// 发送通道及各模式买卖使用的通道来自 etc.yaml
+	channels, err := rpcs.NewRegistry(config.C.Bot.Channels, config.C.Bot.Routes)
+	if err != nil {
+		return nil, err
+	}
+	// 交易钱包来自 etc.yaml，签名前检查交易，SOL 只允许转给各通道的小费账户
+	tips, err := rpcs.TipAccounts(config.C.Bot.Channels)
+	if err != nil {
+		return nil, err
+	}
+	// 小费转账必须用静态账户，不从地址表加载
+	global.SetStaticAccounts(tips...)
+	tradingWallets, err := wallets.Load(config.C.Bot, tips...)
 	if err != nil {
 		logx.Must(err)
 		return nil, err
 	}
 
-	global.ConnectToEndpoints()
-
-	BuyCache = fifomap.NewFIFOMap(5)
+	var streams []*stream.GrpcStream
+	var replay *stream.ReplaySource
+	if ReplayFile != "" {
+		replay = stream.NewReplaySource(ReplayFile, ReplaySpeed)
+	} else {
+		blzStream := stream.NewBlzStream()
+		if len(blzStream.Conns) == 0 {
+			return nil, errors.New("无法连接到GRPC服务器")
+		}
+		// Triton、自建节点按环境变量可选接入，和 BlockRazor 一起做 fan-in
+		streams = append(streams, blzStream)
+		for _, s := range []*stream.GrpcStream{stream.NewTritonStream(), stream.NewSelfStream()} {
+			if len(s.Conns) > 0 {
+				streams = append(streams, s)
+			}
+		}
+	}
 
-	streams := []*stream.GrpcStream{
-		stream.NewBlzStream(),
+	var recorder *stream.Recorder
+	if RecordFile != "" {
+		recorder, err = stream.NewRecorder(RecordFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	rpcs := []rpcs.RpcChannel{
-		rpcs.NewJitoChannel(),
-		rpcs.NewBlzChannel(),
-		rpcs.NewAstralaneChannel(),
-		// rpcs.NewTempgoralChannel(),
+	ctx, cancel := context.WithCancel(context.Background())
+	// 所有订阅共用每个端点的一条流，按过滤器分发，多来源去重
+	hub := stream.NewHub(ctx, streams...)
+	if recorder != nil {
+		hub.SetRecorder(recorder)
 	}
 
-	grpcClient := streams[0].Conns[0]
-	if grpcClient == nil {
-		return nil, err
+	// 尽早开始跟踪 slot 状态，回滚通知依赖它
+	hub.Slots()
+
+	go stream.BlockSubscribeWithRelay(ctx, hub)
+	for _, w := range tradingWallets.All() {
+		go stream.NonceSubscribeWithRelay(ctx, hub, w.Nonces)
 	}
-	go stream.BlockSubscribeWithRelay(grpcClient)
-	go stream.NonceSubscribeWithRelay(grpcClient)
-	go stream.WSOLSubscribeWithRelay(grpcClient)
-	go stream.UpdateGasWithRelay()
+	// paper 模式的余额来自模拟账，不用链上余额覆盖
+	if !Paper {
+		go stream.WSOLSubscribeWithRelay(ctx, hub, tradingWallets.PublicKeys()...)
+	}
+	// 优先费按池子账户估算，合并 relay、RPC 和自己流里观察到的费用
+	go fee.Run(ctx, hub)
+	go bidder.Run(ctx, time.Minute)
 
 	HTTPUrls := strings.Split(os.Getenv("BLZ_HTTP_URLS"), ",")
+	httpClient := rpc.New(HTTPUrls[0])
+	// 交易钱包参与的交易一上链就能拿到结果，RPC 只做兜底
+	confirms := confirm.NewTracker(httpClient)
+	var walletKeys []string
+	for _, key := range tradingWallets.PublicKeys() {
+		walletKeys = append(walletKeys, key.String())
+	}
+	go confirms.Run(ctx, hub, walletKeys...)
+
+	// paper 模式所有模式都只走模拟通道，成交结果直接交给 confirms
+	var paper *rpcs.PaperChannel
+	if Paper {
+		paper = rpcs.NewPaperChannel(PaperBalance, rpcs.DefaultPaperLatency, confirms.Resolve)
+		channels = rpcs.RegistryOf(paper)
+		// 模拟账的余额单独记，不覆盖真实钱包的全局余额
+		for _, w := range tradingWallets.All() {
+			w.Balance = &global.WalletBalance{}
+			w.Balance.SetSol(PaperBalance)
+		}
+		paper.OnBalance(func(owner solana.PublicKey, sol uint64) {
+			if w := tradingWallets.Get(owner); w != nil {
+				w.Balance.SetSol(sol)
+			}
+		})
+		logx.Infof("[paper] 不广播交易，每个钱包初始 %d lamports", PaperBalance)
+	}
+
+	// 常用账户从地址表加载，交易以 v0 格式发送
+	if len(config.C.Bot.LookupTables) > 0 {
+		tables, err := alt.PublicKeys(config.C.Bot.LookupTables)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		lookups := alt.NewManager(httpClient)
+		if err := lookups.Load(ctx, tables...); err != nil {
+			cancel()
+			return nil, err
+		}
+		global.SetAddressTables(lookups.Tables())
+		go lookups.Run(ctx, time.Minute, global.SetAddressTables)
+	}
+
+	// 仓位落盘，paper 模式的仓位只在内存里
+	if !Paper {
+		if err := openPositionStore(config.C.Bot.Positions); err != nil {
+			cancel()
+			return nil, fmt.Errorf("打开仓位库 %s 失败: %w", config.C.Bot.Positions, err)
+		}
+		restoreRisk()
+	}
+
+	var pre *preflight.Preflight
+	if config.C.Bot.Preflight.Enabled && !Paper {
+		pre = preflight.New(httpClient, config.C.Bot.Preflight)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
 	return &PumpFunMonitor{
 			Wg:            &sync.WaitGroup{},
 			mu:            sync.Mutex{},
 			ctx:           ctx,
 			cancel:        cancel,
-			rpcs:          rpcs,
-			streams:       streams,
-			httpClient:    rpc.New(HTTPUrls[0]),
-			wallet:        wallet,
+			channels:      channels,
+			bidder:        bidder,
+			confirms:      confirms,
+			hub:           hub,
+			recorder:      recorder,
+			replay:        replay,
+			httpClient:    httpClient,
+			wallets:       tradingWallets,
+			preflight:     pre,
+			paper:         paper,
 			lastBuyTime:   atomic_.NewMap(), // 初始化 Map
 			pubsub:        pubsub.NewPubSub(),
 			buyMultiplier: atomic_.NewFloat64(1.0),
@@ -121,26 +254,145 @@ func (p *PumpFunMonitor) Go(fn func()) {
 	}()
 }
 
-func (p *PumpFunMonitor) Start() {
-	for k, v := range MonitorType {
-		if k == "mint" && v {
-			p.Go(func() {
-				p.workerForMint()
-			})
+func (p *PumpFunMonitor) Start() error {
+	enabled, err := enabledStrategies()
+	if err != nil {
+		return err
+	}
+
+	// 风控熔断后重启监控也保持暂停，等人工恢复；在策略开始收流之前设置
+	if Risk.Halted() {
+		p.paused.Store(true)
+		logx.Errorf("[risk] 风控熔断中，暂停开仓")
+	}
+
+	for _, s := range enabled {
+		s := s
+		p.Go(func() {
+			p.runStrategy(s)
+		})
+	}
+
+	// 接管上次运行留下的持仓
+	go p.reconcile()
+	go p.Profit()
+	go p.watchStreams()
+	go p.watchWallets()
+
+	if p.replay != nil {
+		go p.runReplay()
+	}
+	return nil
+}
+
+// runReplay 等各 worker 注册好过滤器后开始回放
+func (p *PumpFunMonitor) runReplay() {
+	select {
+	case <-p.ctx.Done():
+		return
+	case <-time.After(time.Second):
+	}
+	logx.Infof("[replay] start %s, speed %v", p.replay.Path, p.replay.Speed)
+	if err := p.replay.Run(p.ctx, p.hub.Inject); err != nil && p.ctx.Err() == nil {
+		logx.Errorf("[replay] %s: %v", p.replay.Path, err)
+	}
+}
+
+// StreamStatus 返回所有 Geyser 订阅的连接状态
+func (p *PumpFunMonitor) StreamStatus() []stream.SubscriptionStatus {
+	return stream.Statuses()
+}
+
+// watchStreams 定期输出不健康的订阅，避免流静默断开无人察觉
+func (p *PumpFunMonitor) watchStreams() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, st := range p.StreamStatus() {
+				if st.State == stream.StateConnected {
+					continue
+				}
+				logx.Errorf("[%s]: stream %s, filters: %v, last message: %v, reconnects: %d, err: %v",
+					st.Target, st.State, st.Filters, st.LastMessage.Format(time.RFC3339), st.Reconnects, st.LastError)
+			}
 		}
-		if k == "smart" && v {
-			p.Go(func() {
-				p.workerForSmart()
-			})
+	}
+}
+
+// watchWallets 定期输出每个钱包的余额、持仓和盈亏
+func (p *PumpFunMonitor) watchWallets() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.wallets.Report()
 		}
-		if k == "scm" && v {
-			p.Go(func() {
-				p.workerForScm()
-			})
+	}
+}
+
+// watchRetract 跟单的源交易所在 slot 被回滚时发出 retracted 通知：
+// 还没买入的由 BuyBefore 放弃，已买入的由 ListenSell 清仓。
+// 只有收到 SLOT_DEAD 才直接判定回滚；slot 只是没等到 finalized 更新时先查源交易的签名状态
+func (p *PumpFunMonitor) watchRetract(ts *TokenSwap) {
+	var status stream.SlotCommitment
+	select {
+	case <-ts.Ctx.Done():
+		return
+	case status = <-p.hub.Slots().Watch(ts.Slot):
+	}
+	switch status {
+	case stream.SlotRolledBack:
+	case stream.SlotMissing:
+		if !p.sourceTxGone(ts) {
+			return
 		}
+	default:
+		return
 	}
 
-	go p.Profit()
+	ts.Retracted.Store(true)
+	logx.Errorf("[%s]:源交易 %s 所在 slot %d 已回滚", ts.Token.TokenAddress, ts.BundleTx, ts.Slot)
+	select {
+	case ts.Cmd <- "retracted":
+	case <-ts.Ctx.Done():
+	}
+}
+
+// sourceTxGone 源交易在链上查不到时返回 true；查询失败时按仍在链上处理，不因此清仓
+func (p *PumpFunMonitor) sourceTxGone(ts *TokenSwap) bool {
+	sig, err := solana.SignatureFromBase58(ts.BundleTx)
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ts.Ctx, 5*time.Second)
+	defer cancel()
+	statuses, err := p.httpClient.GetSignatureStatuses(ctx, true, sig)
+	if err != nil {
+		logx.Errorf("[%s]:查询源交易 %s 状态失败: %v", ts.Token.TokenAddress, ts.BundleTx, err)
+		return false
+	}
+	if len(statuses.Value) > 0 && statuses.Value[0] != nil {
+		logx.Infof("[%s]:slot %d 没等到 finalized 更新，源交易 %s 仍在链上", ts.Token.TokenAddress, ts.Slot, ts.BundleTx)
+		return false
+	}
+	return true
+}
+
+// ChannelReport 各发送通道的出价模型和落地统计
+func (p *PumpFunMonitor) ChannelReport() []rpcs.BidReport {
+	return p.bidder.Report()
+}
+
+// StreamReport 各 Geyser 来源本统计周期的抢先率和延迟，不清零
+func (p *PumpFunMonitor) StreamReport() []stream.SourceReport {
+	return p.hub.FanIn().Report(false)
 }
 
 func (p *PumpFunMonitor) Stop() {
@@ -149,6 +401,12 @@ func (p *PumpFunMonitor) Stop() {
 
 	p.Wg.Wait()
 
+	if p.recorder != nil {
+		p.hub.SetRecorder(nil)
+		if err := p.recorder.Close(); err != nil {
+			logx.Errorf("close recorder error: %v", err)
+		}
+	}
 }
 
 func (p *PumpFunMonitor) runWithCtx(ctx context.Context, ch <-chan interface{}, handler func(interface{})) {
@@ -177,17 +435,20 @@ func (p *PumpFunMonitor) runWithCtx(ctx context.Context, ch <-chan interface{},
 	})
 }
 
-func (p *PumpFunMonitor) BurnToken(tokenAddress string) {
+func (p *PumpFunMonitor) BurnToken(w *wallets.Wallet, tokenAddress string) {
+	if p.paper != nil {
+		return
+	}
 	mint := solana.MustPublicKeyFromBase58(tokenAddress)
 	// 计算Associated Token Account的地址
-	tokenAccount, _, err := solana.FindAssociatedTokenAddress(p.wallet.PublicKey(), mint)
+	tokenAccount, _, err := solana.FindAssociatedTokenAddress(w.PublicKey(), mint)
 	if err != nil {
 		logx.Errorf("计算Associated Token Account地址失败: %v", err)
 		return
 	}
 
 	rpcClient := global.GetRPCForRequest()
-	big, _ := pump.GetTokenBalance(rpcClient, p.wallet.PublicKey(), mint)
+	big, _ := pump.GetTokenBalance(rpcClient, w.PublicKey(), mint)
 	tokens := []struct {
 		Mint         solana.PublicKey
 		TokenAccount solana.PublicKey
@@ -200,7 +461,7 @@ func (p *PumpFunMonitor) BurnToken(tokenAddress string) {
 		},
 	}
 
-	txHash, err := client.BatchBurnAndClose(rpcClient, p.wallet.PrivateKey, tokens)
+	txHash, err := client.BatchBurnAndClose(rpcClient, w, tokens)
 	if err != nil {
 		logx.Errorf("批量销毁和关闭交易失败: %v", err)
 		return
@@ -209,22 +470,54 @@ func (p *PumpFunMonitor) BurnToken(tokenAddress string) {
 
 }
 
-func (p *PumpFunMonitor) SendAndWait2(token string, tip uint64, txBuilder *global.TxBuilder) (*rpc.GetTransactionResult, error) {
+// SendAndWait2 通过 ts 所属模式在 side 方向配置的通道并发发送，tip 为 0 时每个通道按竞价模型出价；
+// 各通道的交易用同一个 nonce，最多只有一笔能落地：有通道落地时其余通道不计入竞价模型，
+// 都没落地时才记为失败。落地用的 slot 数从源交易所在的 ts.Slot 算起
+func (p *PumpFunMonitor) SendAndWait2(ts *TokenSwap, side string, tip uint64, txBuilder *global.TxBuilder) (*rpc.GetTransactionResult, error) {
+	token := ts.Token.TokenAddress
+	channels := p.channels.Route(ts.Mode, side)
+
+	// 发送前模拟：按实际消耗收紧 CU 上限，模拟出错的交易不发送
+	if p.preflight != nil {
+		if err := p.preflight.Run(p.ctx, poolTypeName(ts.SwapType.Load()), txBuilder); err != nil {
+			return nil, fmt.Errorf("[%s]: preflight: %w", token, err)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 	defer cancel()
 
-	resultChan := make(chan *rpc.GetTransactionResult, len(p.rpcs))
+	resultChan := make(chan *rpc.GetTransactionResult, len(channels))
 	done := make(chan struct{}, 1)
+	startSlot := ts.Slot
+	if startSlot == 0 {
+		// 没有源交易（如清仓、持有到期）时从发送时算起
+		startSlot = global.GetSlot()
+	}
+
+	type miss struct {
+		channel string
+		tip     uint64
+	}
+	var (
+		landed   atomic_.Bool
+		missesMu sync.Mutex
+		misses   []miss
+	)
 
 	var wg sync.WaitGroup
-	for _, r := range p.rpcs {
+	for _, r := range channels {
 		r := r
 		txBuilderCopy := *txBuilder
+		channelTip := tip
+		if channelTip == 0 {
+			channelTip = p.bidder.Bid(r.String(), fee.Tip())
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 
-			sig, err := r.SendTransaction(p.wallet.PrivateKey, tip, txBuilderCopy)
+			sig, err := r.SendTransaction(ts.Wallet, channelTip, txBuilderCopy)
 			if err != nil {
 				logx.Errorf("[%s]: {%s} SendTransaction error:%v", token, sig, err)
 				select {
@@ -234,8 +527,11 @@ func (p *PumpFunMonitor) SendAndWait2(token string, tip uint64, txBuilder *globa
 				return
 			}
 
-			resp, err := client.WaitForTransaction(ctx, p.httpClient, nil, sig)
+			resp, err := p.confirms.Wait(ctx, sig)
 			if err != nil {
+				missesMu.Lock()
+				misses = append(misses, miss{r.String(), channelTip})
+				missesMu.Unlock()
 				logx.Errorf("[%s]: {%s} SendAndWait error:%v", token, sig, err)
 				select {
 				case resultChan <- nil:
@@ -243,7 +539,13 @@ func (p *PumpFunMonitor) SendAndWait2(token string, tip uint64, txBuilder *globa
 				}
 				return
 			} else {
-				logx.Infof("[%s]: {%s} success", token, sig)
+				landed.Store(true)
+				var slots uint64
+				if resp.Slot > startSlot {
+					slots = resp.Slot - startSlot
+				}
+				p.bidder.Record(r.String(), channelTip, true, slots)
+				logx.Infof("[%s]: {%s} success via %s, tip %d, %d slots", token, sig, r, channelTip, slots)
 			}
 
 			select {
@@ -258,17 +560,34 @@ func (p *PumpFunMonitor) SendAndWait2(token string, tip uint64, txBuilder *globa
 		}()
 	}
 
-	// 另起一个 goroutine 等待所有发送结束后关闭 resultChan
+	// 另起一个 goroutine 等待所有发送结束后关闭 resultChan；
+	// 等待被取消或没等到的通道，只有在所有通道都没落地时才记为失败
 	go func() {
 		wg.Wait()
 		close(resultChan)
+		if landed.Load() {
+			return
+		}
+		for _, m := range misses {
+			p.bidder.Record(m.channel, m.tip, false, 0)
+		}
 	}()
 
 	// 读取第一个非 nil 结果并返回
 	for resp := range resultChan {
 		if resp != nil {
 			if resp.Meta != nil && resp.Meta.Err != nil {
-				return nil, fmt.Errorf("SendAndWait error:%v", resp.Meta.Err)
+				programs := txerr.Programs(txBuilder.Instructions())
+				if trade := txBuilder.Trade(); trade != nil {
+					// paper 成交没有指令，错误按成交附带的程序解析
+					programs = trade.Programs()
+				}
+				err := txerr.Decode(resp.Meta.Err, programs)
+				if p.preflight != nil && txerr.IsComputeExceeded(err) {
+					// 学到的上限不够用了，下次重新模拟
+					p.preflight.Forget(preflight.Key(poolTypeName(ts.SwapType.Load()), txBuilder))
+				}
+				return nil, fmt.Errorf("SendAndWait error: %w", err)
 			}
 			return resp, nil
 		}
@@ -278,6 +597,9 @@ func (p *PumpFunMonitor) SendAndWait2(token string, tip uint64, txBuilder *globa
 }
 
 func (p *PumpFunMonitor) SendAndWait(tx *solana.Transaction, skipPreflight bool) (*rpc.GetTransactionResult, error) {
+	if p.paper != nil {
+		return nil, ErrPaperBroadcast
+	}
 	txHash := tx.Signatures[0].String()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
@@ -285,7 +607,7 @@ func (p *PumpFunMonitor) SendAndWait(tx *solana.Transaction, skipPreflight bool)
 	txResp := make(chan *rpc.GetTransactionResult)
 	go func() {
 		// rpcClient, wsClient := global.GetWSRPCForRequest()
-		resp, err := client.WaitForTransaction(ctx, p.httpClient, nil, txHash)
+		resp, err := p.confirms.Wait(ctx, txHash)
 		if err != nil {
 			logx.Errorf("[%s]:SendAndWait error:%v", txHash, err)
 		} else if resp.Meta.Err != nil {
@@ -308,7 +630,7 @@ func (p *PumpFunMonitor) SendAndWait(tx *solana.Transaction, skipPreflight bool)
 		return nil, fmt.Errorf("SendAndWait error: resp is nil")
 	}
 	if resp.Meta != nil && resp.Meta.Err != nil {
-		return nil, fmt.Errorf("SendAndWait error:%v", resp.Meta.Err)
+		return nil, fmt.Errorf("SendAndWait error: %w", txerr.Decode(resp.Meta.Err, txerr.TxPrograms(tx)))
 	}
 
 	return resp, nil
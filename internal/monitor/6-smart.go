@@ -1,18 +1,16 @@
 package monitor
 
 import (
-	"context"
 	"math/big"
-	"solana-bot/internal/global"
-	"solana-bot/internal/stream"
-	"sync"
 	"time"
 
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
 	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
 
 	"slices"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -25,169 +23,107 @@ var (
 	USDC = solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
 )
 
-func (p *PumpFunMonitor) workerForSmart() {
-	p.ListenSmartAccountTransation()
+func init() {
+	RegisterStrategy(&smartStrategy{})
 }
 
-func (p *PumpFunMonitor) ListenSmartAccountTransation() {
-
-	logx.Infof("[%s]:监听账户交易", "all")
-	subscribe := make(chan interface{})
-	var subscription pb.SubscribeRequest
-	commitment := pb.CommitmentLevel_PROCESSED
-	subscription.Commitment = &commitment
-	subscription.Transactions = make(map[string]*pb.SubscribeRequestFilterTransactions)
-	failed := false
-	vote := false
-	subscription.Transactions["transactions_sub"] = &pb.SubscribeRequestFilterTransactions{
-		Failed: &failed,
-		Vote:   &vote,
-	}
+// smartStrategy 跟买 config/smart_addresses.yaml 里聪明钱包的买入
+type smartStrategy struct {
+	BaseStrategy
+}
+
+func (*smartStrategy) Name() string {
+	return "smart"
+}
+
+func (*smartStrategy) Filters() *pb.SubscribeRequest {
+	return txSubscription(smartKeys()...)
+}
 
+func smartKeys() []string {
 	smartKey := make([]string, 0)
 	for k := range GetSmartAddresses() {
 		smartKey = append(smartKey, k)
 	}
+	return smartKey
+}
 
-	subscription.Transactions["transactions_sub"].AccountInclude = smartKey
-	// subscription.Transactions["transactions_sub"].AccountExclude = transactionsAccountsExclude
-	// go client.Grpc_subscribe(p.grpcClient, &subscription, p.ctx, subscribe)
-
-	var once sync.Once
-	for _, s := range p.streams {
-		s.Subscribe(p.ctx, &subscription, &once, subscribe)
+func (*smartStrategy) OnSwap(p *PumpFunMonitor, tx *pb.SubscribeUpdateTransaction, swapInfo *solanaswapgo.SwapInfo) *TokenSwap {
+	hour := time.Now().Hour()
+	params := GetStrategyParamsByHour(hour)
+	if !params.SmartStart {
+		return nil
 	}
 
-	p.runWithCtx(context.Background(), subscribe, func(msg interface{}) {
-
-		hour := time.Now().Hour()
-		params := GetStrategyParamsByHour(hour)
-		if !params.SmartStart {
-			return
-		}
-		// 检查消息是否包含来源信息
-		var got *pb.SubscribeUpdate
-		v := msg.(*stream.StreamMessage)
-		got = v.Data.(*pb.SubscribeUpdate)
-
-		tx := got.GetTransaction()
-		if tx == nil {
-			return
-		}
-
-		if global.Sol_Balance.Load() != 0 && global.Sol_Balance.Load() < 5e8 {
-			return
-		}
-
-		// logx.Infof("收到交易{%s}", solana.SignatureFromBytes(tx.Transaction.Signature).String())
-
-		swapInfo, err := ParseSwapTransaction(tx.Transaction.Transaction, tx.Transaction.Meta)
-		if err != nil || swapInfo == nil {
-			return
-		}
-
-		smart := GetSmartAddresses()[swapInfo.Signers[0].String()]
-		logx.Infof("[%s]:收到[%s]的交易{%s}", swapInfo.TokenOutMint, smart, solana.SignatureFromBytes(tx.Transaction.Signature).String())
-		if smart == "" {
-			return
-		}
-
-		smartBuyAmount := swapInfo.TokenInAmount
-
-		//不是买入
-		if !slices.Contains(QuoteMint, swapInfo.TokenInMint.String()) {
-			return
-		}
+	smart := GetSmartAddresses()[swapInfo.Signers[0].String()]
+	logx.Infof("[%s]:收到[%s]的交易{%s}", swapInfo.TokenOutMint, smart, solana.SignatureFromBytes(tx.Transaction.Signature).String())
+	if smart == "" {
+		return nil
+	}
 
-		// 排除小额交易
-		if swapInfo.TokenInMint.Equals(solana.WrappedSol) && smartBuyAmount < 1e9 {
-			return
-		}
-		if swapInfo.TokenInMint.Equals(USDC) {
-			if smartBuyAmount < 100e6 {
-				return
-			}
-		}
+	smartBuyAmount := swapInfo.TokenInAmount
 
-		if swapInfo.PoolData == nil {
-			return
-		}
+	//不是买入
+	if !slices.Contains(QuoteMint, swapInfo.TokenInMint.String()) {
+		return nil
+	}
 
-		if _, b := BuyCache.Get(swapInfo.TokenOutMint.String()); b {
-			logx.Infof("[%s]:BackRun交易已存在", swapInfo.TokenOutMint.String())
-			return
+	// 排除小额交易
+	if swapInfo.TokenInMint.Equals(solana.WrappedSol) && smartBuyAmount < 1e9 {
+		return nil
+	}
+	if swapInfo.TokenInMint.Equals(USDC) {
+		if smartBuyAmount < 100e6 {
+			return nil
 		}
+	}
 
-		ata, _, _ := solana.FindAssociatedTokenAddress(p.wallet.PublicKey(), swapInfo.TokenOutMint)
-		ts := NewTokenSwap(false, swapInfo.Signatures[0].String(), swapInfo.TokenOutMint.String(), smartKey, ata.String(), swapInfo.PoolData)
-		ts.Tracked.InToken = swapInfo.TokenInMint.String()
-		ts.Tracked.BuyAmount = big.NewInt(int64(smartBuyAmount))
-		ts.Tracked.RemainingAmount.Store(big.NewInt(int64(swapInfo.TokenOutAmount)))
-
-		go p.SmartBackRun(ts, tx.Slot, swapInfo.Signers[0].String())
-
-	})
-
-}
-
-func (p *PumpFunMonitor) SmartBackRun(ts *TokenSwap, slot uint64, smart string) {
-
-	devBuyAmount := ts.Tracked.BuyAmount.Uint64()
-	smartInToken := ts.Tracked.InToken
-	tokenAddress := ts.Token.TokenAddress
-	logx.Infof("[%s]:BackRun Smart 交易 ", tokenAddress)
-
-	// err := mcapCheck(ts)
-	// if err != nil {
-	// 	logx.Errorf("[%s]:mcapCheck err:%v", tokenAddress, err)
-	// 	return
-	// }
-
-	// err = holderCheck(tokenAddress, bondingCurveData.BondingCurvePk.String())
-	// if err != nil {
-	// 	logx.Errorf("[%s]:holderCheck err:%v", tokenAddress, err)
-	// 	return
-	// }
+	if swapInfo.PoolData == nil {
+		return nil
+	}
 
-	solAmount := float64(devBuyAmount) / 1e9
-	holdDuration := calculateHoldDuration(solAmount, NegativeCurve)
+	if _, b := BuyCache.Get(swapInfo.TokenOutMint.String()); b {
+		logx.Infof("[%s]:BackRun交易已存在", swapInfo.TokenOutMint.String())
+		return nil
+	}
 
-	err := p.BuyBefore(ts)
+	// 按配置的方式选择交易钱包，余额不足的钱包不参与
+	wallet, err := p.wallets.Pick("smart", swapInfo.TokenOutMint.String())
 	if err != nil {
-		logx.Errorf("[%s]:买入校验失败: %v", tokenAddress, err)
-		p.BuyError(ts)
-		return
+		logx.Infof("[%s]:%v，跳过", swapInfo.TokenOutMint, err)
+		return nil
 	}
+	ata, _, _ := solana.FindAssociatedTokenAddress(wallet.PublicKey(), swapInfo.TokenOutMint)
+	ts := NewTokenSwap(p.hub, false, swapInfo.Signatures[0].String(), swapInfo.TokenOutMint.String(), smartKeys(), ata.String(), swapInfo.PoolData)
+	ts.Tracked.InToken = swapInfo.TokenInMint.String()
+	ts.Tracked.BuyAmount = big.NewInt(int64(smartBuyAmount))
+	ts.Tracked.RemainingAmount.Store(big.NewInt(int64(swapInfo.TokenOutAmount)))
+	ts.Wallet = wallet
+	ts.Slot = tx.Slot
+	return ts
+}
 
+// Entry 按聪明钱包的买入金额跟买，USDC 买入固定 50 USDC
+func (*smartStrategy) Entry(p *PumpFunMonitor, ts *TokenSwap) (*Order, error) {
 	slippage := 0.00002
 
-	buyAmount := calculateDynamicBuyAmount(devBuyAmount)
+	buyAmount := calculateDynamicBuyAmount(ts.Tracked.BuyAmount.Uint64())
 
-	if smartInToken == USDC.String() {
+	if ts.Tracked.InToken == USDC.String() {
 		buyAmount = big.NewFloat(float64(50 * 1e6))
 	}
+	return &Order{Amount: buyAmount, Slippage: float32(slippage * 100)}, nil
+}
 
-	resp, err := p.buyToken(ts, buyAmount, float32(slippage*100))
-	if err != nil {
-		logx.Errorf("[%s] 买入失败,err:%v", ts.Token.TokenAddress, err)
-		p.BuyError(ts)
-		return
-	}
-
-	p.BuyDone(ts, resp)
-
-	// if smart == "DfMxre4cKmvogbLrPigxmibVTTQDuzjdXojWzjCXXhzj" {
-	// 	holdDuration = 400 * time.Millisecond
-	// }
-
-	// if resp.Slot-slot > 2 {
-	// 	holdDuration = 1 * time.Millisecond
-	// }
+// Exit 按配置的退出规则止盈止损，持有一段时间后卖出，同时监听提前卖出
+func (*smartStrategy) Exit(p *PumpFunMonitor, ts *TokenSwap, order *Order, resp *rpc.GetTransactionResult) {
+	solAmount := float64(ts.Tracked.BuyAmount.Uint64()) / 1e9
+	holdDuration := calculateHoldDuration(solAmount, NegativeCurve)
 
-	logx.Infof("[%s]:将持有 %v 秒 后自动卖出", tokenAddress, holdDuration.Seconds())
+	logx.Infof("[%s]:将持有 %v 秒 后自动卖出", ts.Token.TokenAddress, holdDuration.Seconds())
 
+	p.armExit(ts, newExitState(ts))
 	p.normalBackRun(ts, holdDuration)
-
 }
 
 func (p *PumpFunMonitor) normalBackRun(token *TokenSwap, holdDuration time.Duration) {
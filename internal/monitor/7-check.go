@@ -31,11 +31,13 @@ func holderCheck(ts *TokenSwap) error {
 	var pool *solana.PublicKey
 	switch ts.SwapType.Load() {
 	case PumpFunType:
-		data := ts.Token.PoolData.Data.(*solanaswapgo.PumpFunPool)
-		pool = &data.AssociatedBondingCurve
+		if data, err := loadPool[*solanaswapgo.PumpFunPool](ts); err == nil {
+			pool = &data.AssociatedBondingCurve
+		}
 	case PumpAmmType:
-		data := ts.Token.PoolData.Data.(*solanaswapgo.PumpAmmPool)
-		pool = &data.PoolQuoteTokenAccount
+		if data, err := loadPool[*solanaswapgo.PumpAmmPool](ts); err == nil {
+			pool = &data.PoolQuoteTokenAccount
+		}
 	}
 	if pool == nil {
 		return nil
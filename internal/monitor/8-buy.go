@@ -1,13 +1,16 @@
 package monitor
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"solana-bot/internal/client"
+	"solana-bot/internal/fee"
 	"solana-bot/internal/global"
 	atomic_ "solana-bot/internal/global/utils/atomic"
+	"solana-bot/internal/rpcs"
 	"solana-bot/internal/shot"
 	"time"
 
@@ -31,6 +34,10 @@ func (p *PumpFunMonitor) BuyBefore(ts *TokenSwap) error {
 		return fmt.Errorf("当前买入数量超过限制，跳过")
 	}
 
+	if ts.Retracted.Load() {
+		return fmt.Errorf("源交易所在 slot %d 已回滚，跳过", ts.Slot)
+	}
+
 	cfg, err := GetMintConfig()
 	if err == nil && cfg != nil && len(cfg.Blacklist) > 0 {
 		if global.Contains(cfg.Blacklist, ts.Tracked.TrackedAddress[0]) {
@@ -94,10 +101,14 @@ func (p *PumpFunMonitor) BuyDone(ts *TokenSwap, resp *rpc.GetTransactionResult)
 	ts.MySwap.BuyAmount.Store(amount)
 	ts.MySwap.RemainingAmount.Store(amount)
 	ts.MySwap.BuyBalanceChange.Store(big.NewFloat(costSol))
+	if costSol < 0 {
+		ts.Wallet.Bought(ts.Token.TokenAddress, uint64(-costSol))
+	}
 	ts.Token.TokenPrice.Store(big.NewFloat(buyPrice))
 
 	// 真正买入完成后更新最后买入时间
 	p.lastBuyTime.Store(ts.Token.TokenAddress, time.Now())
+	savePosition(ts, resp)
 
 	data, _ := json.MarshalIndent(ts, "", "  ")
 	fmt.Println(string(data))
@@ -106,6 +117,8 @@ func (p *PumpFunMonitor) BuyDone(ts *TokenSwap, resp *rpc.GetTransactionResult)
 func (p *PumpFunMonitor) BuyError(ts *TokenSwap) {
 	ts.Cancel()
 	buyCount.Decrement()
+	ts.Wallet.Close(ts.Token.TokenAddress, 0)
+	releaseRisk(ts)
 
 	p.lastBuyTime.Delete(ts.Token.TokenAddress)
 }
@@ -118,6 +131,12 @@ func (p *PumpFunMonitor) buyToken(ts *TokenSwap, maxAmountIn *big.Float, slippag
 
 	in, _ := maxAmountIn.Float64()
 
+	// 按放大后的实际投入检查风控
+	amountIn, _ := new(big.Float).Mul(maxAmountIn, global.Float1Lamp).Uint64()
+	if err := checkRisk(ts, amountIn); err != nil {
+		return nil, fmt.Errorf("[%s]:风控拒绝: %w", ts.Token.TokenAddress, err)
+	}
+
 	Profit.lastInvestmentAmount.Store(in)
 
 	select {
@@ -126,6 +145,9 @@ func (p *PumpFunMonitor) buyToken(ts *TokenSwap, maxAmountIn *big.Float, slippag
 	case <-ts.Cmd:
 		return nil, fmt.Errorf("[%s]:交易取消", ts.Token.TokenAddress)
 	default:
+		if p.paper != nil {
+			return p.paperSwap(ts, rpcs.Buy, amountIn, slippage)
+		}
 		switch ts.SwapType.Load() {
 		case PumpFunType:
 			return p.buyWithFun(ts, maxAmountIn, slippage)
@@ -146,29 +168,35 @@ func (p *PumpFunMonitor) buyWithFun(ts *TokenSwap, maxAmountIn *big.Float, slipp
 	mintAddress := ts.Token.TokenAddress
 	tokenMint := solana.MustPublicKeyFromBase58(mintAddress)
 
-	priorityFee := global.GetMedium()
-	// fee := uint64(1e6) // 基础费用自动扣除
-	tip := global.GetHigh()
-	if tip > uint64(1e6) {
-		tip = uint64(1e6)
-	}
-	if tip < uint64(1e6) {
-		tip = uint64(1e6)
-	}
-
 	amountIn, _ := new(big.Float).Mul(maxAmountIn, global.Float1Lamp).Uint64()
 
 	bondingCurveData := ts.GetBondingCurveData()
 	if bondingCurveData == nil {
 		return nil, errors.New("bondingCurveData is nil")
 	}
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.PumpFunPool)
+	poolData, err := loadPool[*solanaswapgo.PumpFunPool](ts)
+	if err != nil {
+		return nil, err
+	}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonce, err := p.leaseNonce(ts)
+	if err != nil {
+		return nil, err
+	}
+	defer nonce.Release()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	punpFunAdapter := shot.NewPumpFunAdapter()
+	accounts := []solana.PublicKey{
+		nonceAccount,
+		poolData.CreatorVault,
+		poolData.Global,
+		poolData.BondingCurve,
+		poolData.AssociatedBondingCurve,
+	}
+	priorityFee := shot.PriorityFee(punpFunAdapter, fee.Medium, accounts...)
 	buyIns := punpFunAdapter.BuildInstructions(
 		&shot.TxContext{
-			SignerAndOwner:       p.wallet.PrivateKey,
+			SignerAndOwner:       ts.Wallet,
 			SrcMint:              solana.WrappedSol,
 			DstMint:              tokenMint,
 			VirtualSolReserves:   big.NewInt(int64(poolData.VirtualSolReserves)),
@@ -178,40 +206,46 @@ func (p *PumpFunMonitor) buyWithFun(ts *TokenSwap, maxAmountIn *big.Float, slipp
 			PriorityFee:          priorityFee,
 			Fee:                  0,
 		},
-		nonceAccount,
-		poolData.CreatorVault,
-		poolData.Global,
-		poolData.BondingCurve,
-		poolData.AssociatedBondingCurve,
+		accounts...,
 	)
 
-	txBuilder := global.NewTxBuilder(p.wallet.PublicKey(), nonceHash)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), nonceHash)
 	txBuilder.AddInstruction(buyIns...)
 
-	return p.SendAndWait2(mintAddress, tip, txBuilder)
+	return p.SendAndWait2(ts, rpcs.Buy, 0, txBuilder)
 }
 
 func (p *PumpFunMonitor) buyWithAmm(ts *TokenSwap, maxAmountIn *big.Float, slippage float32) (*rpc.GetTransactionResult, error) {
 
-	priorityFee := global.GetMedium()
-	// fee := uint64(1e6) // 基础费用自动扣除
-	tip := global.GetHigh()
-	if tip > uint64(1e6) {
-		tip = uint64(1e6)
-	}
-	if tip < uint64(1e6) {
-		tip = uint64(1e6)
-	}
-
 	amountIn, _ := new(big.Float).Mul(maxAmountIn, global.Float1Lamp).Uint64()
 
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.PumpAmmPool)
+	poolData, err := loadPool[*solanaswapgo.PumpAmmPool](ts)
+	if err != nil {
+		return nil, err
+	}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonce, err := p.leaseNonce(ts)
+	if err != nil {
+		return nil, err
+	}
+	defer nonce.Release()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	adapter := shot.NewPumpAmmAdapter()
+	accounts := []solana.PublicKey{
+		nonceAccount,
+		poolData.Pool,
+		poolData.GlobalConfig,
+		poolData.PoolBaseTokenAccount,
+		poolData.PoolQuoteTokenAccount,
+		poolData.ProtocolFeeRecipient,
+		poolData.ProtocolFeeRecipientTokenAccount,
+		poolData.CoinCreatorVaultAta,
+		poolData.CoinCreatorVaultAuthority,
+	}
+	priorityFee := shot.PriorityFee(adapter, fee.Medium, accounts...)
 	buyIns := adapter.BuildInstructions(
 		&shot.TxContext{
-			SignerAndOwner:       p.wallet.PrivateKey,
+			SignerAndOwner:       ts.Wallet,
 			SrcMint:              poolData.QuoteMint,
 			DstMint:              poolData.BaseMint,
 			VirtualSolReserves:   big.NewInt(int64(poolData.PoolBaseTokenReserves)),
@@ -221,21 +255,13 @@ func (p *PumpFunMonitor) buyWithAmm(ts *TokenSwap, maxAmountIn *big.Float, slipp
 			PriorityFee:          priorityFee,
 			Fee:                  0,
 		},
-		nonceAccount,
-		poolData.Pool,
-		poolData.GlobalConfig,
-		poolData.PoolBaseTokenAccount,
-		poolData.PoolQuoteTokenAccount,
-		poolData.ProtocolFeeRecipient,
-		poolData.ProtocolFeeRecipientTokenAccount,
-		poolData.CoinCreatorVaultAta,
-		poolData.CoinCreatorVaultAuthority,
+		accounts...,
 	)
 
-	txBuilder := global.NewTxBuilder(p.wallet.PublicKey(), nonceHash)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), nonceHash)
 	txBuilder.AddInstruction(buyIns...)
 
-	return p.SendAndWait2(ts.Token.TokenAddress, tip, txBuilder)
+	return p.SendAndWait2(ts, rpcs.Buy, 0, txBuilder)
 }
 
 func (p *PumpFunMonitor) buyWithJupiter(ts *TokenSwap, maxAmountIn *big.Float, slippage float32) (*rpc.GetTransactionResult, error) {
@@ -254,7 +280,7 @@ func (p *PumpFunMonitor) buyWithJupiter(ts *TokenSwap, maxAmountIn *big.Float, s
 		return nil, err
 	}
 
-	r, err := client.SwapInstructions(q, p.wallet.PublicKey().String())
+	r, err := client.SwapInstructions(q, ts.Wallet.PublicKey().String())
 	if err != nil {
 		logx.Errorf("Error creating swap transaction: %s", err)
 		return nil, err
@@ -278,26 +304,21 @@ func (p *PumpFunMonitor) buyWithJupiter(ts *TokenSwap, maxAmountIn *big.Float, s
 	// Add cleanup instruction if present
 	// instructions = append(instructions, createTransactionInstruction(*r.CleanupInstruction))
 
-	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, p.wallet.PublicKey(), BribeAccount).Build())
+	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, ts.Wallet.PublicKey(), BribeAccount).Build())
 
 	blockHash := global.GetBlockHash()
 	// Create the transaction with all instructions
 	tx, err := solana.NewTransaction(
 		instructions,
 		blockHash,
-		solana.TransactionPayer(p.wallet.PublicKey()),
+		solana.TransactionPayer(ts.Wallet.PublicKey()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %v", err)
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &p.wallet.PrivateKey
-		},
-	)
-	if err != nil {
+	if err := ts.Wallet.SignTransaction(tx); err != nil {
 		logx.Errorf("Error signing transaction: %v", err)
 		return nil, err
 	}
@@ -311,26 +332,34 @@ func (p *PumpFunMonitor) buyWithJupiter(ts *TokenSwap, maxAmountIn *big.Float, s
 }
 
 func (p *PumpFunMonitor) buyWithMeteoraDbc(ts *TokenSwap, maxAmountIn *big.Float, slippage float32) (*rpc.GetTransactionResult, error) {
-	priorityFee := global.GetMedium()
-	// fee := uint64(1e6) // 基础费用自动扣除
-	tip := global.GetHigh()
-	if tip > uint64(1e6) {
-		tip = uint64(1e6)
-	}
-	if tip < uint64(1e6) {
-		tip = uint64(1e6)
-	} // 0.001 SOL（如果使用 Jito）
-
 	amountIn, _ := new(big.Float).Mul(maxAmountIn, global.Float1Lamp).Uint64()
 
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.MeteoraDbcPool)
+	poolData, err := loadPool[*solanaswapgo.MeteoraDbcPool](ts)
+	if err != nil {
+		return nil, err
+	}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonce, err := p.leaseNonce(ts)
+	if err != nil {
+		return nil, err
+	}
+	defer nonce.Release()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	adapter := shot.NewMDbcAdapter()
 
+	accounts := []solana.PublicKey{
+		nonceAccount,
+		poolData.Config,
+		poolData.Pool,
+		poolData.BaseVault,
+		poolData.QuoteVault,
+		poolData.TokenBaseProgram,
+		poolData.TokenQuoteProgram,
+	}
+	priorityFee := shot.PriorityFee(adapter, fee.Medium, accounts...)
 	buyIns := adapter.BuildInstructions(
 		&shot.TxContext{
-			SignerAndOwner: p.wallet.PrivateKey,
+			SignerAndOwner: ts.Wallet,
 			SrcMint:        poolData.QuoteMint,
 			DstMint:        poolData.BaseMint,
 			SqrtPrice:      big.NewInt(int64(poolData.NextSqrtPrice)),
@@ -339,43 +368,44 @@ func (p *PumpFunMonitor) buyWithMeteoraDbc(ts *TokenSwap, maxAmountIn *big.Float
 			PriorityFee:    priorityFee,
 			Fee:            0,
 		},
-		nonceAccount,
-		poolData.Config,
-		poolData.Pool,
-		poolData.BaseVault,
-		poolData.QuoteVault,
-		poolData.TokenBaseProgram,
-		poolData.TokenQuoteProgram,
+		accounts...,
 	)
 
-	txBuilder := global.NewTxBuilder(p.wallet.PublicKey(), nonceHash)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), nonceHash)
 	txBuilder.AddInstruction(buyIns...)
 
-	return p.SendAndWait2(ts.Token.TokenAddress, tip, txBuilder)
+	return p.SendAndWait2(ts, rpcs.Buy, 0, txBuilder)
 
 }
 
 func (p *PumpFunMonitor) buyWithRaydiumLaunchpad(ts *TokenSwap, maxAmountIn *big.Float, slippage float32) (*rpc.GetTransactionResult, error) {
 
-	priorityFee := global.GetMedium()
-	// fee := uint64(1e6) // 基础费用自动扣除
-	tip := global.GetHigh()
-	if tip > uint64(1e6) {
-		tip = uint64(1e6)
-	}
-	if tip < uint64(1e6) {
-		tip = uint64(1e6)
-	}
-
 	amountIn, _ := new(big.Float).Mul(maxAmountIn, global.Float1Lamp).Uint64()
 
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.RaydiumLaunchpadPool)
+	poolData, err := loadPool[*solanaswapgo.RaydiumLaunchpadPool](ts)
+	if err != nil {
+		return nil, err
+	}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	nonce, err := p.leaseNonce(ts)
+	if err != nil {
+		return nil, err
+	}
+	defer nonce.Release()
+	nonceAccount, nonceHash := nonce.Account, nonce.Hash
 	adapter := shot.NewBonkAdapter()
+	accounts := []solana.PublicKey{
+		nonceAccount,
+		poolData.GlobalConfig,
+		poolData.PlatformConfig,
+		poolData.PoolState,
+		poolData.BaseVault,
+		poolData.QuoteVault,
+	}
+	priorityFee := shot.PriorityFee(adapter, fee.Medium, accounts...)
 	buyIns := adapter.BuildInstructions(
 		&shot.TxContext{
-			SignerAndOwner:       p.wallet.PrivateKey,
+			SignerAndOwner:       ts.Wallet,
 			SrcMint:              poolData.QuoteMint,
 			DstMint:              poolData.BaseMint,
 			VirtualSolReserves:   big.NewInt(int64(ts.Token.PoolSolBalance.Load())),
@@ -385,16 +415,21 @@ func (p *PumpFunMonitor) buyWithRaydiumLaunchpad(ts *TokenSwap, maxAmountIn *big
 			PriorityFee:          priorityFee,
 			Fee:                  0,
 		},
-		nonceAccount,
-		poolData.GlobalConfig,
-		poolData.PlatformConfig,
-		poolData.PoolState,
-		poolData.BaseVault,
-		poolData.QuoteVault,
+		accounts...,
 	)
 
-	txBuilder := global.NewTxBuilder(p.wallet.PublicKey(), nonceHash)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), nonceHash)
 	txBuilder.AddInstruction(buyIns...)
 
-	return p.SendAndWait2(ts.Token.TokenAddress, tip, txBuilder)
+	return p.SendAndWait2(ts, rpcs.Buy, 0, txBuilder)
+}
+
+// 所有 nonce 都在使用时，买入最多等待这么久
+const nonceWait = 3 * time.Second
+
+// leaseNonce 从 ts 所用钱包的 nonce 池中为一笔买入独占一个 nonce 账户，发送结束后需要 Release
+func (p *PumpFunMonitor) leaseNonce(ts *TokenSwap) (*global.NonceLease, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, nonceWait)
+	defer cancel()
+	return ts.Wallet.Nonces.Lease(ctx)
 }
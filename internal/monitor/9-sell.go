@@ -9,15 +9,18 @@ import (
 	dex "solana-bot/internal/dex/okx"
 	"solana-bot/internal/dex/pump"
 	"solana-bot/internal/dex/raydium"
+	"solana-bot/internal/exitplan"
+	"solana-bot/internal/fee"
 	"solana-bot/internal/global"
+	"solana-bot/internal/positions"
+	"solana-bot/internal/rpcs"
 	"solana-bot/internal/shot"
 	"solana-bot/internal/stream"
+	"solana-bot/internal/txerr"
 	"strconv"
-	"sync"
 
 	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
 
-	"strings"
 	"time"
 
 	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
@@ -33,7 +36,8 @@ const (
 	maxRetries = 10 // 最大重试次数
 )
 
-func (p *PumpFunMonitor) SellDone(ts *TokenSwap, resp *rpc.GetTransactionResult) {
+// SellDone 卖出落地，rule 为触发这次卖出的规则；resp 为 nil 时持仓已经清零
+func (p *PumpFunMonitor) SellDone(ts *TokenSwap, resp *rpc.GetTransactionResult, rule string) {
 	logx.Infof("[%s]: 🈹 卖出成功!", ts.Token.TokenAddress)
 
 	go func() {
@@ -46,9 +50,14 @@ func (p *PumpFunMonitor) SellDone(ts *TokenSwap, resp *rpc.GetTransactionResult)
 				profit = profit.Add(profit, s)
 			}
 			buy := new(big.Float).Quo(ts.MySwap.BuyBalanceChange.Load(), big.NewFloat(1e9))
-			log := fmt.Sprintf("%s,%s,%s,%s", time.Now().Format(time.DateTime), ts.Token.TokenAddress, buy.String(), new(big.Float).Quo(profit, big.NewFloat(1e9)).String())
+			log := fmt.Sprintf("%s,%s,%s,%s,%s", time.Now().Format(time.DateTime), ts.Token.TokenAddress, buy.String(), new(big.Float).Quo(profit, big.NewFloat(1e9)).String(), ts.Wallet)
 			p.pubsub.Publish(log)
 
+			pnl, _ := profit.Int64()
+			ts.Wallet.Close(ts.Token.TokenAddress, pnl)
+			closeRisk(ts, pnl)
+			finishPosition(ts)
+
 			ts.Cancel()
 			buyCount.Decrement()
 		}
@@ -59,10 +68,12 @@ func (p *PumpFunMonitor) SellDone(ts *TokenSwap, resp *rpc.GetTransactionResult)
 	}
 
 	if resp == nil {
+		finishPosition(ts)
 		ts.Cancel()
 		buyCount.Decrement()
 		return
 	}
+	recordSell(ts, resp, rule)
 
 	sellPrice, _ := global.GetBuyPriceAndAmount(resp, ts.Token.TokenAddress)
 	ts.MySwap.SellPrice.Store(big.NewFloat(sellPrice))
@@ -89,10 +100,8 @@ func (p *PumpFunMonitor) ListenWatchSell(ts *TokenSwap) {
 
 	subscription.Transactions["transactions_sub"].AccountInclude = ts.Tracked.TrackedAddress
 	// subscription.Transactions["transactions_sub"].AccountExclude = transactionsAccountsExclude
-	var once sync.Once
-	for _, s := range p.streams {
-		s.Subscribe(p.ctx, &subscription, &once, subscribe)
-	}
+	// 过滤器跟着仓位走，仓位结束时从 hub 移除
+	p.hub.Subscribe(ts.Ctx, &subscription, subscribe)
 
 	p.runWithCtx(ts.Ctx, subscribe, func(msg interface{}) {
 		var got *pb.SubscribeUpdate
@@ -112,7 +121,7 @@ func (p *PumpFunMonitor) ListenWatchSell(ts *TokenSwap) {
 
 			ts.Tracked.RemainingAmount.Sub(big.NewInt(int64(swapInfo.TokenInAmount)))
 
-			err := p.ExecuteSell(ts, ts.GetRemainingAmount())
+			err := p.ExecuteSell(ts, ts.GetRemainingAmount(), exitplan.RuleLeaderExit)
 			if err != nil {
 				logx.Errorf("[%s]:跟随卖出失败: %v", tokenAddress, err)
 			} else {
@@ -144,26 +153,36 @@ func (p *PumpFunMonitor) ListenSell(ts *TokenSwap) {
 					currentPrice,
 					amountBought,
 				)
-				_ = p.ExecuteSell(ts, amountToSell)
+				_ = p.ExecuteSell(ts, amountToSell, msg)
 				continue
 			}
 
+			if msg == "retracted" {
+				logx.Infof("[%s] ↩️ 源交易已回滚，清仓", tokenAddress)
+				_ = p.ExecuteSell(ts, ts.GetRemainingAmount(), msg)
+				return
+			}
+
 			if msg == "sell-some" {
 				logx.Infof("[%s] 🌫 收到部分信号，快速卖出", tokenAddress)
 				amountBought := ts.GetRemainingAmount()
-				_ = p.ExecuteSell(ts, new(big.Int).Quo(amountBought, big.NewInt(3)))
+				_ = p.ExecuteSell(ts, new(big.Int).Quo(amountBought, big.NewInt(3)), msg)
 				continue
 			}
 
 			amount := ts.GetRemainingAmount()
 			logx.Infof("[%s]⛔ 收到停止信号，快速卖出", tokenAddress)
-			_ = p.ExecuteSell(ts, amount)
+			_ = p.ExecuteSell(ts, amount, msg)
 			return
 		default:
-			balance, _ := pump.GetTokenBalance(p.httpClient, p.wallet.PublicKey(), solana.MustPublicKeyFromBase58(tokenAddress))
+			if p.paper != nil {
+				time.Sleep(time.Second)
+				continue
+			}
+			balance, _ := pump.GetTokenBalance(p.httpClient, ts.Wallet.PublicKey(), solana.MustPublicKeyFromBase58(tokenAddress))
 			if balance != nil && balance.Cmp(big.NewInt(0)) == 0 {
 				logx.Infof("[%s]:提前卖出成功", tokenAddress)
-				p.SellDone(ts, nil)
+				p.SellDone(ts, nil, "")
 				return
 			}
 			time.Sleep(time.Second)
@@ -193,6 +212,10 @@ func (p *PumpFunMonitor) StartHoldTimer(t *TokenSwap, initialDuration time.Durat
 	holdInfoMap[tokenAddress] = info
 	holdInfoMutex.Unlock()
 
+	updateExitPlan(t, func(plan *positions.ExitPlan) {
+		plan.HoldUntil = time.Now().Add(initialDuration)
+	})
+
 	go func() {
 		defer func() {
 			holdInfoMutex.Lock()
@@ -201,7 +224,7 @@ func (p *PumpFunMonitor) StartHoldTimer(t *TokenSwap, initialDuration time.Durat
 		}()
 
 		sellStep := 0
-		totalSteps := 3
+		totalSteps := t.holdSteps()
 
 		// 初始分配每段时间
 		stepDurations := make([]time.Duration, totalSteps)
@@ -234,17 +257,10 @@ func (p *PumpFunMonitor) StartHoldTimer(t *TokenSwap, initialDuration time.Durat
 				timer.Reset(stepDurations[sellStep])
 
 			case <-timer.C:
-				var amount *big.Int
-				switch sellStep {
-				case 0:
-					amount = new(big.Int).Div(t.GetRemainingAmount(), big.NewInt(3))
-				case 1:
-					amount = new(big.Int).Div(t.GetRemainingAmount(), big.NewInt(2))
-				case 2:
-					amount = t.GetRemainingAmount()
-				}
+				// 剩余的持仓平分到剩下的批次，最后一批清仓
+				amount := new(big.Int).Div(t.GetRemainingAmount(), big.NewInt(int64(totalSteps-sellStep)))
 
-				err := p.ExecuteSell(t, amount)
+				err := p.ExecuteSell(t, amount, fmt.Sprintf("hold:%d/%d", sellStep+1, totalSteps))
 				logx.Infof("[%s]:第 %d 次卖出 %v, err: %v", tokenAddress, sellStep+1, amount, err)
 
 				sellStep++
@@ -261,12 +277,13 @@ func (p *PumpFunMonitor) StartHoldTimer(t *TokenSwap, initialDuration time.Durat
 	}()
 }
 
-func (p *PumpFunMonitor) ExecuteSell(ts *TokenSwap, amount *big.Int) error {
+// ExecuteSell 卖出 amount，失败时按错误重试；rule 为触发这次卖出的规则，记录在仓位的卖出上
+func (p *PumpFunMonitor) ExecuteSell(ts *TokenSwap, amount *big.Int, rule string) error {
 	if amount == nil || amount.Cmp(big.NewInt(0)) <= 0 {
 		return nil
 	}
 	tokenAddress := ts.Token.TokenAddress
-	logx.Infof("[%s]:开始执行卖出, 数量: %d", tokenAddress, amount.Int64())
+	logx.Infof("[%s]:开始执行卖出, 数量: %d, 规则: %s", tokenAddress, amount.Int64(), rule)
 
 	retryCount := 0
 	slippage := float32(10)
@@ -280,36 +297,28 @@ func (p *PumpFunMonitor) ExecuteSell(ts *TokenSwap, amount *big.Int) error {
 		default:
 			resp, err := p.SellToken(ts, amount, slippage)
 			if err == nil && resp != nil && resp.Meta != nil && resp.Meta.Err == nil {
-				p.SellDone(ts, resp)
+				p.SellDone(ts, resp, rule)
 				return nil
 			}
 
 			logx.Errorf("[%s] 卖出失败,err:%v", tokenAddress, err)
 
-			if strings.Contains(err.Error(), ":6023") {
+			switch sellRetryAction(err) {
+			case sellRequote:
 				amount = ts.GetRemainingAmount()
 				resp, err := p.SellToken(ts, amount, slippage)
 				if err == nil && resp != nil && resp.Meta != nil && resp.Meta.Err == nil {
-					p.SellDone(ts, resp)
+					p.SellDone(ts, resp, rule)
 					return nil
 				}
-			}
-
-			if strings.Contains(err.Error(), ":38") || strings.Contains(err.Error(), ":3012") {
-				p.SellDone(ts, resp)
+			case sellGone:
+				p.SellDone(ts, resp, rule)
 				return nil
-			}
-
-			if strings.Contains(err.Error(), ":6001") ||
-				strings.Contains(err.Error(), ":6002") ||
-				strings.Contains(err.Error(), ":6003") ||
-				strings.Contains(err.Error(), ":6004") {
+			case sellWiden:
 				// 增加50%的滑点
 				slippage += slippage * 0.5
 				continue
-			}
-
-			if strings.Contains(err.Error(), ": 429") {
+			case sellBackoff:
 				time.Sleep(2 * time.Second)
 				continue
 			}
@@ -318,7 +327,7 @@ func (p *PumpFunMonitor) ExecuteSell(ts *TokenSwap, amount *big.Int) error {
 			// 	ts.SwapType.Store(JupiterType)
 			// 	resp, err = p.sellWithJupiter(ts, amount, slippage)
 			// 	if err == nil {
-			// 		p.SellDone(ts, resp)
+			// 		p.SellDone(ts, resp, rule)
 			// 		return nil
 			// 	}
 			// }
@@ -337,12 +346,44 @@ func (p *PumpFunMonitor) ExecuteSell(ts *TokenSwap, amount *big.Int) error {
 
 	resp, err := p.sellWithJupiter(ts, amount, float32(200))
 	if err == nil {
-		p.SellDone(ts, resp)
+		p.SellDone(ts, resp, rule)
 		return nil
 	}
 	return errors.New("卖出失败")
 }
 
+// sellAction 卖出失败后的处理方式
+type sellAction int
+
+const (
+	sellRetry   sellAction = iota // 按剩余数量稍后重试
+	sellRequote                   // 卖出数量超过持仓，按剩余数量立即重试
+	sellGone                      // token 账户已经关闭，视为已经卖完
+	sellWiden                     // 滑点不够，放大滑点重试
+	sellBackoff                   // 被限流，等一会再试
+)
+
+// sellRetryAction 按出错的程序和错误码决定卖出失败后的处理，同一个错误码在不同程序里含义不同
+func sellRetryAction(err error) sellAction {
+	switch {
+	case errors.Is(err, txerr.PumpNotEnoughTokensToSell),
+		errors.Is(err, txerr.TokenInsufficientFunds),
+		errors.Is(err, txerr.Token2022InsufficientFunds):
+		return sellRequote
+	case txerr.IsAnchor(err, txerr.AnchorAccountNotInitialized):
+		return sellGone
+	case errors.Is(err, txerr.PumpTooMuchSolRequired),
+		errors.Is(err, txerr.PumpTooLittleSolReceived),
+		errors.Is(err, txerr.PumpAmmExceededSlippage),
+		errors.Is(err, txerr.DbcExceededSlippage),
+		errors.Is(err, txerr.LaunchLabExceededSlippage):
+		return sellWiden
+	case txerr.IsRateLimited(err):
+		return sellBackoff
+	}
+	return sellRetry
+}
+
 func (p *PumpFunMonitor) SellToken(ts *TokenSwap, amountIn *big.Int, slippage float32) (*rpc.GetTransactionResult, error) {
 	// 修复：避免复制包含锁的对象
 	remaining := ts.GetRemainingAmount()
@@ -351,7 +392,10 @@ func (p *PumpFunMonitor) SellToken(ts *TokenSwap, amountIn *big.Int, slippage fl
 	logx.Infof("[%s]:卖出 %v, 剩余: %v, SwapType: %d", ts.Token.TokenAddress, amountIn, newAmount, ts.SwapType.Load())
 	if remaining.Cmp(big.NewInt(0)) <= 0 {
 		logx.Infof("[%s]:卖出完成", ts.Token.TokenAddress)
-		p.SellDone(ts, nil)
+		p.SellDone(ts, nil, "")
+	}
+	if p.paper != nil {
+		return p.paperSwap(ts, rpcs.Sell, amountIn.Uint64(), slippage)
 	}
 	go func() {
 		go p.sellWithOkx(ts, amountIn, slippage)
@@ -377,19 +421,29 @@ func (p *PumpFunMonitor) sellWithFun(ts *TokenSwap, amountIn *big.Int, slippage
 	mintAddress := ts.Token.TokenAddress
 	tokenMint := solana.MustPublicKeyFromBase58(mintAddress)
 
-	priorityFee := global.GetMedium()
-
 	bondingCurveData := ts.GetBondingCurveData()
 	if bondingCurveData == nil {
 		return nil, errors.New("bondingCurveData is nil")
 	}
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.PumpFunPool)
+	poolData, err := loadPool[*solanaswapgo.PumpFunPool](ts)
+	if err != nil {
+		return nil, err
+	}
 
-	nonceAccount, nonceHash := global.GetNonceAccountAndHash()
+	// 卖出不推进 nonce，直接用最近的 blockhash，不占用 nonce 账户
+	recentHash := global.GetBlockHash()
 	punpFunAdapter := shot.NewPumpFunAdapter()
+	accounts := []solana.PublicKey{
+		{},
+		poolData.CreatorVault,
+		poolData.Global,
+		poolData.BondingCurve,
+		poolData.AssociatedBondingCurve,
+	}
+	priorityFee := shot.PriorityFee(punpFunAdapter, fee.Medium, accounts...)
 	sellIns := punpFunAdapter.BuildInstructions(
 		&shot.TxContext{
-			SignerAndOwner:       p.wallet.PrivateKey,
+			SignerAndOwner:       ts.Wallet,
 			SrcMint:              tokenMint,
 			DstMint:              solana.WrappedSol,
 			VirtualSolReserves:   big.NewInt(int64(bondingCurveData.BondingCurve.VirtualSOLReserves)),
@@ -399,17 +453,13 @@ func (p *PumpFunMonitor) sellWithFun(ts *TokenSwap, amountIn *big.Int, slippage
 			PriorityFee:          priorityFee,
 			Fee:                  0,
 		},
-		nonceAccount,
-		poolData.CreatorVault,
-		poolData.Global,
-		poolData.BondingCurve,
-		poolData.AssociatedBondingCurve,
+		accounts...,
 	)
 
-	txBuilder := global.NewTxBuilder(p.wallet.PublicKey(), nonceHash)
+	txBuilder := global.NewTxBuilder(ts.Wallet.PublicKey(), recentHash)
 	txBuilder.AddInstruction(sellIns...)
 
-	return p.SendAndWait2(mintAddress, uint64(1e5), txBuilder)
+	return p.SendAndWait2(ts, rpcs.Sell, uint64(1e5), txBuilder)
 }
 
 func (p *PumpFunMonitor) sellWithJupiter(ts *TokenSwap, maxAmountIn *big.Int, slippage float32) (*rpc.GetTransactionResult, error) {
@@ -425,7 +475,7 @@ func (p *PumpFunMonitor) sellWithJupiter(ts *TokenSwap, maxAmountIn *big.Int, sl
 		return nil, err
 	}
 
-	r, err := client.SwapInstructions(q, p.wallet.PublicKey().String())
+	r, err := client.SwapInstructions(q, ts.Wallet.PublicKey().String())
 	if err != nil {
 		logx.Errorf("Error creating swap transaction: %s", err)
 		return nil, err
@@ -449,13 +499,13 @@ func (p *PumpFunMonitor) sellWithJupiter(ts *TokenSwap, maxAmountIn *big.Int, sl
 	// Add cleanup instruction if present
 	// instructions = append(instructions, createTransactionInstruction(*r.CleanupInstruction))
 
-	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, p.wallet.PublicKey(), BribeAccount).Build())
+	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, ts.Wallet.PublicKey(), BribeAccount).Build())
 
-	ata, _, _ := solana.FindAssociatedTokenAddress(p.wallet.PublicKey(), solana.MustPublicKeyFromBase58(mintAddress))
+	ata, _, _ := solana.FindAssociatedTokenAddress(ts.Wallet.PublicKey(), solana.MustPublicKeyFromBase58(mintAddress))
 	closeInst := token_program.NewCloseAccountInstruction(
 		ata,
-		p.wallet.PublicKey(),
-		p.wallet.PublicKey(),
+		ts.Wallet.PublicKey(),
+		ts.Wallet.PublicKey(),
 		[]solana.PublicKey{},
 	).Build()
 	instructions = append(instructions, closeInst)
@@ -465,19 +515,14 @@ func (p *PumpFunMonitor) sellWithJupiter(ts *TokenSwap, maxAmountIn *big.Int, sl
 	tx, err := solana.NewTransaction(
 		instructions,
 		blockHash,
-		solana.TransactionPayer(p.wallet.PublicKey()),
+		solana.TransactionPayer(ts.Wallet.PublicKey()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %v", err)
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &p.wallet.PrivateKey
-		},
-	)
-	if err != nil {
+	if err := ts.Wallet.SignTransaction(tx); err != nil {
 		logx.Errorf("Error signing transaction: %v", err)
 		return nil, err
 	}
@@ -491,13 +536,15 @@ func (p *PumpFunMonitor) sellWithJupiter(ts *TokenSwap, maxAmountIn *big.Int, sl
 
 func (p *PumpFunMonitor) sellWithAmm(ts *TokenSwap, amountIn *big.Int, slippage float64, shouldCloseTokenAccount bool) (*rpc.GetTransactionResult, error) {
 	mintAddress := ts.Token.TokenAddress
-	priorityFee := global.GetMedium() // 0.0005 SOL
-	fee := uint64(10e4)               // 基础费用自动扣除
-	jitoTip := uint64(0)              // 0.001 SOL（如果使用 Jito）
-
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.PumpAmmPool)
+	poolData, err := loadPool[*solanaswapgo.PumpAmmPool](ts)
+	if err != nil {
+		return nil, err
+	}
+	priorityFee := fee.Estimate([]string{poolData.Pool.String(), poolData.PoolBaseTokenAccount.String(), poolData.PoolQuoteTokenAccount.String()}, fee.Medium)
+	baseFee := uint64(10e4) // 基础费用自动扣除
+	jitoTip := uint64(0)    // 0.001 SOL（如果使用 Jito）
 	sellTx, err := pump.GetPumpAMMSellTx(
-		&p.wallet.PrivateKey,
+		ts.Wallet,
 		poolData.Pool,
 		poolData.GlobalConfig,
 		poolData.BaseMint,
@@ -513,7 +560,7 @@ func (p *PumpFunMonitor) sellWithAmm(ts *TokenSwap, amountIn *big.Int, slippage
 		amountIn,
 		slippage,
 		priorityFee,
-		fee,
+		baseFee,
 		jitoTip,
 		shouldCloseTokenAccount,
 	)
@@ -531,27 +578,32 @@ func (p *PumpFunMonitor) sellWithAmm(ts *TokenSwap, amountIn *big.Int, slippage
 }
 
 func (p *PumpFunMonitor) sellWithMeteoraDbc(ts *TokenSwap, maxAmountIn *big.Int, slippage float64) (*rpc.GetTransactionResult, error) {
-	priorityFee := global.GetMedium() // 0.0005 SOL
-	fee := uint64(10e4)               // 基础费用自动扣除
-	jitoTip := uint64(0)              // 0.001 SOL（如果使用 Jito）
-
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.MeteoraDbcPool)
+	poolData, err := loadPool[*solanaswapgo.MeteoraDbcPool](ts)
+	if err != nil {
+		return nil, err
+	}
+	priorityFee := fee.Estimate([]string{poolData.Pool.String()}, fee.Medium)
+	baseFee := uint64(10e4) // 基础费用自动扣除
+	jitoTip := uint64(0)    // 0.001 SOL（如果使用 Jito）
 
-	np, _ := ts.Token.TokenPrice.Load().Int64()
+	sqrtPrice := ts.Token.SqrtPrice.Load()
+	if sqrtPrice == nil {
+		return nil, errors.New("sqrt price is nil")
+	}
 
 	buyTx, err := meteora.GetSellTx(
-		&p.wallet.PrivateKey,
+		ts.Wallet,
 		poolData.Config,
 		poolData.Pool,
 		poolData.BaseVault,
 		poolData.QuoteVault,
 		poolData.BaseMint,
 		poolData.QuoteMint,
-		big.NewInt(np),
+		sqrtPrice,
 		maxAmountIn,
 		slippage,
 		priorityFee,
-		fee,
+		baseFee,
 		jitoTip,
 	)
 
@@ -571,13 +623,15 @@ func (p *PumpFunMonitor) sellWithMeteoraDbc(ts *TokenSwap, maxAmountIn *big.Int,
 func (p *PumpFunMonitor) sellWithRaydiumLaunchpad(ts *TokenSwap, maxAmountIn *big.Int, slippage float64, shouldCloseTokenAccount bool) (*rpc.GetTransactionResult, error) {
 	mintAddress := ts.Token.TokenAddress
 
-	priorityFee := global.GetMedium() // 0.0005 SOL
-	fee := uint64(10e4)               // 基础费用自动扣除
-	jitoTip := uint64(0)              // 0.001 SOL（如果使用 Jito）
-
-	poolData := ts.Token.PoolData.Data.(*solanaswapgo.RaydiumLaunchpadPool)
+	poolData, err := loadPool[*solanaswapgo.RaydiumLaunchpadPool](ts)
+	if err != nil {
+		return nil, err
+	}
+	priorityFee := fee.Estimate([]string{poolData.PoolState.String()}, fee.Medium)
+	baseFee := uint64(10e4) // 基础费用自动扣除
+	jitoTip := uint64(0)    // 0.001 SOL（如果使用 Jito）
 	buyTx, err := raydium.GetSellTx(
-		&p.wallet.PrivateKey,
+		ts.Wallet,
 		poolData.GlobalConfig,
 		poolData.PlatformConfig,
 		poolData.PoolState,
@@ -590,7 +644,7 @@ func (p *PumpFunMonitor) sellWithRaydiumLaunchpad(ts *TokenSwap, maxAmountIn *bi
 		maxAmountIn,
 		slippage,
 		priorityFee,
-		fee,
+		baseFee,
 		jitoTip,
 		shouldCloseTokenAccount,
 	)
@@ -616,9 +670,9 @@ func (p *PumpFunMonitor) sellWithOkx(ts *TokenSwap, maxAmountIn *big.Int, slippa
 	slippageStr := strconv.FormatFloat(float64(slippage/100), 'f', 10, 64)
 
 	dexApi := dex.NewDexAPI()
-	r, err := dex.GetSolSwapInstruction(dexApi, p.wallet.PublicKey().String(), mintAddress, global.Solana, maxAmountIn.String(), slippageStr)
+	r, err := dex.GetSolSwapInstruction(dexApi, ts.Wallet.PublicKey().String(), mintAddress, global.Solana, maxAmountIn.String(), slippageStr)
 
-	// r, err := client.SwapInstructions(q, p.wallet.PublicKey().String())
+	// r, err := client.SwapInstructions(q, ts.Wallet.PublicKey().String())
 	if err != nil {
 		logx.Errorf("Error creating swap transaction: %s", err)
 		return nil, err
@@ -631,13 +685,13 @@ func (p *PumpFunMonitor) sellWithOkx(ts *TokenSwap, maxAmountIn *big.Int, slippa
 		instructions = append(instructions, createTransactionInstructionWithOkx(instruction))
 	}
 
-	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, p.wallet.PublicKey(), BribeAccount).Build())
+	instructions = append(instructions, system.NewTransferInstruction(BribeAmount, ts.Wallet.PublicKey(), BribeAccount).Build())
 
-	ata, _, _ := solana.FindAssociatedTokenAddress(p.wallet.PublicKey(), solana.MustPublicKeyFromBase58(mintAddress))
+	ata, _, _ := solana.FindAssociatedTokenAddress(ts.Wallet.PublicKey(), solana.MustPublicKeyFromBase58(mintAddress))
 	closeInst := token_program.NewCloseAccountInstruction(
 		ata,
-		p.wallet.PublicKey(),
-		p.wallet.PublicKey(),
+		ts.Wallet.PublicKey(),
+		ts.Wallet.PublicKey(),
 		[]solana.PublicKey{},
 	).Build()
 	instructions = append(instructions, closeInst)
@@ -647,19 +701,14 @@ func (p *PumpFunMonitor) sellWithOkx(ts *TokenSwap, maxAmountIn *big.Int, slippa
 	tx, err := solana.NewTransaction(
 		instructions,
 		blockHash,
-		solana.TransactionPayer(p.wallet.PublicKey()),
+		solana.TransactionPayer(ts.Wallet.PublicKey()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %v", err)
 	}
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &p.wallet.PrivateKey
-		},
-	)
-	if err != nil {
+	if err := ts.Wallet.SignTransaction(tx); err != nil {
 		logx.Errorf("Error signing transaction: %v", err)
 		return nil, err
 	}
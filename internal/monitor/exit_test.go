@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"solana-bot/internal/exitplan"
+	"solana-bot/internal/orders"
+)
+
+func TestExitRules(t *testing.T) {
+	ts := NewTokenJupiterSwap("mint")
+	ts.MySwap.RemainingAmount.Store(big.NewInt(1000))
+	ts.Tracked.RemainingAmount.Store(big.NewInt(400))
+
+	fills := make(chan exitplan.Signal, 10)
+	plan := exitplan.Plan{TakeProfit: []exitplan.TakeProfit{{Multiple: 2, Fraction: 0.5}}, LeaderExit: exitplan.LeaderExitProportional}
+	ts.exit.Store(&exitRules{
+		state: exitplan.NewState(plan, 1, 1000, time.Now()),
+		sell:  func(s exitplan.Signal) error { fills <- s; return nil },
+	})
+
+	next := func() exitplan.Signal {
+		select {
+		case s := <-fills:
+			return s
+		case <-time.After(time.Second):
+			t.Fatal("no fill")
+		}
+		return exitplan.Signal{}
+	}
+
+	ts.setPrice(big.NewFloat(1.5))
+	ts.setPrice(big.NewFloat(2.1))
+	if s := next(); s.Rule != "take_profit:2x" || s.Amount != 500 {
+		t.Fatalf("take profit = %+v", s)
+	}
+
+	// 跟随的钱包卖出四分之一
+	ts.MySwap.RemainingAmount.Store(big.NewInt(500))
+	ts.leaderExit(100)
+	if s := next(); s.Rule != exitplan.RuleLeaderExit || s.Amount != 125 {
+		t.Fatalf("leader exit = %+v", s)
+	}
+	if held := ts.Tracked.RemainingAmount.Load().Int64(); held != 300 {
+		t.Fatalf("leader held = %d", held)
+	}
+	if steps := ts.holdSteps(); steps != 3 {
+		t.Fatalf("hold steps = %d", steps)
+	}
+}
+
+func TestPriceDrivesOrders(t *testing.T) {
+	ts := NewTokenJupiterSwap("orders-mint")
+	filled := make(chan orders.Order, 1)
+	id := Orders.Place(orders.Order{Wallet: "w", Mint: "orders-mint", Kind: orders.StopLoss, Price: 0.5, Amount: 10}, func(o orders.Order) error {
+		filled <- o
+		return nil
+	})
+	defer Orders.Cancel(id, "test")
+
+	ts.setPrice(big.NewFloat(0.6))
+	ts.setPrice(big.NewFloat(0.4))
+	select {
+	case o := <-filled:
+		if o.ID != id {
+			t.Fatalf("filled = %+v", o)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stop loss not triggered")
+	}
+}
+
+func TestExitOrders(t *testing.T) {
+	const mint = "exit-orders-mint"
+	ts := NewTokenJupiterSwap(mint)
+	defer ts.Cancel()
+	ts.MySwap.RemainingAmount.Store(big.NewInt(1000))
+
+	fills := make(chan exitplan.Signal, 10)
+	plan := exitplan.Plan{TakeProfit: []exitplan.TakeProfit{{Multiple: 2, Fraction: 0.5}}, StopLoss: 0.5}
+	state := exitplan.NewState(plan, 0.1, 1000, time.Now())
+	state.Orders = true
+	rules := &exitRules{
+		ts:     ts,
+		state:  state,
+		sell:   func(s exitplan.Signal) error { fills <- s; return nil },
+		orders: make(map[string]uint64),
+	}
+	ts.exit.Store(rules)
+	rules.placeOrders()
+	rules.placeOrders() // 不重复挂单
+
+	placed := Orders.List(mint, false)
+	if len(placed) != 2 {
+		t.Fatalf("orders = %+v", placed)
+	}
+	byRule := make(map[string]orders.Order)
+	for _, o := range placed {
+		byRule[o.Rule] = o
+	}
+	stop, ok := byRule[exitplan.RuleStopLoss]
+	if !ok || stop.Kind != orders.StopLoss || stop.Price != 0.05 {
+		t.Fatalf("stop loss order = %+v", stop)
+	}
+
+	// 撤掉止损单后规则不再触发
+	if err := CancelOrder(stop.ID); err != nil {
+		t.Fatal(err)
+	}
+	ts.setPrice(big.NewFloat(0.04))
+	select {
+	case s := <-fills:
+		t.Fatalf("cancelled stop loss fired: %+v", s)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if triggers := rules.state.Triggers(1000); len(triggers) != 1 {
+		t.Fatalf("triggers after cancel = %+v", triggers)
+	}
+
+	ts.setPrice(big.NewFloat(0.25))
+	select {
+	case s := <-fills:
+		if s.Rule != "take_profit:2x" || s.Amount != 500 {
+			t.Fatalf("take profit = %+v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("take profit order not filled")
+	}
+	for _, o := range Orders.List(mint, false) {
+		if o.State == orders.Pending {
+			t.Fatalf("order left pending: %+v", o)
+		}
+	}
+}
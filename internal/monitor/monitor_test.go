@@ -21,7 +21,9 @@ func TestPump(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	p.Start()
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
 	// p.listenSmartWallet()
 
 }
@@ -31,7 +33,7 @@ func TestBurnToken(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	p.BurnToken("BM5oY7JPjpnr7ennNWVaQU8YhdCaHPAQK52r3xSRbonk")
+	p.BurnToken(p.wallets.All()[0], "BM5oY7JPjpnr7ennNWVaQU8YhdCaHPAQK52r3xSRbonk")
 }
 
 func TestParse(t *testing.T) {
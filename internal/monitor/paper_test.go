@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPaperQuote(t *testing.T) {
+	ts := &TokenSwap{Token: &TokenInfo{}}
+	// 30 SOL / 1,073,000,000 token，pump 初始曲线
+	ts.UpdateAmmPool(1_073_000_000_000_000, 30_000_000_000)
+
+	for _, swapType := range []int32{PumpFunType, PumpAmmType, RaydiumLaunchpadType} {
+		tokens := paperQuote(ts, swapType, true, 1e9)
+		// 1 SOL 大约买到 3.4% 的 token，扣掉 1% 手续费
+		if tokens < 33_000_000_000_000 || tokens > 34_700_000_000_000 {
+			t.Fatalf("type %d: buy 1 SOL got %d", swapType, tokens)
+		}
+		// 立即卖回拿不到超过投入的 SOL
+		if sol := paperQuote(ts, swapType, false, tokens); sol >= 1e9 || sol < 0.9e9 {
+			t.Fatalf("type %d: sell back got %d", swapType, sol)
+		}
+	}
+
+	ts.Token.TokenPrice.Store(big.NewFloat(0.00003))
+	// 0.03 SOL 扣 1% 后按 0.00003 SOL/token 成交
+	if tokens := paperQuote(ts, MeteoraDbcType, true, 3e7); tokens < 989_999_000 || tokens > 990_000_000 {
+		t.Fatalf("dbc buy got %d", tokens)
+	}
+	if paperQuote(ts, JupiterType, true, 1e9) != 0 {
+		t.Fatal("unsupported pool should not fill")
+	}
+}
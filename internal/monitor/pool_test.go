@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+)
+
+func TestApplyPoolAccount(t *testing.T) {
+	base := solana.NewWallet().PublicKey()
+	quote := solana.NewWallet().PublicKey()
+	ts := &TokenSwap{Token: &TokenInfo{}}
+	ts.Token.PoolData.Store(&solanaswapgo.PoolData{
+		PoolType: "PumpAmm",
+		Data: &solanaswapgo.PumpAmmPool{
+			PoolBaseTokenAccount:  base,
+			PoolQuoteTokenAccount: quote,
+		},
+	})
+
+	vault := func(amount uint64) []byte {
+		data := make([]byte, 165)
+		binary.LittleEndian.PutUint64(data[64:], amount)
+		return data
+	}
+	if !ts.applyPoolAccount(base, vault(1_000_000_000_000)) || !ts.applyPoolAccount(quote, vault(50_000_000_000)) {
+		t.Fatal("vault updates should apply")
+	}
+	if ts.Token.PoolTokenBalance.Load() != 1_000_000_000_000 || ts.Token.PoolSolBalance.Load() != 50_000_000_000 {
+		t.Fatalf("unexpected reserves %d/%d", ts.Token.PoolTokenBalance.Load(), ts.Token.PoolSolBalance.Load())
+	}
+	// 50 SOL / 1,000,000 token => 0.00005 SOL/token
+	if price, _ := ts.Token.TokenPrice.Load().Float64(); price < 0.0000499 || price > 0.0000501 {
+		t.Fatalf("unexpected price %v", price)
+	}
+	if ts.applyPoolAccount(solana.NewWallet().PublicKey(), vault(1)) {
+		t.Fatal("unrelated account should be ignored")
+	}
+}
+
+func TestApplyLaunchpadPool(t *testing.T) {
+	poolState := solana.NewWallet().PublicKey()
+	ts := &TokenSwap{Token: &TokenInfo{}}
+	ts.Token.PoolData.Store(&solanaswapgo.PoolData{
+		PoolType: "RaydiumLaunchpad",
+		Data:     &solanaswapgo.RaydiumLaunchpadPool{PoolState: poolState},
+	})
+
+	data := make([]byte, 300)
+	sum := sha256.Sum256([]byte("account:PoolState"))
+	copy(data, sum[:8])
+	data[17] = 1 // status: 等待迁移
+	put := func(i int, v uint64) { binary.LittleEndian.PutUint64(data[21+8*i:], v) }
+	put(2, 1_073_000_000_000_000) // virtual_base
+	put(3, 30_000_000_000)        // virtual_quote
+	put(4, 73_000_000_000_000)    // real_base
+	put(5, 10_000_000_000)        // real_quote
+
+	if !ts.applyPoolAccount(poolState, data) {
+		t.Fatal("pool state should apply")
+	}
+	if ts.Token.PoolTokenBalance.Load() != 1_000_000_000_000_000 || ts.Token.PoolSolBalance.Load() != 40_000_000_000 {
+		t.Fatalf("unexpected reserves %d/%d", ts.Token.PoolTokenBalance.Load(), ts.Token.PoolSolBalance.Load())
+	}
+	if !ts.Token.Migrated.Load() {
+		t.Fatal("pool should be marked migrated")
+	}
+}
+
+func TestLoadPool(t *testing.T) {
+	ts := &TokenSwap{Token: &TokenInfo{}}
+	if _, err := loadPool[*solanaswapgo.PumpFunPool](ts); err == nil {
+		t.Fatal("pool not loaded should fail")
+	}
+	// 迁移后 SwapType 还没切换时，快照已经是 AMM 池
+	ts.SwapType.Store(PumpFunType)
+	ts.Token.PoolData.Store(&solanaswapgo.PoolData{PoolType: "PumpAmm", Data: &solanaswapgo.PumpAmmPool{}})
+	if _, err := loadPool[*solanaswapgo.PumpFunPool](ts); err == nil {
+		t.Fatal("mismatched pool type should fail")
+	}
+	if pool, err := loadPool[*solanaswapgo.PumpAmmPool](ts); err != nil || pool == nil {
+		t.Fatalf("pool = %v, err = %v", pool, err)
+	}
+}
@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/exitplan"
+	"solana-bot/internal/positions"
+
+	"github.com/gagliardetto/solana-go"
+	solanaswapgo "github.com/lonelybeanz/solanaswap-go/solanaswap-go"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	held := &positions.Position{Mint: "a", Exit: positions.ExitPlan{HoldUntil: time.Now()}}
+	noPlan := &positions.Position{Mint: "b"}
+	rules := &positions.Position{Mint: "e", Exit: positions.ExitPlan{Rules: &exitplan.State{}}}
+	gone := &positions.Position{Mint: "c", Exit: positions.ExitPlan{Limits: []positions.Limit{{Price: 1, Amount: 1}}}}
+	holdings := []tokenHolding{{Mint: "a", Amount: 10}, {Mint: "b", Amount: 20}, {Mint: "d", Amount: 30}, {Mint: "e", Amount: 40}}
+
+	actions := func(steps []reconcileStep) map[string]string {
+		out := make(map[string]string)
+		for _, s := range steps {
+			mint := s.Holding.Mint
+			if s.Action == reconcileFinish {
+				mint = s.Position.Mint
+			}
+			out[mint] = s.Action
+		}
+		return out
+	}
+
+	got := actions(planReconcile(holdings, []*positions.Position{held, noPlan, gone, rules}, config.ReconcileConf{Known: "resume", Unknown: "liquidate"}))
+	want := map[string]string{"a": reconcileResume, "b": reconcileLiquidate, "c": reconcileFinish, "d": reconcileLiquidate, "e": reconcileResume}
+	for mint, action := range want {
+		if got[mint] != action {
+			t.Fatalf("%s = %s, want %s (%v)", mint, got[mint], action, got)
+		}
+	}
+
+	got = actions(planReconcile(holdings, []*positions.Position{held}, config.ReconcileConf{Known: "liquidate", Unknown: "ignore"}))
+	if got["a"] != reconcileLiquidate || got["b"] != reconcileIgnore || got["d"] != reconcileIgnore {
+		t.Fatalf("liquidate/ignore = %v", got)
+	}
+}
+
+func TestDecodePoolData(t *testing.T) {
+	pool := &solanaswapgo.PumpFunPool{BondingCurve: solana.NewWallet().PublicKey(), VirtualSolReserves: 30e9, VirtualTokenReserves: 1e15}
+	raw, _ := json.Marshal(pool)
+	poolData, err := decodePoolData("PumpFun", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := poolData.Data.(*solanaswapgo.PumpFunPool)
+	if got.BondingCurve != pool.BondingCurve || got.VirtualSolReserves != pool.VirtualSolReserves {
+		t.Fatalf("decoded = %+v", got)
+	}
+
+	if poolData, err := decodePoolData("Jupiter", nil); poolData != nil || err != nil {
+		t.Fatalf("jupiter = %v, %v", poolData, err)
+	}
+	if _, err := decodePoolData("Orca", raw); err == nil {
+		t.Fatal("unknown pool type decoded")
+	}
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestSellRetryAction(t *testing.T) {
+	custom := func(program solana.PublicKey, code uint32) error {
+		return fmt.Errorf("SendAndWait error: %w", txerr.NewProgramError(program, 3, code))
+	}
+	cases := []struct {
+		err  error
+		want sellAction
+	}{
+		{custom(txerr.PumpProgramID, 6023), sellRequote},
+		// PumpAMM 的 6023 是 Overflow，不是持仓不足
+		{custom(txerr.PumpAmmProgramID, 6023), sellRetry},
+		{custom(solana.TokenProgramID, 1), sellRequote},
+		{custom(txerr.PumpAmmProgramID, 3012), sellGone},
+		{custom(txerr.PumpProgramID, 6003), sellWiden},
+		{custom(txerr.PumpAmmProgramID, 6004), sellWiden},
+		{custom(txerr.DbcProgramID, 6002), sellWiden},
+		// Pump 的 6004 是 MintDoesNotMatchBondingCurve，放大滑点没有用
+		{custom(txerr.PumpProgramID, 6004), sellRetry},
+		{fmt.Errorf("quote: %w", txerr.ErrRateLimited), sellBackoff},
+		{errors.New("SendAndWait error: resp is nil"), sellRetry},
+	}
+	for _, c := range cases {
+		if got := sellRetryAction(c.err); got != c.want {
+			t.Errorf("%v: action = %d, want %d", c.err, got, c.want)
+		}
+	}
+}
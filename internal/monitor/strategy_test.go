@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStrategies(t *testing.T) {
+	for _, name := range []string{"mint", "smart", "scm"} {
+		if !slices.Contains(Strategies(), name) {
+			t.Fatalf("%s not registered: %v", name, Strategies())
+		}
+	}
+	defer SetStrategies(nil)
+
+	SetStrategies([]string{"smart", "", "mint", "smart"})
+	enabled, err := enabledStrategies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enabled) != 2 || enabled[0].Name() != "smart" || enabled[1].Name() != "mint" {
+		t.Fatalf("enabled = %v", EnabledStrategies)
+	}
+
+	SetStrategies([]string{"mint", "unknown"})
+	if _, err := enabledStrategies(); err == nil {
+		t.Fatal("unknown strategy enabled")
+	}
+
+	// 不开仓的策略
+	if _, err := (&scmStrategy{}).Entry(nil, nil); err == nil {
+		t.Fatal("scm should not open positions")
+	}
+	filter := (&mintStrategy{}).Filters().Transactions["transactions_sub"]
+	if *filter.Failed || *filter.Vote || !slices.Equal(filter.AccountInclude, []string{PumpTokenMintAuthority, RaydiumLaunchpadAuthority}) {
+		t.Fatalf("mint filter = %v", filter)
+	}
+}
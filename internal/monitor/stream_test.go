@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"solana-bot/internal/stream"
+	"solana-bot/internal/stream/geysertest"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"google.golang.org/grpc"
+)
+
+// accountStrategy 只订阅一个账户，收到的更新转给 got
+type accountStrategy struct {
+	BaseStrategy
+	account solana.PublicKey
+	got     chan *pb.SubscribeUpdateAccount
+}
+
+func (s *accountStrategy) Name() string { return "stream-test" }
+
+func (s *accountStrategy) Filters() *pb.SubscribeRequest {
+	return &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"accounts_sub": {Account: []string{s.account.String()}},
+		},
+	}
+}
+
+func (s *accountStrategy) OnAccount(p *PumpFunMonitor, account *pb.SubscribeUpdateAccount) *TokenSwap {
+	s.got <- account
+	return nil
+}
+
+func TestStreamToStrategy(t *testing.T) {
+	srv, err := geysertest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	conn := stream.Grpc_connect(srv.Addr(), true)
+	if conn == nil {
+		t.Fatal("connect failed")
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := stream.NewHub(ctx, &stream.GrpcStream{Name: "fake", Conns: []*grpc.ClientConn{conn}})
+	p := &PumpFunMonitor{Wg: &sync.WaitGroup{}, ctx: ctx, cancel: cancel, hub: hub}
+	defer func() {
+		cancel()
+		p.Wg.Wait()
+	}()
+
+	s := &accountStrategy{account: solana.NewWallet().PublicKey(), got: make(chan *pb.SubscribeUpdateAccount, 4)}
+	p.Go(func() { p.runStrategy(s) })
+	if err := srv.WaitSubscribers(1, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	owner := solana.NewWallet().PublicKey()
+	// 过滤器推送是异步的，等服务端看到账户过滤器后再推
+	deadline := time.Now().Add(3 * time.Second)
+	for srv.Push(geysertest.Account(7, s.account, owner, 1, []byte{1, 2, 3})) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("filter never reached server")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	srv.Push(geysertest.Account(8, solana.NewWallet().PublicKey(), owner, 1, nil))
+
+	select {
+	case account := <-s.got:
+		if account.Slot != 7 || solana.PublicKeyFromBytes(account.Account.Pubkey) != s.account {
+			t.Fatalf("account = %+v", account)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("strategy never received the account update")
+	}
+	select {
+	case account := <-s.got:
+		t.Fatalf("unmatched account delivered: %+v", account)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,264 @@
+package orders
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("order not found")
+	ErrNotPending = errors.New("order is not pending")
+)
+
+// Kind 触发方向
+type Kind string
+
+const (
+	TakeProfit Kind = "take_profit" // 价格涨到 Price 及以上时触发
+	StopLoss   Kind = "stop_loss"   // 价格跌到 Price 及以下时触发
+)
+
+// State 订单状态，只会按 pending -> triggered -> filled/cancelled 或 pending -> cancelled 变化
+type State string
+
+const (
+	Pending   State = "pending"
+	Triggered State = "triggered"
+	Filled    State = "filled"
+	Cancelled State = "cancelled"
+)
+
+// 已结束的订单最多保留这么多个，供查询
+const historySize = 500
+
+// Order 一个按价格触发的卖出单
+type Order struct {
+	ID      uint64
+	Wallet  string
+	Mint    string
+	Kind    Kind
+	Price   float64 // 触发价，SOL / token
+	Amount  uint64  // 卖出数量
+	Rule    string  // 记录到卖出上的规则名
+	State   State
+	Reason  string // 取消或卖出失败的原因
+	Created time.Time
+	Updated time.Time
+}
+
+// Executor 订单触发后执行卖出，返回错误时订单记为 cancelled
+type Executor func(Order) error
+
+type entry struct {
+	Order
+	exec Executor
+}
+
+// book 一个 token 上挂着的订单：止盈按触发价从低到高，止损从高到低，价格变化时只需要看队头
+type book struct {
+	above []*entry
+	below []*entry
+	last  float64 // 最近一次价格
+}
+
+// Engine 所有 token 的条件单，价格更新时集中撮合；每个订单最多触发一次
+type Engine struct {
+	mu      sync.Mutex
+	nextID  uint64
+	books   map[string]*book
+	active  map[uint64]*entry
+	history []Order
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		books:  make(map[string]*book),
+		active: make(map[uint64]*entry),
+	}
+}
+
+// Place 挂一个订单，返回订单号；当前价格已经越过触发价时立即触发
+func (e *Engine) Place(o Order, exec Executor) uint64 {
+	e.mu.Lock()
+	e.nextID++
+	now := time.Now()
+	o.ID, o.State, o.Reason, o.Created, o.Updated = e.nextID, Pending, "", now, now
+	en := &entry{Order: o, exec: exec}
+	e.active[o.ID] = en
+
+	b := e.books[o.Mint]
+	if b == nil {
+		b = &book{}
+		e.books[o.Mint] = b
+	}
+	if o.Kind == StopLoss {
+		i := sort.Search(len(b.below), func(i int) bool { return b.below[i].Price < o.Price })
+		b.below = insert(b.below, i, en)
+	} else {
+		i := sort.Search(len(b.above), func(i int) bool { return b.above[i].Price > o.Price })
+		b.above = insert(b.above, i, en)
+	}
+	var fired []*entry
+	if b.last > 0 {
+		fired = e.crossLocked(b, b.last)
+	}
+	e.mu.Unlock()
+
+	e.run(fired)
+	return o.ID
+}
+
+// OnPrice mint 的价格更新，越过触发价的订单转为 triggered 并执行
+func (e *Engine) OnPrice(mint string, price float64) {
+	if price <= 0 {
+		return
+	}
+	e.mu.Lock()
+	b := e.books[mint]
+	if b == nil {
+		e.mu.Unlock()
+		return
+	}
+	b.last = price
+	fired := e.crossLocked(b, price)
+	e.mu.Unlock()
+
+	e.run(fired)
+}
+
+func (e *Engine) crossLocked(b *book, price float64) []*entry {
+	var fired []*entry
+	for len(b.above) > 0 && b.above[0].Price <= price {
+		fired = append(fired, b.above[0])
+		b.above = b.above[1:]
+	}
+	for len(b.below) > 0 && b.below[0].Price >= price {
+		fired = append(fired, b.below[0])
+		b.below = b.below[1:]
+	}
+	now := time.Now()
+	for _, en := range fired {
+		en.State, en.Updated = Triggered, now
+	}
+	return fired
+}
+
+// run 依次执行触发的订单，同一次价格更新触发的多个订单不并发卖出
+func (e *Engine) run(fired []*entry) {
+	if len(fired) == 0 {
+		return
+	}
+	go func() {
+		for _, en := range fired {
+			err := en.exec(en.Order)
+			e.mu.Lock()
+			if err != nil {
+				en.State, en.Reason = Cancelled, err.Error()
+			} else {
+				en.State = Filled
+			}
+			en.Updated = time.Now()
+			e.finishLocked(en)
+			e.mu.Unlock()
+		}
+	}()
+}
+
+// Cancel 取消一个还没触发的订单
+func (e *Engine) Cancel(id uint64, reason string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	en, ok := e.active[id]
+	if !ok {
+		for _, o := range e.history {
+			if o.ID == id {
+				return ErrNotPending
+			}
+		}
+		return ErrNotFound
+	}
+	if en.State != Pending {
+		return ErrNotPending
+	}
+	e.cancelLocked(en, reason)
+	return nil
+}
+
+// CancelToken 取消钱包在 mint 上所有还没触发的订单，例如仓位已经平掉
+func (e *Engine) CancelToken(wallet, mint, reason string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var n int
+	for _, en := range e.active {
+		if en.Wallet == wallet && en.Mint == mint && en.State == Pending {
+			e.cancelLocked(en, reason)
+			n++
+		}
+	}
+	return n
+}
+
+func (e *Engine) cancelLocked(en *entry, reason string) {
+	if b := e.books[en.Mint]; b != nil {
+		b.above = remove(b.above, en)
+		b.below = remove(b.below, en)
+	}
+	en.State, en.Reason, en.Updated = Cancelled, reason, time.Now()
+	e.finishLocked(en)
+}
+
+// finishLocked 订单结束，移到历史里；token 上没有订单时丢掉它的 book
+func (e *Engine) finishLocked(en *entry) {
+	delete(e.active, en.ID)
+	e.history = append(e.history, en.Order)
+	if len(e.history) > historySize {
+		e.history = e.history[len(e.history)-historySize:]
+	}
+	if b := e.books[en.Mint]; b != nil && len(b.above) == 0 && len(b.below) == 0 {
+		for _, other := range e.active {
+			if other.Mint == en.Mint {
+				return
+			}
+		}
+		delete(e.books, en.Mint)
+	}
+}
+
+// List 按订单号排列的订单，mint 为空时返回所有 token；all 为 false 时只返回 pending、triggered
+func (e *Engine) List(mint string, all bool) []Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []Order
+	for _, en := range e.active {
+		if mint == "" || en.Mint == mint {
+			out = append(out, en.Order)
+		}
+	}
+	if all {
+		for _, o := range e.history {
+			if mint == "" || o.Mint == mint {
+				out = append(out, o)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func insert(s []*entry, i int, en *entry) []*entry {
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = en
+	return s
+}
+
+func remove(s []*entry, en *entry) []*entry {
+	for i, other := range s {
+		if other == en {
+			return append(s[:i], s[i+1:]...)
+		}
+	}
+	return s
+}
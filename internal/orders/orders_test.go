@@ -0,0 +1,96 @@
+package orders
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitState(t *testing.T, e *Engine, id uint64, want State) Order {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, o := range e.List("", true) {
+			if o.ID == id && o.State == want {
+				return o
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("order %d not %s: %+v", id, want, e.List("", true))
+	return Order{}
+}
+
+func TestTriggerOnce(t *testing.T) {
+	e := NewEngine()
+	var fills atomic.Int32
+	exec := func(Order) error {
+		fills.Add(1)
+		return nil
+	}
+
+	tp1 := e.Place(Order{Wallet: "w", Mint: "m", Kind: TakeProfit, Price: 1.5, Amount: 10}, exec)
+	tp2 := e.Place(Order{Wallet: "w", Mint: "m", Kind: TakeProfit, Price: 4, Amount: 10}, exec)
+	sl := e.Place(Order{Wallet: "w", Mint: "m", Kind: StopLoss, Price: 0.7, Amount: 10}, exec)
+
+	e.OnPrice("m", 1)
+	e.OnPrice("other", 10)
+	if got := e.List("m", false); len(got) != 3 {
+		t.Fatalf("pending = %+v", got)
+	}
+
+	// 多次越过触发价只成交一次
+	e.OnPrice("m", 1.6)
+	e.OnPrice("m", 2)
+	waitState(t, e, tp1, Filled)
+	if o := waitState(t, e, tp2, Pending); o.Price != 4 {
+		t.Fatalf("tp2 = %+v", o)
+	}
+	if fills.Load() != 1 {
+		t.Fatalf("fills = %d", fills.Load())
+	}
+
+	e.OnPrice("m", 0.5)
+	waitState(t, e, sl, Filled)
+	if err := e.Cancel(sl, "operator"); !errors.Is(err, ErrNotPending) {
+		t.Fatalf("cancel filled = %v", err)
+	}
+	if err := e.Cancel(tp2, "operator"); err != nil {
+		t.Fatal(err)
+	}
+	if o := waitState(t, e, tp2, Cancelled); o.Reason != "operator" {
+		t.Fatalf("cancelled = %+v", o)
+	}
+	e.OnPrice("m", 5)
+	time.Sleep(10 * time.Millisecond)
+	if fills.Load() != 2 {
+		t.Fatalf("fills after cancel = %d", fills.Load())
+	}
+	if err := e.Cancel(99, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("cancel unknown = %v", err)
+	}
+	if got := e.List("", false); len(got) != 0 {
+		t.Fatalf("active = %+v", got)
+	}
+}
+
+func TestPlaceCrossed(t *testing.T) {
+	e := NewEngine()
+	ok := func(Order) error { return nil }
+	e.Place(Order{Wallet: "w", Mint: "m", Kind: TakeProfit, Price: 10}, ok)
+	e.OnPrice("m", 2)
+
+	// 挂单时价格已经越过触发价，立即触发；卖出失败记为取消
+	id := e.Place(Order{Wallet: "w", Mint: "m", Kind: StopLoss, Price: 3}, func(Order) error { return errors.New("sell failed") })
+	if o := waitState(t, e, id, Cancelled); o.Reason != "sell failed" {
+		t.Fatalf("failed = %+v", o)
+	}
+
+	if n := e.CancelToken("w", "m", "closed"); n != 1 {
+		t.Fatalf("cancel token = %d", n)
+	}
+	if len(e.books) != 0 {
+		t.Fatalf("books = %v", e.books)
+	}
+}
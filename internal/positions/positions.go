@@ -0,0 +1,262 @@
+package positions
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"solana-bot/internal/exitplan"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	ErrNotFound = errors.New("position not found")
+
+	openBucket   = []byte("open")
+	closedBucket = []byte("closed")
+	stateBucket  = []byte("state") // 和仓位一起跨重启保留的进程状态，例如风控熔断
+)
+
+// Position 一个钱包在一个 token 上的仓位，买入、卖出时写入，重启后据此恢复
+type Position struct {
+	Wallet       string
+	Mint         string
+	Strategy     string          // 开仓的策略
+	PoolType     string          // PumpFun、PumpAmm、MeteoraDbc、RaydiumLaunchpad、Jupiter
+	PoolAccounts []string        // 池子状态账户，恢复时订阅
+	Pool         json.RawMessage `json:",omitempty"` // 按 PoolType 对应的 solanaswapgo 池子结构，恢复时用它卖出
+	EntrySlot    uint64          // 买入落地的 slot
+	EntryPrice   float64         // SOL / token（按 6 位小数）
+	EntryAmount  uint64          // 买到的 token 数量
+	EntryCost    uint64          // 买入花费的 lamports，含手续费
+	Remaining    uint64          // 未卖出的 token 数量
+	Sells        []Sell
+	Exit         ExitPlan
+	OpenedAt     time.Time
+	ClosedAt     time.Time `json:",omitempty"`
+}
+
+// Sell 一次已落地的卖出
+type Sell struct {
+	Slot     uint64
+	Amount   uint64 // 卖出的 token 数量
+	Lamports int64  // 钱包 SOL 的变化，含手续费
+	Rule     string `json:",omitempty"` // 触发这次卖出的规则，如 take_profit:1.5x、stop_loss、hold:1/3
+	Time     time.Time
+}
+
+// ExitPlan 仓位的退出方式
+type ExitPlan struct {
+	HoldUntil time.Time       `json:",omitempty"` // 持有到期后分批卖出
+	Limits    []Limit         `json:",omitempty"` // 止盈、止损单
+	Rules     *exitplan.State `json:",omitempty"` // 策略配置的退出规则和执行到的位置
+}
+
+// Limit 价格到达 Price 时卖出 Amount
+type Limit struct {
+	Price  float64
+	Amount uint64
+}
+
+// Realized 已卖出部分收回的 lamports
+func (p *Position) Realized() int64 {
+	var total int64
+	for _, s := range p.Sells {
+		total += s.Lamports
+	}
+	return total
+}
+
+// Store 保存在本地 bbolt 文件里的仓位，每次写入都在一个事务里完成
+type Store struct {
+	db *bolt.DB
+}
+
+// Open 打开 path 的仓位库，不存在时创建；同一个文件同时只能被一个进程打开
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{openBucket, closedBucket, stateBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func key(wallet, mint string) []byte {
+	return []byte(wallet + "/" + mint)
+}
+
+// Put 写入一个未平仓的仓位，已有同一钱包、token 的仓位时覆盖
+func (s *Store) Put(p *Position) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(openBucket), p)
+	})
+}
+
+// Get 未平仓的仓位，没有时返回 ErrNotFound
+func (s *Store) Get(wallet, mint string) (*Position, error) {
+	var p *Position
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		p, err = get(tx.Bucket(openBucket), key(wallet, mint))
+		return err
+	})
+	return p, err
+}
+
+// Update 在一个事务里读出、修改并写回未平仓的仓位
+func (s *Store) Update(wallet, mint string, fn func(*Position) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(openBucket)
+		p, err := get(b, key(wallet, mint))
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+		return put(b, p)
+	})
+}
+
+// RecordSell 记录一次卖出，剩余数量卖完时平仓
+func (s *Store) RecordSell(wallet, mint string, sell Sell) (*Position, error) {
+	var p *Position
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		p, err = get(tx.Bucket(openBucket), key(wallet, mint))
+		if err != nil {
+			return err
+		}
+		p.Sells = append(p.Sells, sell)
+		if sell.Amount >= p.Remaining {
+			p.Remaining = 0
+			return closeOut(tx, p, sell.Time)
+		}
+		p.Remaining -= sell.Amount
+		return put(tx.Bucket(openBucket), p)
+	})
+	return p, err
+}
+
+// Finish 平仓，例如 token 账户已经关闭或持仓已经清零
+func (s *Store) Finish(wallet, mint string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		p, err := get(tx.Bucket(openBucket), key(wallet, mint))
+		if err != nil {
+			return err
+		}
+		p.Remaining = 0
+		return closeOut(tx, p, time.Now())
+	})
+}
+
+// PutState 以 JSON 保存 name 对应的状态，覆盖之前的
+func (s *Store) PutState(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(name), data)
+	})
+}
+
+// GetState 读出 name 对应的状态到 v，没有保存过时返回 ErrNotFound
+func (s *Store) GetState(name string, v interface{}) error {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(stateBucket).Get([]byte(name)); b != nil {
+			data = append([]byte(nil), b...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// List 所有未平仓的仓位
+func (s *Store) List() ([]*Position, error) {
+	return s.list(openBucket)
+}
+
+// Closed 所有已平仓的仓位
+func (s *Store) Closed() ([]*Position, error) {
+	return s.list(closedBucket)
+}
+
+func (s *Store) list(bucket []byte) ([]*Position, error) {
+	var out []*Position
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, v []byte) error {
+			var p Position
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			out = append(out, &p)
+			return nil
+		})
+	})
+	return out, err
+}
+
+// closeOut 把仓位从 open 移到 closed；同一钱包、token 可能多次开平仓，closed 里按平仓时间区分
+func closeOut(tx *bolt.Tx, p *Position, at time.Time) error {
+	p.ClosedAt = at
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(openBucket).Delete(key(p.Wallet, p.Mint)); err != nil {
+		return err
+	}
+	return tx.Bucket(closedBucket).Put(append(key(p.Wallet, p.Mint), "/"+at.UTC().Format(time.RFC3339Nano)...), data)
+}
+
+func get(b *bolt.Bucket, k []byte) (*Position, error) {
+	v := b.Get(k)
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	var p Position
+	if err := json.Unmarshal(v, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func put(b *bolt.Bucket, p *Position) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return b.Put(key(p.Wallet, p.Mint), data)
+}
@@ -0,0 +1,83 @@
+package positions
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "positions.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Position{Wallet: "w", Mint: "m", Strategy: "mint", PoolType: "PumpFun", EntryAmount: 300, EntryCost: 1e9, Remaining: 300, OpenedAt: time.Now()}
+	if err := s.Put(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update("w", "m", func(p *Position) error {
+		p.Exit.Limits = append(p.Exit.Limits, Limit{Price: 1.5, Amount: 240})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.RecordSell("w", "m", Sell{Amount: 100, Lamports: 4e8, Time: time.Now()}); err != nil || got.Remaining != 200 {
+		t.Fatalf("sell = %+v, %v", got, err)
+	}
+
+	// 重新打开后仓位还在
+	s.Close()
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	open, err := s.List()
+	if err != nil || len(open) != 1 {
+		t.Fatalf("open = %v, %v", open, err)
+	}
+	if got := open[0]; got.Remaining != 200 || len(got.Sells) != 1 || len(got.Exit.Limits) != 1 || got.Strategy != "mint" {
+		t.Fatalf("reopened = %+v", got)
+	}
+
+	// 卖完后平仓
+	if _, err := s.RecordSell("w", "m", Sell{Amount: 250, Lamports: 7e8, Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("w", "m"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get closed = %v", err)
+	}
+	closed, err := s.Closed()
+	if err != nil || len(closed) != 1 || closed[0].Remaining != 0 || closed[0].Realized() != 11e8 || closed[0].ClosedAt.IsZero() {
+		t.Fatalf("closed = %+v, %v", closed, err)
+	}
+	if err := s.Finish("w", "m"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("finish closed = %v", err)
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "positions.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct{ Halted bool }
+	if err := s.GetState("risk", &got); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing state = %v", err)
+	}
+	if err := s.PutState("risk", struct{ Halted bool }{true}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.GetState("risk", &got); err != nil || !got.Halted {
+		t.Fatalf("state = %+v, %v", got, err)
+	}
+}
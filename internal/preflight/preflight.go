@@ -0,0 +1,225 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/global"
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	maxUnits       = 1_400_000 // 单笔交易的 CU 上限
+	defaultMargin  = 0.1
+	defaultSamples = 3
+	defaultTTL     = 10 * time.Minute
+	// 通道发送时还会追加一条小费转账
+	tipUnits = 300
+	timeout  = 2 * time.Second
+)
+
+var ErrSimulation = errors.New("simulation failed")
+
+// SimulationError 模拟执行出错，交易不会发送
+type SimulationError struct {
+	Err   interface{} // RPC 返回的交易错误，例如 {"InstructionError": [2, {"Custom": 6001}]}
+	Cause error       // 按程序解析出的错误，见 txerr.Decode
+	Logs  []string
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("simulation failed: %v", e.Err)
+}
+
+func (e *SimulationError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{ErrSimulation}
+	}
+	return []error{ErrSimulation, e.Cause}
+}
+
+type entry struct {
+	units   uint64 // 观察到的最大消耗
+	samples int
+	learned time.Time // 模拟够次数的时间，超过 ttl 后重新学习
+}
+
+// Preflight 发送前模拟交易，按实际消耗收紧 CU 上限；
+// 同一池子类型、同样指令组合的交易模拟够 samples 次后直接使用学到的上限，不再模拟。
+// 学到的上限 ttl 后过期，链上因 CU 不够失败时用 Forget 立即丢掉
+type Preflight struct {
+	client  *rpc.Client
+	margin  float64
+	samples int
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu    sync.RWMutex
+	cache map[string]*entry
+}
+
+func New(client *rpc.Client, conf config.PreflightConf) *Preflight {
+	margin := conf.Margin
+	if margin <= 0 {
+		margin = defaultMargin
+	}
+	samples := conf.Samples
+	if samples <= 0 {
+		samples = defaultSamples
+	}
+	ttl := conf.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Preflight{
+		client:  client,
+		margin:  margin,
+		samples: samples,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   make(map[string]*entry),
+	}
+}
+
+// Key 缓存学到的上限用的 key：池子类型加指令组合
+func Key(pool string, txBuilder *global.TxBuilder) string {
+	return pool + "/" + Shape(txBuilder.Instructions())
+}
+
+// Run 为 pool 类型的交易设置 CU 上限。还没学到上限时先模拟，
+// 模拟出错返回 *SimulationError；RPC 不可用时保留原来的上限照常发送
+func (p *Preflight) Run(ctx context.Context, pool string, txBuilder *global.TxBuilder) error {
+	key := Key(pool, txBuilder)
+	if units, ok := p.Learned(key); ok {
+		txBuilder.SetComputeUnitLimit(p.limit(units))
+		return nil
+	}
+
+	units, err := p.simulate(ctx, txBuilder)
+	if err != nil {
+		var simErr *SimulationError
+		if errors.As(err, &simErr) {
+			return err
+		}
+		logx.Errorf("[preflight] %s: %v", key, err)
+		return nil
+	}
+	p.record(key, units)
+	txBuilder.SetComputeUnitLimit(p.limit(units))
+	return nil
+}
+
+// Learned 已经模拟够次数、还没过期的 key 的最大消耗；过期的从头重新学习
+func (p *Preflight) Learned(key string) (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.cache[key]
+	if !ok || e.samples < p.samples {
+		return 0, false
+	}
+	if p.now().Sub(e.learned) > p.ttl {
+		delete(p.cache, key)
+		logx.Infof("[preflight] %s: learned limit expired", key)
+		return 0, false
+	}
+	return e.units, true
+}
+
+// Forget 丢掉 key 学到的上限，下次发送重新模拟；交易因 CU 不够失败时调用，见 txerr.IsComputeExceeded
+func (p *Preflight) Forget(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.cache[key]; ok {
+		delete(p.cache, key)
+		logx.Infof("[preflight] %s: forgot learned limit", key)
+	}
+}
+
+func (p *Preflight) record(key string, units uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.cache[key]
+	if !ok {
+		e = &entry{}
+		p.cache[key] = e
+	}
+	e.units = max(e.units, units)
+	e.samples++
+	if e.samples == p.samples {
+		e.learned = p.now()
+		logx.Infof("[preflight] %s: learned %d CU", key, e.units)
+	}
+}
+
+func (p *Preflight) limit(units uint64) uint32 {
+	limit := uint64(float64(units)*(1+p.margin)) + tipUnits
+	return uint32(min(limit, maxUnits))
+}
+
+// simulate 用最大 CU 上限模拟交易，返回实际消耗；模拟不校验签名，不需要签名
+func (p *Preflight) simulate(ctx context.Context, txBuilder *global.TxBuilder) (uint64, error) {
+	sim := *txBuilder
+	sim.SetComputeUnitLimit(maxUnits)
+	tx, err := sim.Build()
+	if err != nil {
+		return 0, err
+	}
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := p.client.SimulateTransactionWithOpts(ctx, tx, &rpc.SimulateTransactionOpts{
+		Commitment: rpc.CommitmentProcessed,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if out.Value.Err != nil {
+		return 0, &SimulationError{
+			Err:   out.Value.Err,
+			Cause: txerr.Decode(out.Value.Err, txerr.Programs(sim.Instructions())),
+			Logs:  out.Value.Logs,
+		}
+	}
+	if out.Value.UnitsConsumed == nil {
+		return 0, errors.New("no unitsConsumed in simulation result")
+	}
+	return *out.Value.UnitsConsumed, nil
+}
+
+// Shape 指令组合：除 ComputeBudget 外每条指令的程序和指令类型
+func Shape(instrs []solana.Instruction) string {
+	parts := make([]string, 0, len(instrs))
+	for _, ins := range instrs {
+		program := ins.ProgramID()
+		if program.Equals(solana.ComputeBudget) {
+			continue
+		}
+		data, _ := ins.Data()
+		data = data[:min(len(data), discriminatorSize(program))]
+		parts = append(parts, fmt.Sprintf("%s:%x", program.String()[:8], data))
+	}
+	return strings.Join(parts, ",")
+}
+
+// discriminatorSize 指令类型占 data 的字节数：System 为 u32，SPL 程序为 u8，Anchor 程序为 8 字节
+func discriminatorSize(program solana.PublicKey) int {
+	switch {
+	case program.Equals(solana.SystemProgramID):
+		return 4
+	case program.Equals(solana.TokenProgramID), program.Equals(solana.Token2022ProgramID),
+		program.Equals(solana.SPLAssociatedTokenAccountProgramID):
+		return 1
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,158 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/global"
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// simulator 模拟 simulateTransaction，返回固定的消耗或错误
+func simulator(t *testing.T, units uint64, txErr interface{}, calls *atomic.Int32) *rpc.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "simulateTransaction" {
+			t.Errorf("method = %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": 1},
+				"value":   map[string]interface{}{"err": txErr, "logs": []string{}, "unitsConsumed": units},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return rpc.New(srv.URL)
+}
+
+func testBuilder(payer solana.PublicKey) *global.TxBuilder {
+	b := global.NewTxBuilder(payer, solana.Hash{1})
+	b.AddInstruction(
+		computebudget.NewSetComputeUnitLimitInstruction(200_000).Build(),
+		system.NewTransferInstruction(1, payer, solana.NewWallet().PublicKey()).Build(),
+	)
+	return b
+}
+
+func unitLimit(t *testing.T, b *global.TxBuilder) uint32 {
+	for _, ins := range b.Instructions() {
+		if global.IsComputeUnitLimit(ins) {
+			data, _ := ins.Data()
+			inst, err := computebudget.DecodeInstruction(nil, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return inst.Impl.(*computebudget.SetComputeUnitLimit).Units
+		}
+	}
+	t.Fatal("no compute unit limit")
+	return 0
+}
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int32
+	p := New(simulator(t, 1000, nil, &calls), config.PreflightConf{Samples: 2})
+	payer := solana.NewWallet().PublicKey()
+
+	for i := 0; i < 4; i++ {
+		b := testBuilder(payer)
+		if err := p.Run(context.Background(), "PumpFun", b); err != nil {
+			t.Fatal(err)
+		}
+		if got := unitLimit(t, b); got != 1000*1.1+tipUnits {
+			t.Fatalf("limit = %d", got)
+		}
+	}
+	// 学到上限后不再模拟
+	if calls.Load() != 2 {
+		t.Fatalf("simulated %d times, want 2", calls.Load())
+	}
+}
+
+func TestRelearn(t *testing.T) {
+	var calls atomic.Int32
+	p := New(simulator(t, 1000, nil, &calls), config.PreflightConf{Samples: 1, TTL: time.Minute})
+	now := time.Unix(1000, 0)
+	p.now = func() time.Time { return now }
+	payer := solana.NewWallet().PublicKey()
+	run := func() {
+		if err := p.Run(context.Background(), "PumpFun", testBuilder(payer)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run()
+	run()
+	if calls.Load() != 1 {
+		t.Fatalf("simulated %d times, want 1", calls.Load())
+	}
+	// 链上 CU 不够后重新模拟
+	p.Forget(Key("PumpFun", testBuilder(payer)))
+	run()
+	if calls.Load() != 2 {
+		t.Fatalf("simulated %d times after forget, want 2", calls.Load())
+	}
+	// 过期后重新模拟
+	now = now.Add(2 * time.Minute)
+	run()
+	run()
+	if calls.Load() != 3 {
+		t.Fatalf("simulated %d times after expiry, want 3", calls.Load())
+	}
+}
+
+func TestRunSimulationError(t *testing.T) {
+	var calls atomic.Int32
+	txErr := map[string]interface{}{"InstructionError": []interface{}{1, map[string]interface{}{"Custom": 6001}}}
+	p := New(simulator(t, 1000, txErr, &calls), config.PreflightConf{})
+
+	b := testBuilder(solana.NewWallet().PublicKey())
+	err := p.Run(context.Background(), "PumpFun", b)
+	var simErr *SimulationError
+	if !errors.As(err, &simErr) || !errors.Is(err, ErrSimulation) {
+		t.Fatalf("err = %v, want simulation error", err)
+	}
+	// 出错的是第 2 条指令，即 System 转账
+	var progErr *txerr.ProgramError
+	if !errors.As(err, &progErr) || !progErr.ProgramID.Equals(solana.SystemProgramID) || progErr.Code != 6001 {
+		t.Fatalf("cause = %v", simErr.Cause)
+	}
+	// 出错的交易不学习，保留原来的上限
+	if got := unitLimit(t, b); got != 200_000 {
+		t.Fatalf("limit = %d", got)
+	}
+}
+
+func TestShape(t *testing.T) {
+	payer := solana.NewWallet().PublicKey()
+	a, b := testBuilder(payer), testBuilder(payer)
+	b.SetComputeUnitLimit(5000)
+	b.AddInstruction(system.NewTransferInstruction(2, payer, payer).Build())
+	if Shape(a.Instructions()) == Shape(b.Instructions()) {
+		t.Fatal("different instructions share a shape")
+	}
+	a.AddInstruction(system.NewTransferInstruction(3e9, payer, payer).Build())
+	if Shape(a.Instructions()) != Shape(b.Instructions()) {
+		t.Fatal("amounts or compute budget changed the shape")
+	}
+}
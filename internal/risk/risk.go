@@ -0,0 +1,294 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"solana-bot/internal/config"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ErrHalted 窗口内已实现亏损超过上限，需要手动 Reset 后才能开仓
+var ErrHalted = errors.New("loss limit reached, entries halted")
+
+// Entry 一次开仓请求
+type Entry struct {
+	Mint      string
+	Strategy  string // mint/smart/scm
+	Leader    string // 跟随的钱包，没有时为空
+	Launchpad string // 池子类型
+	Amount    uint64 // 投入的 lamports
+	PoolSol   uint64 // 池子 SOL 储备，0 为未知，不检查占比
+}
+
+// Position 一个还没平掉的仓位
+type Position struct {
+	Ticket uint64
+	Entry
+	Opened time.Time
+}
+
+// Realized 一笔已实现盈亏
+type Realized struct {
+	At  time.Time
+	Pnl int64
+}
+
+// Saved 需要跨重启保留的状态：熔断和窗口内的已实现盈亏；未平的仓位由启动核对重新 Track
+type Saved struct {
+	Halted   bool
+	Reason   string
+	HaltedAt time.Time
+	Realized []Realized
+}
+
+// Status 当前敞口、窗口内已实现盈亏和熔断状态
+type Status struct {
+	Limits     config.RiskConf
+	Exposure   uint64
+	Positions  []Position
+	WindowPnl  int64
+	Halted     bool
+	HaltReason string
+	HaltedAt   time.Time
+}
+
+// Manager 开仓前的风控：限制总敞口、各维度的仓位数、占池子的比例，已实现亏损超过上限时熔断
+type Manager struct {
+	mu       sync.Mutex
+	limits   config.RiskConf
+	next     uint64
+	open     map[uint64]*Position
+	realized []Realized
+	halted   bool
+	reason   string
+	haltedAt time.Time
+	onHalt   func(reason string)
+	onChange func(Saved)
+	now      func() time.Time
+}
+
+func New(limits config.RiskConf) *Manager {
+	return &Manager{
+		limits: limits,
+		open:   make(map[uint64]*Position),
+		now:    time.Now,
+	}
+}
+
+// SetLimits 热重载后更新上限，已有仓位不受影响
+func (m *Manager) SetLimits(limits config.RiskConf) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = limits
+}
+
+// OnHalt 熔断时回调，例如暂停监控
+func (m *Manager) OnHalt(fn func(reason string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onHalt = fn
+}
+
+// OnChange 已实现盈亏或熔断状态变化时回调，用于持久化；在锁内调用，保证按顺序写入，
+// fn 里不能再调用 Manager 的方法
+func (m *Manager) OnChange(fn func(Saved)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// Restore 恢复上次运行保存的状态，启动时在开仓前调用；恢复为熔断时不回调 OnHalt
+func (m *Manager) Restore(s Saved) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.halted, m.reason, m.haltedAt = s.Halted, s.Reason, s.HaltedAt
+	m.realized = append([]Realized(nil), s.Realized...)
+	logx.Infof("[risk] 恢复状态，熔断: %v %s，已实现 %d 笔", s.Halted, s.Reason, len(s.Realized))
+}
+
+// Check 检查一次开仓，通过时占用额度并返回 ticket，之后用 Release 或 Close 归还；不通过时返回原因
+func (m *Manager) Check(e Entry) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkLocked(e); err != nil {
+		logx.Infof("[risk] 拒绝开仓 %s(%s) %d lamports: %v", e.Mint, e.Strategy, e.Amount, err)
+		return 0, err
+	}
+	ticket := m.trackLocked(e)
+	logx.Infof("[risk] 允许开仓 %s(%s) %d lamports, ticket %d, 总敞口 %d", e.Mint, e.Strategy, e.Amount, ticket, m.exposureLocked())
+	return ticket, nil
+}
+
+func (m *Manager) checkLocked(e Entry) error {
+	if m.halted {
+		return fmt.Errorf("%w: %s", ErrHalted, m.reason)
+	}
+	l := m.limits
+	if l.MaxExposure > 0 {
+		if exposure := m.exposureLocked(); exposure+e.Amount > l.MaxExposure {
+			return fmt.Errorf("exposure %d + %d exceeds %d", exposure, e.Amount, l.MaxExposure)
+		}
+	}
+	var strategy, leader, launchpad int
+	for _, p := range m.open {
+		if p.Strategy == e.Strategy {
+			strategy++
+		}
+		if e.Leader != "" && p.Leader == e.Leader {
+			leader++
+		}
+		if p.Launchpad == e.Launchpad {
+			launchpad++
+		}
+	}
+	switch {
+	case l.MaxPerStrategy > 0 && strategy >= l.MaxPerStrategy:
+		return fmt.Errorf("strategy %s has %d open positions, limit %d", e.Strategy, strategy, l.MaxPerStrategy)
+	case l.MaxPerLeader > 0 && leader >= l.MaxPerLeader:
+		return fmt.Errorf("leader %s has %d open positions, limit %d", e.Leader, leader, l.MaxPerLeader)
+	case l.MaxPerLaunchpad > 0 && launchpad >= l.MaxPerLaunchpad:
+		return fmt.Errorf("launchpad %s has %d open positions, limit %d", e.Launchpad, launchpad, l.MaxPerLaunchpad)
+	}
+	if l.MaxPoolShare > 0 && e.PoolSol > 0 {
+		if share := float64(e.Amount) / float64(e.PoolSol); share > l.MaxPoolShare {
+			return fmt.Errorf("%.4f of pool liquidity exceeds %.4f", share, l.MaxPoolShare)
+		}
+	}
+	return nil
+}
+
+// Track 不做检查直接记录一个仓位，例如启动时接管的持仓
+func (m *Manager) Track(e Entry) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ticket := m.trackLocked(e)
+	logx.Infof("[risk] 接管仓位 %s(%s) %d lamports, ticket %d", e.Mint, e.Strategy, e.Amount, ticket)
+	return ticket
+}
+
+func (m *Manager) trackLocked(e Entry) uint64 {
+	m.next++
+	m.open[m.next] = &Position{Ticket: m.next, Entry: e, Opened: m.now()}
+	return m.next
+}
+
+// Release 买入没有成交，归还额度
+func (m *Manager) Release(ticket uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.open[ticket]; ok {
+		delete(m.open, ticket)
+		logx.Infof("[risk] 释放额度 %s, ticket %d", p.Mint, ticket)
+	}
+}
+
+// Close 仓位平掉，归还额度并记录已实现盈亏（lamports）；窗口内亏损超过上限时熔断
+func (m *Manager) Close(ticket uint64, pnl int64) {
+	m.mu.Lock()
+	p, ok := m.open[ticket]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.open, ticket)
+	now := m.now()
+	m.realized = append(m.realized, Realized{At: now, Pnl: pnl})
+	windowPnl := m.windowPnlLocked(now)
+	logx.Infof("[risk] 平仓 %s, ticket %d, 盈亏 %d, 窗口内已实现 %d", p.Mint, ticket, pnl, windowPnl)
+
+	var onHalt func(string)
+	if !m.halted && m.limits.LossLimit > 0 && windowPnl < 0 && uint64(-windowPnl) >= m.limits.LossLimit {
+		m.halted, m.haltedAt = true, now
+		m.reason = fmt.Sprintf("realized %d lamports within %v, limit %d", windowPnl, m.limits.LossWindow, m.limits.LossLimit)
+		onHalt = m.onHalt
+		logx.Errorf("[risk] 熔断，暂停开仓: %s", m.reason)
+	}
+	reason := m.reason
+	m.saveLocked()
+	m.mu.Unlock()
+
+	if onHalt != nil {
+		onHalt(reason)
+	}
+}
+
+// windowPnlLocked 窗口内已实现盈亏合计，顺便丢掉窗口外的记录
+func (m *Manager) windowPnlLocked(now time.Time) int64 {
+	if m.limits.LossWindow > 0 {
+		cutoff := now.Add(-m.limits.LossWindow)
+		i := 0
+		for i < len(m.realized) && m.realized[i].At.Before(cutoff) {
+			i++
+		}
+		m.realized = m.realized[i:]
+	}
+	var sum int64
+	for _, r := range m.realized {
+		sum += r.Pnl
+	}
+	return sum
+}
+
+func (m *Manager) exposureLocked() uint64 {
+	var sum uint64
+	for _, p := range m.open {
+		sum += p.Amount
+	}
+	return sum
+}
+
+// Halted 是否已经熔断
+func (m *Manager) Halted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.halted
+}
+
+// Reset 人工恢复开仓，清掉窗口内的已实现盈亏，返回恢复前是否处于熔断
+func (m *Manager) Reset() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	was := m.halted
+	m.halted, m.reason, m.haltedAt = false, "", time.Time{}
+	m.realized = nil
+	m.saveLocked()
+	logx.Infof("[risk] 人工恢复开仓，恢复前熔断: %v", was)
+	return was
+}
+
+// saveLocked 把需要保留的状态交给 OnChange
+func (m *Manager) saveLocked() {
+	if m.onChange == nil {
+		return
+	}
+	m.onChange(Saved{
+		Halted:   m.halted,
+		Reason:   m.reason,
+		HaltedAt: m.haltedAt,
+		Realized: append([]Realized(nil), m.realized...),
+	})
+}
+
+// Status 当前状态，仓位按 ticket 排列
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := Status{
+		Limits:     m.limits,
+		Exposure:   m.exposureLocked(),
+		WindowPnl:  m.windowPnlLocked(m.now()),
+		Halted:     m.halted,
+		HaltReason: m.reason,
+		HaltedAt:   m.haltedAt,
+	}
+	for ticket := uint64(1); ticket <= m.next && len(s.Positions) < len(m.open); ticket++ {
+		if p, ok := m.open[ticket]; ok {
+			s.Positions = append(s.Positions, *p)
+		}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"solana-bot/internal/config"
+)
+
+func TestCheckLimits(t *testing.T) {
+	m := New(config.RiskConf{MaxExposure: 1000, MaxPerStrategy: 2, MaxPerLeader: 1, MaxPerLaunchpad: 3, MaxPoolShare: 0.1})
+
+	a, err := m.Check(Entry{Mint: "a", Strategy: "smart", Leader: "l1", Launchpad: "PumpFun", Amount: 400, PoolSol: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []Entry{
+		{Mint: "b", Strategy: "mint", Launchpad: "PumpFun", Amount: 700},                // 总敞口
+		{Mint: "b", Strategy: "smart", Leader: "l1", Launchpad: "PumpFun", Amount: 100}, // 同一个钱包
+		{Mint: "b", Strategy: "mint", Launchpad: "PumpFun", Amount: 100, PoolSol: 500},  // 占池子比例
+	}
+	for _, e := range cases {
+		if _, err := m.Check(e); err == nil {
+			t.Fatalf("%+v accepted", e)
+		}
+	}
+
+	if _, err := m.Check(Entry{Mint: "b", Strategy: "smart", Leader: "l2", Launchpad: "PumpFun", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Check(Entry{Mint: "c", Strategy: "smart", Launchpad: "PumpAmm", Amount: 100}); err == nil {
+		t.Fatal("third smart position accepted")
+	}
+
+	m.Release(a)
+	if s := m.Status(); s.Exposure != 100 || len(s.Positions) != 1 {
+		t.Fatalf("status = %+v", s)
+	}
+	if _, err := m.Check(Entry{Mint: "c", Strategy: "smart", Leader: "l1", Launchpad: "PumpAmm", Amount: 800}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLossHalt(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := New(config.RiskConf{LossLimit: 100, LossWindow: time.Hour})
+	m.now = func() time.Time { return now }
+	var halts []string
+	m.OnHalt(func(reason string) { halts = append(halts, reason) })
+
+	open := func() uint64 {
+		ticket, err := m.Check(Entry{Mint: "m", Strategy: "mint", Amount: 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ticket
+	}
+
+	m.Close(open(), -80)
+	// 窗口外的亏损不再计入
+	now = now.Add(2 * time.Hour)
+	m.Close(open(), -60)
+	m.Close(open(), 20)
+	if m.Halted() {
+		t.Fatalf("halted early: %+v", m.Status())
+	}
+
+	ticket := open()
+	m.Close(ticket, -70)
+	m.Close(ticket, -70)
+	if !m.Halted() || len(halts) != 1 {
+		t.Fatalf("halted = %v, callbacks = %v", m.Halted(), halts)
+	}
+	if _, err := m.Check(Entry{Mint: "n", Strategy: "mint", Amount: 1}); !errors.Is(err, ErrHalted) {
+		t.Fatalf("check while halted = %v", err)
+	}
+
+	if !m.Reset() {
+		t.Fatal("reset reported not halted")
+	}
+	if s := m.Status(); s.Halted || s.WindowPnl != 0 {
+		t.Fatalf("after reset = %+v", s)
+	}
+	open()
+}
+
+func TestSaveRestore(t *testing.T) {
+	m := New(config.RiskConf{LossLimit: 100, LossWindow: time.Hour})
+	var saved Saved
+	m.OnChange(func(s Saved) { saved = s })
+	ticket, err := m.Check(Entry{Mint: "m", Strategy: "mint", Amount: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close(ticket, -150)
+	if !saved.Halted || len(saved.Realized) != 1 || saved.Realized[0].Pnl != -150 {
+		t.Fatalf("saved = %+v", saved)
+	}
+
+	// 重启后熔断还在，窗口内的亏损继续计入
+	restarted := New(config.RiskConf{LossLimit: 100, LossWindow: time.Hour})
+	restarted.Restore(saved)
+	if _, err := restarted.Check(Entry{Mint: "n", Strategy: "mint", Amount: 1}); !errors.Is(err, ErrHalted) {
+		t.Fatalf("check after restore = %v", err)
+	}
+	if s := restarted.Status(); s.WindowPnl != -150 || s.HaltReason != saved.Reason {
+		t.Fatalf("restored = %+v", s)
+	}
+
+	m.Reset()
+	if saved.Halted || len(saved.Realized) != 0 {
+		t.Fatalf("saved after reset = %+v", saved)
+	}
+}
@@ -2,9 +2,11 @@ package rpcs
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math/rand"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/system"
@@ -12,8 +14,6 @@ import (
 )
 
 var (
-	slotName   = "0slot"
-	slotUrl    = "https://de.0slot.trade?api-key="
 	slotTipKey = []string{
 		"4HiwLEP2Bzqj3hM2ENxJuzhcPCdsafwiet3oGkMkuQY4",
 		"6fQaVhYZA4w3MBSXjJ81Vf6W1EDYeUPXpgVQ6UQyU1Av",
@@ -30,54 +30,64 @@ var (
 )
 
 type SlotChannel struct {
-	rpcClient *rpc.Client
+	name       string
+	rpcClients []*rpc.Client
+	tips       []string
 }
 
-func NewSlotChannel() *SlotChannel {
-	rpcClient := rpc.New(slotUrl)
+// NewSlotChannel urls 为各区域完整的发送地址（已带 key），tips 为空时用内置小费账户
+func NewSlotChannel(name string, urls []string, tips []string) *SlotChannel {
+	rpcClients := make([]*rpc.Client, 0, len(urls))
+	for _, url := range urls {
+		rpcClients = append(rpcClients, rpc.New(url))
+	}
+	if len(tips) == 0 {
+		tips = slotTipKey
+	}
 	return &SlotChannel{
-		rpcClient: rpcClient,
+		name:       name,
+		rpcClients: rpcClients,
+		tips:       tips,
 	}
 }
 
+func (c *SlotChannel) String() string {
+	return c.name
+}
+
 func (c *SlotChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
-	randomIndex := rand.Intn(len(slotTipKey))
-	tipPublicKey := solana.MustPublicKeyFromBase58(slotTipKey[randomIndex])
+	randomIndex := rand.Intn(len(c.tips))
+	tipPublicKey := solana.MustPublicKeyFromBase58(c.tips[randomIndex])
 	return system.NewTransferInstruction(tip, owner, tipPublicKey).Build()
 }
 
-func (c *SlotChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error) {
+func (c *SlotChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
 
 	txBuilder.AddInstruction(c.GetTipInstruction(wallet.PublicKey(), tip))
 
-	tx, err := txBuilder.BuildTx([]solana.PrivateKey{wallet})
+	tx, err := txBuilder.BuildTx(wallet)
 	if err != nil {
 		return "", err
 	}
 
-	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &wallet
-		},
-	)
-	if err != nil {
-		return "", err
+	// 各区域依次发送，有一个成功即可
+	var lastErr error = errors.New("no endpoint")
+	for _, rpcClient := range c.rpcClients {
+		sig, err := rpcClient.SendTransactionWithOpts(
+			context.TODO(),
+			tx,
+			rpc.TransactionOpts{
+				SkipPreflight:       true,
+				PreflightCommitment: rpc.CommitmentConfirmed,
+			},
+		)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		log.Printf("✅ [%s] tx sent: %s", c.name, sig)
+		return sig.String(), nil
 	}
 
-	sig, err := c.rpcClient.SendTransactionWithOpts(
-		context.TODO(),
-		tx,
-		rpc.TransactionOpts{
-			SkipPreflight:       true,
-			PreflightCommitment: rpc.CommitmentConfirmed,
-		},
-	)
-	if err != nil {
-		return "", err
-	}
-
-	log.Printf("✅ [%s] tx sent: %s", slotName, sig)
-
-	return sig.String(), nil
+	return "", lastErr
 }
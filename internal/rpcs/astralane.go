@@ -4,9 +4,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"math/rand"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
@@ -20,52 +20,44 @@ type AstralaneChannel struct {
 	Tips []string
 }
 
-func NewAstralaneChannel() *AstralaneChannel {
-	apiKey := "workbOV3jJl2xQa0YtLdZUWH2sUcNmOwUf7Tk88EfPb630nshyAvhDvepWJOJ4i0"
+var astralaneTips = []string{
+	"astrazznxsGUhWShqgNtAdfrzP2G83DzcWVJDxwV9bF",
+	"astra4uejePWneqNaJKuFFA8oonqCE1sqF6b45kDMZm",
+	"astra9xWY93QyfG6yM8zwsKsRodscjQ2uU2HKNL5prk",
+	"astraRVUuTHjpwEVvNBeQEgwYx9w9CFyfxjYoobCZhL",
+	"astraEJ2fEj8Xmy6KLG7B3VfbKfsHXhHrNdCQx7iGJK",
+	"astraubkDw81n4LuutzSQ8uzHCv4BhPVhfvTcYv8SKC",
+	"astraZW5GLFefxNPAatceHhYjfA1ciq9gvfEg2S47xk",
+	"astrawVNP4xDBKT7rAdxrLYiTSTdqtUr63fSMduivXK",
+}
+
+// NewAstralaneChannel urls 为各区域完整的发送地址（已带 key），tips 为空时用内置小费账户
+func NewAstralaneChannel(name string, urls []string, tips []string) *AstralaneChannel {
+	if len(tips) == 0 {
+		tips = astralaneTips
+	}
 	return &AstralaneChannel{
-		Name: "astralane",
-		Urls: []string{
-			// fmt.Sprintf("http://fr.gateway.astralane.io/iris?api-key=%s", apiKey),
-			// fmt.Sprintf("http://lax.gateway.astralane.io/iris?api-key=%s", apiKey),
-			// fmt.Sprintf("http://jp.gateway.astralane.io/iris?api-key=%s", apiKey),
-			fmt.Sprintf("http://ny.gateway.astralane.io/iris?api-key=%s", apiKey),
-			// fmt.Sprintf("http://ams.gateway.astralane.io/iris?api-key=%s", apiKey),
-			// fmt.Sprintf("http://lim.gateway.astralane.io/iris?api-key=%s", apiKey),
-		},
-		Tips: []string{
-			"astrazznxsGUhWShqgNtAdfrzP2G83DzcWVJDxwV9bF",
-			"astra4uejePWneqNaJKuFFA8oonqCE1sqF6b45kDMZm",
-			"astra9xWY93QyfG6yM8zwsKsRodscjQ2uU2HKNL5prk",
-			"astraRVUuTHjpwEVvNBeQEgwYx9w9CFyfxjYoobCZhL",
-			"astraEJ2fEj8Xmy6KLG7B3VfbKfsHXhHrNdCQx7iGJK",
-			"astraubkDw81n4LuutzSQ8uzHCv4BhPVhfvTcYv8SKC",
-			"astraZW5GLFefxNPAatceHhYjfA1ciq9gvfEg2S47xk",
-			"astrawVNP4xDBKT7rAdxrLYiTSTdqtUr63fSMduivXK",
-		},
+		Name: name,
+		Urls: urls,
+		Tips: tips,
 	}
 }
 
+func (f *AstralaneChannel) String() string {
+	return f.Name
+}
+
 func (f *AstralaneChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
 	randomIndex := rand.Intn(len(f.Tips))
 	tipPublicKey := solana.MustPublicKeyFromBase58(f.Tips[randomIndex])
 	return system.NewTransferInstruction(tip, owner, tipPublicKey).Build()
 }
 
-func (f *AstralaneChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error) {
+func (f *AstralaneChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
 
 	txBuilder.AddInstruction(f.GetTipInstruction(wallet.PublicKey(), tip))
 
-	tx, err := txBuilder.BuildTx([]solana.PrivateKey{wallet})
-	if err != nil {
-		return "", err
-	}
-
-	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &wallet
-		},
-	)
+	tx, err := txBuilder.BuildTx(wallet)
 	if err != nil {
 		return "", err
 	}
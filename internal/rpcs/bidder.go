@@ -0,0 +1,256 @@
+package rpcs
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"solana-bot/internal/config"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 出价阶梯相邻两档的倍数
+	tipRatio = 1.5
+	// 只用这段时间内的结果估算落地概率，行情冷热变化后能较快跟上
+	bidWindow = 10 * time.Minute
+	// 每档最多保留的结果数
+	bucketHistory = 64
+	// 向下试探一档的概率，避免一直停在偏高的出价
+	bidExplore = 0.1
+	// 平滑用的先验：相当于每档先有 bidPriorWeight 笔、一半落地
+	bidPriorWeight = 2.0
+	// 没有任何结果也没有建议值时的出价
+	defaultTip = uint64(1e6)
+)
+
+type bidOutcome struct {
+	at      time.Time
+	success bool
+}
+
+type tipBucket struct {
+	tip      uint64
+	outcomes []bidOutcome
+}
+
+type channelBids struct {
+	buckets []*tipBucket
+	sent    uint64
+	landed  uint64
+	slots   uint64 // 已落地交易的 slot 延迟之和
+	last    uint64 // 最近一次出价
+}
+
+// Bidder 按发送通道记录每笔交易的小费和落地结果，
+// 给出能以目标概率在 TargetSlots 内落地的最低出价
+type Bidder struct {
+	mu       sync.Mutex
+	conf     config.TipConf
+	ladder   []uint64
+	channels map[string]*channelBids
+	rand     func() float64
+	now      func() time.Time
+}
+
+func NewBidder(conf config.TipConf) *Bidder {
+	if conf.Target <= 0 || conf.Target >= 1 {
+		conf.Target = 0.8
+	}
+	if conf.TargetSlots == 0 {
+		conf.TargetSlots = 2
+	}
+	if conf.Min == 0 {
+		conf.Min = 1e5
+	}
+	if conf.Max < conf.Min {
+		conf.Max = conf.Min
+	}
+
+	var ladder []uint64
+	for tip := float64(conf.Min); uint64(tip) < conf.Max; tip *= tipRatio {
+		ladder = append(ladder, uint64(tip))
+	}
+	ladder = append(ladder, conf.Max)
+
+	return &Bidder{
+		conf:     conf,
+		ladder:   ladder,
+		channels: make(map[string]*channelBids),
+		rand:     rand.Float64,
+		now:      time.Now,
+	}
+}
+
+func (b *Bidder) channel(name string) *channelBids {
+	c, ok := b.channels[name]
+	if !ok {
+		c = &channelBids{buckets: make([]*tipBucket, len(b.ladder))}
+		for i, tip := range b.ladder {
+			c.buckets[i] = &tipBucket{tip: tip}
+		}
+		b.channels[name] = c
+	}
+	return c
+}
+
+// bucketOf 返回不高于 tip 的最高一档，低于最低档返回 -1
+func (b *Bidder) bucketOf(tip uint64) int {
+	return sort.Search(len(b.ladder), func(i int) bool { return b.ladder[i] > tip }) - 1
+}
+
+// probabilities 每档的平滑落地概率（按出价单调不减）和样本数
+func (b *Bidder) probabilities(c *channelBids, now time.Time) ([]float64, []int) {
+	probs := make([]float64, len(c.buckets))
+	counts := make([]int, len(c.buckets))
+	best := 0.0
+	for i, bucket := range c.buckets {
+		var n, ok float64
+		for _, o := range bucket.outcomes {
+			if now.Sub(o.at) > bidWindow {
+				continue
+			}
+			n++
+			if o.success {
+				ok++
+			}
+		}
+		p := (ok + bidPriorWeight/2) / (n + bidPriorWeight)
+		// 出价更高的档落地概率不应更低
+		if p < best {
+			p = best
+		}
+		best = p
+		probs[i] = p
+		counts[i] = int(n)
+	}
+	return probs, counts
+}
+
+// Bid 返回 channel 下一笔交易的小费（lamports）；hint 为外部建议的小费，
+// 在该通道还没有近期结果时作为起点
+func (b *Bidder) Bid(channel string, hint uint64) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.channel(channel)
+	probs, counts := b.probabilities(c, b.now())
+
+	chosen, highest := -1, -1
+	for i := range probs {
+		if counts[i] > 0 {
+			highest = i
+		}
+		if chosen < 0 && counts[i] > 0 && probs[i] >= b.conf.Target {
+			chosen = i
+		}
+	}
+	switch {
+	case highest < 0:
+		if hint == 0 {
+			hint = defaultTip
+		}
+		chosen = max(b.bucketOf(hint), 0)
+	case chosen < 0:
+		// 试过的档都达不到目标，往上加一档
+		chosen = min(highest+1, len(b.ladder)-1)
+	case chosen > 0 && b.rand() < bidExplore:
+		// 偶尔试一下更便宜的一档
+		chosen--
+	}
+
+	c.last = b.ladder[chosen]
+	return c.last
+}
+
+// Record 记录一笔交易的结果，slots 为从发送到落地经过的 slot 数
+func (b *Bidder) Record(channel string, tip uint64, landed bool, slots uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.channel(channel)
+	c.sent++
+	if landed {
+		c.landed++
+		c.slots += slots
+	}
+	i := b.bucketOf(tip)
+	if i < 0 {
+		return
+	}
+	bucket := c.buckets[i]
+	bucket.outcomes = append(bucket.outcomes, bidOutcome{
+		at:      b.now(),
+		success: landed && slots <= b.conf.TargetSlots,
+	})
+	if len(bucket.outcomes) > bucketHistory {
+		bucket.outcomes = bucket.outcomes[len(bucket.outcomes)-bucketHistory:]
+	}
+}
+
+// BucketReport 一档出价的近期统计
+type BucketReport struct {
+	Tip         uint64
+	Samples     int
+	Probability float64
+}
+
+// BidReport 一个通道的出价模型和累计统计
+type BidReport struct {
+	Channel  string
+	Tip      uint64 // 最近一次出价
+	Sent     uint64
+	Landed   uint64
+	AvgSlots float64
+	Buckets  []BucketReport
+}
+
+// Report 返回各通道当前的出价模型，按通道名排序
+func (b *Bidder) Report() []BidReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	out := make([]BidReport, 0, len(b.channels))
+	for name, c := range b.channels {
+		r := BidReport{Channel: name, Tip: c.last, Sent: c.sent, Landed: c.landed}
+		if c.landed > 0 {
+			r.AvgSlots = float64(c.slots) / float64(c.landed)
+		}
+		probs, counts := b.probabilities(c, now)
+		for i, bucket := range c.buckets {
+			r.Buckets = append(r.Buckets, BucketReport{Tip: bucket.tip, Samples: counts[i], Probability: probs[i]})
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Channel < out[j].Channel })
+	return out
+}
+
+// Run 每隔 interval 打印一次各通道的出价和落地情况
+func (b *Bidder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, r := range b.Report() {
+				if r.Sent == 0 {
+					continue
+				}
+				logx.Infof("[bidder] %s: tip %d, sent %d, landed %d (%.1f%%), avg %.1f slots",
+					r.Channel, r.Tip, r.Sent, r.Landed, float64(r.Landed)/float64(r.Sent)*100, r.AvgSlots)
+				for _, bucket := range r.Buckets {
+					if bucket.Samples > 0 {
+						logx.Infof("[bidder] %s: tip %d, %d samples, p=%.2f", r.Channel, bucket.Tip, bucket.Samples, bucket.Probability)
+					}
+				}
+			}
+		}
+	}
+}
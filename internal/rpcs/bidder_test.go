@@ -0,0 +1,65 @@
+package rpcs
+
+import (
+	"testing"
+	"time"
+
+	"solana-bot/internal/config"
+)
+
+func TestBidderAdapts(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewBidder(config.TipConf{Target: 0.8, TargetSlots: 2, Min: 1e5, Max: 1e6})
+	b.now = func() time.Time { return now }
+	b.rand = func() float64 { return 1 } // 不做向下试探
+
+	if b.ladder[0] != 1e5 || b.ladder[len(b.ladder)-1] != 1e6 {
+		t.Fatalf("ladder = %v", b.ladder)
+	}
+
+	// 没有数据时从建议值附近开始
+	first := b.Bid("Jito", 3e5)
+	if first != b.ladder[b.bucketOf(3e5)] {
+		t.Fatalf("first bid = %d", first)
+	}
+
+	// 没落地就往上加一档
+	b.Record("Jito", first, false, 0)
+	second := b.Bid("Jito", 3e5)
+	if second <= first {
+		t.Fatalf("bid after miss = %d, want > %d", second, first)
+	}
+
+	// 稳定落地后停在达标的最低档
+	for i := 0; i < 6; i++ {
+		b.Record("Jito", second, true, 1)
+	}
+	if got := b.Bid("Jito", 3e5); got != second {
+		t.Fatalf("settled bid = %d, want %d", got, second)
+	}
+
+	// 落地太慢不算成功
+	for i := 0; i < 10; i++ {
+		b.Record("Blockrazor", first, true, 5)
+	}
+	if got := b.Bid("Blockrazor", 0); got <= first {
+		t.Fatalf("slow landing bid = %d, want > %d", got, first)
+	}
+
+	// 向下试探
+	b.rand = func() float64 { return 0 }
+	if got := b.Bid("Jito", 3e5); got >= second {
+		t.Fatalf("explore bid = %d, want < %d", got, second)
+	}
+
+	// 旧结果过期后回到建议值
+	now = now.Add(bidWindow + time.Second)
+	if got := b.Bid("Jito", 3e5); got != first {
+		t.Fatalf("bid after window = %d, want %d", got, first)
+	}
+
+	reports := b.Report()
+	if len(reports) != 2 || reports[0].Channel != "Blockrazor" || reports[1].Sent != 7 || reports[1].Landed != 6 {
+		t.Fatalf("report = %+v", reports)
+	}
+}
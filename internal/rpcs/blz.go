@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"math/rand"
-	"os"
 
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 	"time"
 
 	"github.com/BlockRazorinc/solana-trader-client-go/pb/serverpb"
@@ -27,42 +27,46 @@ type BlzChannel struct {
 	Tips    []string
 }
 
-func NewBlzChannel() *BlzChannel {
-	clients := []serverpb.ServerClient{
-		// GetGrpcClient("frankfurt.solana-grpc.blockrazor.xyz:80"),
-		GetGrpcClient("newyork.solana-grpc.blockrazor.xyz:80"),
-		// GetGrpcClient("amsterdam.solana-grpc.blockrazor.xyz:80"),
-		// GetGrpcClient("tokyo.solana-grpc.blockrazor.xyz:80"),
+var blzTips = []string{
+	"Gywj98ophM7GmkDdaWs4isqZnDdFCW7B46TXmKfvyqSm",
+	"FjmZZrFvhnqqb9ThCuMVnENaM3JGVuGWNyCAxRJcFpg9",
+	"6No2i3aawzHsjtThw81iq1EXPJN6rh8eSJCLaYZfKDTG",
+	"A9cWowVAiHe9pJfKAj3TJiN9VpbzMUq6E4kEvf5mUT22",
+	"68Pwb4jS7eZATjDfhmTXgRJjCiZmw1L7Huy4HNpnxJ3o",
+	"4ABhJh5rZPjv63RBJBuyWzBK3g9gWMUQdTZP2kiW31V9",
+	"B2M4NG5eyZp5SBQrSdtemzk5TqVuaWGQnowGaCBt8GyM",
+	"5jA59cXMKQqZAVdtopv8q3yyw9SYfiE3vUCbt7p8MfVf",
+	"5YktoWygr1Bp9wiS1xtMtUki1PeYuuzuCF98tqwYxf61",
+	"295Avbam4qGShBYK7E9H5Ldew4B3WyJGmgmXfiWdeeyV",
+	"EDi4rSy2LZgKJX74mbLTFk4mxoTgT6F7HxxzG2HBAFyK",
+	"BnGKHAC386n4Qmv9xtpBVbRaUTKixjBe3oagkPFKtoy6",
+	"Dd7K2Fp7AtoN8xCghKDRmyqr5U169t48Tw5fEd3wT9mq",
+	"AP6qExwrbRgBAVaehg4b5xHENX815sMabtBzUzVB4v8S",
+}
+
+// NewBlzChannel endpoints 为各区域的 gRPC 地址，authKey 为 BlockRazor 的鉴权 key，tips 为空时用内置小费账户
+func NewBlzChannel(name string, endpoints []string, authKey string, tips []string) *BlzChannel {
+	clients := make([]serverpb.ServerClient, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		clients = append(clients, GetGrpcClient(endpoint, authKey))
+	}
+	if len(tips) == 0 {
+		tips = blzTips
 	}
 
 	return &BlzChannel{
-		Name:    "Blockrazor",
+		Name:    name,
 		Clients: clients,
-		Tips: []string{
-			"Gywj98ophM7GmkDdaWs4isqZnDdFCW7B46TXmKfvyqSm",
-			"FjmZZrFvhnqqb9ThCuMVnENaM3JGVuGWNyCAxRJcFpg9",
-			"6No2i3aawzHsjtThw81iq1EXPJN6rh8eSJCLaYZfKDTG",
-			"A9cWowVAiHe9pJfKAj3TJiN9VpbzMUq6E4kEvf5mUT22",
-			"68Pwb4jS7eZATjDfhmTXgRJjCiZmw1L7Huy4HNpnxJ3o",
-			"4ABhJh5rZPjv63RBJBuyWzBK3g9gWMUQdTZP2kiW31V9",
-			"B2M4NG5eyZp5SBQrSdtemzk5TqVuaWGQnowGaCBt8GyM",
-			"5jA59cXMKQqZAVdtopv8q3yyw9SYfiE3vUCbt7p8MfVf",
-			"5YktoWygr1Bp9wiS1xtMtUki1PeYuuzuCF98tqwYxf61",
-			"295Avbam4qGShBYK7E9H5Ldew4B3WyJGmgmXfiWdeeyV",
-			"EDi4rSy2LZgKJX74mbLTFk4mxoTgT6F7HxxzG2HBAFyK",
-			"BnGKHAC386n4Qmv9xtpBVbRaUTKixjBe3oagkPFKtoy6",
-			"Dd7K2Fp7AtoN8xCghKDRmyqr5U169t48Tw5fEd3wT9mq",
-			"AP6qExwrbRgBAVaehg4b5xHENX815sMabtBzUzVB4v8S",
-		},
+		Tips:    tips,
 	}
 }
 
-func GetGrpcClient(blzRelayEndpoint string) serverpb.ServerClient {
+func GetGrpcClient(blzRelayEndpoint string, authKey string) serverpb.ServerClient {
 	// setup grpc connect
 	conn, err := grpc.NewClient(blzRelayEndpoint,
 		// grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})), // Enable tls configuration when connecting to a genernal endpoint
 		grpc.WithTransportCredentials(insecure.NewCredentials()), // regional endpoints
-		grpc.WithPerRPCCredentials(&Authentication{os.Getenv("BLZ_AUTH_KEY")}),
+		grpc.WithPerRPCCredentials(&Authentication{authKey}),
 	)
 	if err != nil {
 		logx.Must(err)
@@ -76,25 +80,19 @@ func GetGrpcClient(blzRelayEndpoint string) serverpb.ServerClient {
 	return client
 }
 
+func (c *BlzChannel) String() string {
+	return c.Name
+}
+
 func (c *BlzChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
 	randomIndex := rand.Intn(len(c.Tips))
 	tipPublicKey := solana.MustPublicKeyFromBase58(c.Tips[randomIndex])
 	return system.NewTransferInstruction(tip, owner, tipPublicKey).Build()
 }
 
-func (c *BlzChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error) {
+func (c *BlzChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
 	txBuilder.AddInstruction(c.GetTipInstruction(wallet.PublicKey(), tip))
-	tx, err := txBuilder.BuildTx([]solana.PrivateKey{wallet})
-	if err != nil {
-		return "", err
-	}
-
-	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &wallet
-		},
-	)
+	tx, err := txBuilder.BuildTx(wallet)
 	if err != nil {
 		return "", err
 	}
@@ -2,8 +2,10 @@ package rpcs
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
@@ -16,66 +18,77 @@ import (
 )
 
 var (
-	jitoName = "Jito"
-	rpcAddr  = "https://mainnet.block-engine.jito.wtf"
+	rpcAddr = "https://mainnet.block-engine.jito.wtf"
 )
 
 type JitoChannel struct {
+	name    string
 	clients []*searcher_client.Client
+	tips    []solana.PublicKey
 }
 
-func NewJitoChannel() *JitoChannel {
+// NewJitoChannel endpoints 为 jito-go 的区域名（如 NY、AMS）或 block engine 地址，tips 为空时用主网小费账户；
+// 连不上的 endpoint 跳过，一个客户端都没有时返回错误
+func NewJitoChannel(name string, endpoints []string, tips []string) (*JitoChannel, error) {
 
 	clients := []*searcher_client.Client{}
 
-	for k, v := range jito_go.JitoEndpoints {
-		if k != "NY" {
-			continue
+	for _, endpoint := range endpoints {
+		blockEngineURL := endpoint
+		if info, ok := jito_go.JitoEndpoints[endpoint]; ok {
+			blockEngineURL = info.BlockEngineURL
 		}
 		ctx := context.Background()
 		client, err := searcher_client.NewNoAuth(
 			ctx,
-			v.BlockEngineURL,
+			blockEngineURL,
 			rpc.New(rpcAddr),
 			rpc.New(rpc.MainNetBeta_RPC),
 			"",
 			nil,
 		)
 		if err != nil {
-			logx.Errorf("创建 Jito 客户端失败: %v", err)
+			logx.Errorf("[%s] 创建 Jito 客户端失败 %s: %v", name, endpoint, err)
 			continue
 		}
 		clients = append(clients, client)
 	}
 
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("channel %s: no jito client for %v", name, endpoints)
+	}
+
+	tipAccounts := jito_go.MainnetTipAccounts
+	if len(tips) > 0 {
+		var err error
+		if tipAccounts, err = parseTips(name, tips); err != nil {
+			return nil, err
+		}
+	}
+
 	return &JitoChannel{
+		name:    name,
 		clients: clients,
-	}
+		tips:    tipAccounts,
+	}, nil
+}
+
+func (c *JitoChannel) String() string {
+	return c.name
 }
 
 func (c *JitoChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
-	randomIndex := rand.Intn(len(jito_go.MainnetTipAccounts))
-	tipPublicKey := jito_go.MainnetTipAccounts[randomIndex]
+	tipPublicKey := c.tips[rand.Intn(len(c.tips))]
 
 	return system.NewTransferInstruction(tip, owner, tipPublicKey).Build()
 }
 
-func (c *JitoChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error) {
+func (c *JitoChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
 	// max per bundle is 5 transactions
 	txns := make([]*solana.Transaction, 0, 5)
 
 	txBuilder.AddInstruction(c.GetTipInstruction(wallet.PublicKey(), tip))
-	tx, err := txBuilder.BuildTx([]solana.PrivateKey{wallet})
-	if err != nil {
-		return "", err
-	}
-
-	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &wallet
-		},
-	)
+	tx, err := txBuilder.BuildTx(wallet)
 	if err != nil {
 		return "", err
 	}
@@ -86,18 +99,18 @@ func (c *JitoChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBu
 
 	txns = append(txns, tx)
 
-	for _, c := range c.clients {
-		go func(c *searcher_client.Client) {
+	for _, client := range c.clients {
+		go func(client *searcher_client.Client) {
 			ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 			defer cancel()
-			_, err := c.BroadcastBundleWithConfirmation(ctx, txns)
+			_, err := client.BroadcastBundleWithConfirmation(ctx, txns)
 			if err != nil {
-				logx.Errorf("❌[%s] send err:%v", jitoName, err)
+				logx.Errorf("❌[%s] send err:%v", c.name, err)
 			}
-		}(c)
+		}(client)
 	}
 
-	logx.Infof("✅[%s] tx sent: %s", jitoName, txSignature)
+	logx.Infof("✅[%s] tx sent: %s", c.name, txSignature)
 
 	return txSignature, nil
 }
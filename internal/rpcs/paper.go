@@ -0,0 +1,230 @@
+package rpcs
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	paperSignatureFee = 5000
+	// 新建 ATA 押金，清仓关闭账户时退回
+	tokenAccountRent = 2039280
+	paperDecimals    = 6
+	// 交易落地前的延迟，约一个 slot；成交按这时的池子状态计算
+	DefaultPaperLatency = 400 * time.Millisecond
+)
+
+// 模拟成交失败时错误的指令序号，见 global.Trade.Programs
+const (
+	paperPoolIndex = iota
+	paperTokenIndex
+	paperSystemIndex
+)
+
+var ErrNoTrade = errors.New("paper: transaction has no trade")
+
+type paperAccount struct {
+	sol    uint64
+	tokens map[solana.PublicKey]uint64
+}
+
+// PaperChannel 不广播交易的发送通道：按交易附带的 global.Trade 在本地池子状态上模拟成交，
+// 每个钱包记一本模拟账，生成和 getTransaction 格式一致的结果交给 deliver
+type PaperChannel struct {
+	balance uint64 // 每个钱包的初始 SOL
+	latency time.Duration
+	deliver func(sig string, result *rpc.GetTransactionResult)
+
+	mu        sync.Mutex
+	accounts  map[solana.PublicKey]*paperAccount
+	onBalance func(owner solana.PublicKey, sol uint64)
+}
+
+// NewPaperChannel balance 为每个钱包的初始 lamports，deliver 接收模拟的执行结果
+func NewPaperChannel(balance uint64, latency time.Duration, deliver func(sig string, result *rpc.GetTransactionResult)) *PaperChannel {
+	return &PaperChannel{
+		balance:  balance,
+		latency:  latency,
+		deliver:  deliver,
+		accounts: make(map[solana.PublicKey]*paperAccount),
+	}
+}
+
+// OnBalance 每次成交后回调 owner 在模拟账上的 SOL；模拟账不写全局的钱包余额，那是真实钱包的
+func (c *PaperChannel) OnBalance(fn func(owner solana.PublicKey, sol uint64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onBalance = fn
+}
+
+func (c *PaperChannel) String() string {
+	return "paper"
+}
+
+// GetTipInstruction 小费只记账，不会真的转出
+func (c *PaperChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
+	return system.NewTransferInstruction(tip, owner, owner).Build()
+}
+
+// SendTransaction 不签名也不发送，返回一个随机签名，latency 之后交付成交结果
+func (c *PaperChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
+	trade := txBuilder.Trade()
+	if trade == nil || trade.Quote == nil {
+		return "", ErrNoTrade
+	}
+	var sig solana.Signature
+	rand.Read(sig[:])
+
+	go func() {
+		time.Sleep(c.latency)
+		c.deliver(sig.String(), c.Fill(wallet.PublicKey(), tip, trade))
+	}()
+	return sig.String(), nil
+}
+
+// Balance owner 在模拟账上的 SOL 和 mint 的数量
+func (c *PaperChannel) Balance(owner, mint solana.PublicKey) (sol, tokens uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	acc := c.account(owner)
+	return acc.sol, acc.tokens[mint]
+}
+
+func (c *PaperChannel) account(owner solana.PublicKey) *paperAccount {
+	acc, ok := c.accounts[owner]
+	if !ok {
+		acc = &paperAccount{sol: c.balance, tokens: make(map[solana.PublicKey]uint64)}
+		c.accounts[owner] = acc
+	}
+	return acc
+}
+
+// Fill 按 trade 立即成交并更新模拟账。失败时和链上一样只扣签名费，错误写在 Meta.Err：
+// SOL 不足为 System 的 Custom 1，卖出超过持仓为 Token 的 Custom 1，
+// 报价为 0 或低于 MinAmountOut 时按 trade.Program 的滑点错误返回
+func (c *PaperChannel) Fill(owner solana.PublicKey, tip uint64, trade *global.Trade) *rpc.GetTransactionResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc := c.account(owner)
+	preSol := acc.sol
+	preTokens, held := acc.tokens[trade.Mint]
+	cost := uint64(paperSignatureFee) + tip
+
+	var txErr interface{}
+	switch {
+	case trade.Buy:
+		if !held {
+			cost += tokenAccountRent
+		}
+		if acc.sol < trade.AmountIn+cost {
+			txErr = instructionError(paperSystemIndex, txerr.SystemInsufficientLamports.Code)
+			break
+		}
+		out := trade.Quote(trade.AmountIn)
+		if out == 0 || out < trade.MinAmountOut {
+			txErr = instructionError(paperPoolIndex, slippageCode(trade.Program, true))
+			break
+		}
+		acc.sol -= trade.AmountIn + cost
+		acc.tokens[trade.Mint] = preTokens + out
+
+	default:
+		if trade.AmountIn > preTokens {
+			txErr = instructionError(paperTokenIndex, txerr.TokenInsufficientFunds.Code)
+			break
+		}
+		out := trade.Quote(trade.AmountIn)
+		if out == 0 || out < trade.MinAmountOut {
+			txErr = instructionError(paperPoolIndex, slippageCode(trade.Program, false))
+			break
+		}
+		if acc.sol+out < cost {
+			// 卖出所得加余额付不起签名费和小费
+			txErr = instructionError(paperSystemIndex, txerr.SystemInsufficientLamports.Code)
+			break
+		}
+		acc.sol = acc.sol + out - cost
+		acc.tokens[trade.Mint] = preTokens - trade.AmountIn
+		if acc.tokens[trade.Mint] == 0 {
+			// 清仓时关闭 ATA，押金退回
+			delete(acc.tokens, trade.Mint)
+			acc.sol += tokenAccountRent
+		}
+	}
+	if txErr != nil {
+		acc.sol -= min(acc.sol, paperSignatureFee)
+	}
+	if c.onBalance != nil {
+		c.onBalance(owner, acc.sol)
+	}
+
+	blockTime := solana.UnixTimeSeconds(time.Now().Unix())
+	result := &rpc.GetTransactionResult{
+		Slot:      global.GetSlot(),
+		BlockTime: &blockTime,
+		Meta: &rpc.TransactionMeta{
+			Err:          txErr,
+			Fee:          paperSignatureFee,
+			PreBalances:  []uint64{preSol},
+			PostBalances: []uint64{acc.sol},
+		},
+	}
+	if held {
+		result.Meta.PreTokenBalances = []rpc.TokenBalance{tokenBalance(owner, trade.Mint, preTokens)}
+	}
+	if postTokens, ok := acc.tokens[trade.Mint]; ok {
+		result.Meta.PostTokenBalances = []rpc.TokenBalance{tokenBalance(owner, trade.Mint, postTokens)}
+	}
+	if txErr != nil {
+		logx.Infof("[paper] %s %s: %v", owner, trade.Mint, txErr)
+	}
+	return result
+}
+
+func instructionError(index int, code uint32) interface{} {
+	return map[string]interface{}{"InstructionError": []interface{}{index, map[string]interface{}{"Custom": code}}}
+}
+
+// slippageCode 各池子程序超出滑点的错误码，未知程序按 pump 的返回
+func slippageCode(program solana.PublicKey, buy bool) uint32 {
+	switch program {
+	case txerr.PumpAmmProgramID:
+		return txerr.PumpAmmExceededSlippage.Code
+	case txerr.DbcProgramID:
+		return txerr.DbcExceededSlippage.Code
+	case txerr.LaunchLabProgramID:
+		return txerr.LaunchLabExceededSlippage.Code
+	}
+	if buy {
+		return txerr.PumpTooMuchSolRequired.Code
+	}
+	return txerr.PumpTooLittleSolReceived.Code
+}
+
+func tokenBalance(owner, mint solana.PublicKey, amount uint64) rpc.TokenBalance {
+	program := solana.TokenProgramID
+	return rpc.TokenBalance{
+		AccountIndex: 1,
+		Owner:        &owner,
+		ProgramId:    &program,
+		Mint:         mint,
+		UiTokenAmount: &rpc.UiTokenAmount{
+			Amount:         strconv.FormatUint(amount, 10),
+			Decimals:       paperDecimals,
+			UiAmountString: strconv.FormatFloat(float64(amount)/1e6, 'f', -1, 64),
+		},
+	}
+}
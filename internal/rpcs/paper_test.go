@@ -0,0 +1,159 @@
+package rpcs
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
+	"solana-bot/internal/txerr"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// 固定价格：1 lamport 换 2 个 token 单位
+func fixedTrade(mint solana.PublicKey, buy bool, amountIn uint64) *global.Trade {
+	return &global.Trade{
+		Mint:     mint,
+		Buy:      buy,
+		AmountIn: amountIn,
+		Quote: func(in uint64) uint64 {
+			if buy {
+				return in * 2
+			}
+			return in / 2
+		},
+	}
+}
+
+func TestPaperFill(t *testing.T) {
+	c := NewPaperChannel(10e9, 0, nil)
+	owner, mint := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	var reported uint64
+	c.OnBalance(func(o solana.PublicKey, sol uint64) {
+		if o == owner {
+			reported = sol
+		}
+	})
+
+	buy := c.Fill(owner, 1e6, fixedTrade(mint, true, 1e9))
+	if buy.Meta.Err != nil {
+		t.Fatal(buy.Meta.Err)
+	}
+	// BuyDone 用的解析和真实交易一致
+	price, amount := global.GetBuyPriceAndAmount(buy, mint.String())
+	if amount == nil || amount.Uint64() != 2e9 {
+		t.Fatalf("amount = %v", amount)
+	}
+	spent := float64(1e9 + paperSignatureFee + 1e6 + tokenAccountRent)
+	if want := spent / 1e9 / (2e9 / 1e6); math.Abs(price-want) > 1e-15 {
+		t.Fatalf("price = %v, want %v", price, want)
+	}
+	if change, fee := global.GetBalacneChange(buy); change != -spent || fee != paperSignatureFee {
+		t.Fatalf("change = %v, fee = %v", change, fee)
+	}
+
+	sell := c.Fill(owner, 0, fixedTrade(mint, false, 2e9))
+	if sell.Meta.Err != nil {
+		t.Fatal(sell.Meta.Err)
+	}
+	if change := global.GetTokenChange(sell, owner.String(), mint.String()); change != -2e9 {
+		t.Fatalf("token change = %d", change)
+	}
+	// 清仓后 ATA 关闭，押金退回
+	if len(sell.Meta.PostTokenBalances) != 0 {
+		t.Fatalf("post token balances = %v", sell.Meta.PostTokenBalances)
+	}
+	sol, tokens := c.Balance(owner, mint)
+	if want := uint64(10e9 - 1e6 - 2*paperSignatureFee); sol != want || tokens != 0 {
+		t.Fatalf("balance = %d/%d, want %d/0", sol, tokens, want)
+	}
+	if reported != sol {
+		t.Fatalf("reported balance = %d, want %d", reported, sol)
+	}
+	// 模拟账不碰真实钱包的余额
+	if global.Balance(owner).SolKnown.Load() {
+		t.Fatal("paper fill wrote the global wallet balance")
+	}
+}
+
+func TestPaperFillErrors(t *testing.T) {
+	c := NewPaperChannel(1e9, 0, nil)
+	owner, mint := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	withProgram := func(trade *global.Trade, program solana.PublicKey) *global.Trade {
+		trade.Program = program
+		return trade
+	}
+	slippage := fixedTrade(mint, false, 100)
+	slippage.MinAmountOut = 51
+
+	cases := []struct {
+		trade *global.Trade
+		want  error
+	}{
+		{fixedTrade(mint, true, 2e9), txerr.SystemInsufficientLamports}, // SOL 不足
+		{fixedTrade(mint, false, 100), txerr.TokenInsufficientFunds},    // 没有持仓
+		{withProgram(&global.Trade{Mint: mint, Buy: true, AmountIn: 1e8, Quote: func(uint64) uint64 { return 0 }}, txerr.PumpProgramID), txerr.PumpTooMuchSolRequired},
+		{withProgram(&global.Trade{Mint: mint, Buy: true, AmountIn: 1e8, MinAmountOut: 2e8 + 1, Quote: fixedTrade(mint, true, 0).Quote}, txerr.PumpAmmProgramID), txerr.PumpAmmExceededSlippage}, // 超出滑点
+	}
+	for _, tc := range cases {
+		res := c.Fill(owner, 0, tc.trade)
+		if err := txerr.Decode(res.Meta.Err, tc.trade.Programs()); !errors.Is(err, tc.want) {
+			t.Fatalf("%+v: err = %v, want %v", tc.trade, err, tc.want)
+		}
+	}
+	// 失败的交易只扣签名费
+	if sol, _ := c.Balance(owner, mint); sol != 1e9-4*paperSignatureFee {
+		t.Fatalf("balance = %d", sol)
+	}
+
+	// 买完只剩持仓，卖出所得不够付小费时失败，不会下溢
+	sol, _ := c.Balance(owner, mint)
+	if res := c.Fill(owner, 0, fixedTrade(mint, true, sol-paperSignatureFee-tokenAccountRent)); res.Meta.Err != nil {
+		t.Fatal(res.Meta.Err)
+	}
+	trade := withProgram(fixedTrade(mint, false, 2), txerr.LaunchLabProgramID)
+	res := c.Fill(owner, 1e6, trade)
+	if err := txerr.Decode(res.Meta.Err, trade.Programs()); !errors.Is(err, txerr.SystemInsufficientLamports) {
+		t.Fatalf("err = %v, want %v", err, txerr.SystemInsufficientLamports)
+	}
+	if sol, _ := c.Balance(owner, mint); sol != 0 {
+		t.Fatalf("balance = %d, want 0", sol)
+	}
+	trade = withProgram(slippage, txerr.LaunchLabProgramID)
+	res = c.Fill(owner, 0, trade)
+	if err := txerr.Decode(res.Meta.Err, trade.Programs()); !errors.Is(err, txerr.LaunchLabExceededSlippage) {
+		t.Fatalf("err = %v, want %v", err, txerr.LaunchLabExceededSlippage)
+	}
+}
+
+func TestPaperSend(t *testing.T) {
+	delivered := make(chan string, 1)
+	c := NewPaperChannel(1e9, time.Millisecond, func(sig string, result *rpc.GetTransactionResult) {
+		if result.Meta.Err == nil {
+			delivered <- sig
+		}
+	})
+	wallet := signer.NewKeySigner(solana.NewWallet().PrivateKey)
+
+	b := global.NewTxBuilder(wallet.PublicKey(), solana.Hash{})
+	if _, err := c.SendTransaction(wallet, 0, *b); err != ErrNoTrade {
+		t.Fatalf("err = %v, want %v", err, ErrNoTrade)
+	}
+	b.SetTrade(fixedTrade(solana.NewWallet().PublicKey(), true, 1e8))
+	sig, err := c.SendTransaction(wallet, 0, *b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-delivered:
+		if got != sig {
+			t.Fatalf("delivered %s, want %s", got, sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not delivered")
+	}
+}
@@ -0,0 +1,212 @@
+package rpcs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"solana-bot/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+	jito_go "github.com/weeaa/jito-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 交易方向，用于按路由选择通道
+const (
+	Buy  = "buy"
+	Sell = "sell"
+)
+
+// DefaultChannels 配置里没有通道时使用，和之前写死的通道一致；0slot、Tempgoral 默认关闭
+var DefaultChannels = []config.ChannelConf{
+	{Name: "Jito", Type: "jito", Enabled: true, Weight: 1, Endpoints: []string{"NY"}},
+	{Name: "Blockrazor", Type: "blockrazor", Enabled: true, Weight: 1, Endpoints: []string{"newyork.solana-grpc.blockrazor.xyz:80"}, AuthEnv: "BLZ_AUTH_KEY"},
+	{Name: "astralane", Type: "astralane", Enabled: true, Weight: 1, Endpoints: []string{"http://ny.gateway.astralane.io/iris?api-key={auth}"}, AuthEnv: "ASTRALANE_API_KEY"},
+	{Name: "0slot", Type: "0slot", Weight: 1, Endpoints: []string{"https://de.0slot.trade?api-key={auth}"}, AuthEnv: "SLOT_API_KEY"},
+	{Name: "tempgoral", Type: "tempgoral", Weight: 1, Endpoints: []string{"http://ewr1.nozomi.temporal.xyz/?c={auth}"}, AuthEnv: "TEMPORAL_API_KEY"},
+}
+
+// NewChannel 按配置创建通道，鉴权 key 从 AuthEnv 指定的环境变量读取；小费账户不合法时返回错误
+func NewChannel(conf config.ChannelConf) (RpcChannel, error) {
+	if len(conf.Endpoints) == 0 {
+		return nil, fmt.Errorf("channel %s: no endpoints", conf.Name)
+	}
+	if _, err := parseTips(conf.Name, conf.Tips); err != nil {
+		return nil, err
+	}
+	var auth string
+	if conf.AuthEnv != "" {
+		auth = os.Getenv(conf.AuthEnv)
+		if auth == "" {
+			logx.Errorf("[rpcs] channel %s: env %s is empty", conf.Name, conf.AuthEnv)
+		}
+	}
+	endpoints := make([]string, len(conf.Endpoints))
+	for i, endpoint := range conf.Endpoints {
+		endpoints[i] = strings.ReplaceAll(endpoint, "{auth}", auth)
+	}
+
+	switch conf.Type {
+	case "jito":
+		return NewJitoChannel(conf.Name, endpoints, conf.Tips)
+	case "blockrazor":
+		return NewBlzChannel(conf.Name, endpoints, auth, conf.Tips), nil
+	case "astralane":
+		return NewAstralaneChannel(conf.Name, endpoints, conf.Tips), nil
+	case "0slot":
+		return NewSlotChannel(conf.Name, endpoints, conf.Tips), nil
+	case "tempgoral":
+		return NewTempgoralChannel(conf.Name, endpoints, conf.Tips), nil
+	}
+	return nil, fmt.Errorf("channel %s: unknown type %q", conf.Name, conf.Type)
+}
+
+// TipAccounts 配置中启用通道的小费账户，没有配置 tips 的通道用内置账户；channels 为空时使用 DefaultChannels
+func TipAccounts(channels []config.ChannelConf) ([]solana.PublicKey, error) {
+	if len(channels) == 0 {
+		channels = DefaultChannels
+	}
+	var out []solana.PublicKey
+	for _, conf := range channels {
+		if !conf.Enabled {
+			continue
+		}
+		tips := conf.Tips
+		if len(tips) == 0 {
+			switch conf.Type {
+			case "jito":
+				out = append(out, jito_go.MainnetTipAccounts...)
+			case "blockrazor":
+				tips = blzTips
+			case "astralane":
+				tips = astralaneTips
+			case "0slot":
+				tips = slotTipKey
+			case "tempgoral":
+				tips = tempgoralTips
+			}
+		}
+		keys, err := parseTips(conf.Name, tips)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, keys...)
+	}
+	return out, nil
+}
+
+// parseTips 解析通道配置的小费账户
+func parseTips(channel string, tips []string) ([]solana.PublicKey, error) {
+	out := make([]solana.PublicKey, 0, len(tips))
+	for _, tip := range tips {
+		key, err := solana.PublicKeyFromBase58(tip)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: tip account %q: %w", channel, tip, err)
+		}
+		out = append(out, key)
+	}
+	return out, nil
+}
+
+// Registry 按配置创建的发送通道，以及每个模式买卖方向使用的通道
+type Registry struct {
+	channels map[string]RpcChannel
+	enabled  []string // 按权重从高到低
+	routes   map[string]config.RouteConf
+}
+
+// NewRegistry 创建所有启用的通道并校验路由；channels 为空时使用 DefaultChannels
+func NewRegistry(channels []config.ChannelConf, routes map[string]config.RouteConf) (*Registry, error) {
+	if len(channels) == 0 {
+		channels = DefaultChannels
+	}
+
+	r := &Registry{
+		channels: make(map[string]RpcChannel),
+		routes:   make(map[string]config.RouteConf),
+	}
+	weights := make(map[string]int)
+	for _, conf := range channels {
+		if conf.Name == "" {
+			return nil, fmt.Errorf("channel of type %q has no name", conf.Type)
+		}
+		if _, ok := weights[conf.Name]; ok {
+			return nil, fmt.Errorf("duplicate channel %s", conf.Name)
+		}
+		weights[conf.Name] = conf.Weight
+		if !conf.Enabled {
+			continue
+		}
+		ch, err := NewChannel(conf)
+		if err != nil {
+			return nil, err
+		}
+		r.channels[conf.Name] = ch
+		r.enabled = append(r.enabled, conf.Name)
+	}
+	if len(r.enabled) == 0 {
+		return nil, fmt.Errorf("no enabled channel")
+	}
+	sort.SliceStable(r.enabled, func(i, j int) bool {
+		return weights[r.enabled[i]] > weights[r.enabled[j]]
+	})
+
+	for mode, route := range routes {
+		for _, name := range append(append([]string{}, route.Buy...), route.Sell...) {
+			if _, ok := r.channels[name]; !ok {
+				return nil, fmt.Errorf("route %s: channel %s is not enabled", mode, name)
+			}
+		}
+		r.routes[strings.ToLower(mode)] = route
+	}
+
+	logx.Infof("[rpcs] channels: %v", r.enabled)
+	return r, nil
+}
+
+// RegistryOf 用已经创建好的通道组成注册表，所有模式都使用这些通道，例如 paper 模式只有 PaperChannel
+func RegistryOf(channels ...RpcChannel) *Registry {
+	r := &Registry{
+		channels: make(map[string]RpcChannel),
+		routes:   make(map[string]config.RouteConf),
+	}
+	for _, ch := range channels {
+		r.channels[ch.String()] = ch
+		r.enabled = append(r.enabled, ch.String())
+	}
+	return r
+}
+
+// Route 返回 mode 模式下 side 方向（Buy/Sell）使用的通道，按权重从高到低；没有配置时返回所有启用的通道
+func (r *Registry) Route(mode, side string) []RpcChannel {
+	route := r.routes[strings.ToLower(mode)]
+	names := route.Buy
+	if side == Sell {
+		names = route.Sell
+	}
+	if len(names) == 0 {
+		return r.All()
+	}
+
+	out := make([]RpcChannel, 0, len(names))
+	for _, name := range r.enabled {
+		for _, want := range names {
+			if name == want {
+				out = append(out, r.channels[name])
+				break
+			}
+		}
+	}
+	return out
+}
+
+// All 返回所有启用的通道，按权重从高到低
+func (r *Registry) All() []RpcChannel {
+	out := make([]RpcChannel, 0, len(r.enabled))
+	for _, name := range r.enabled {
+		out = append(out, r.channels[name])
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package rpcs
+
+import (
+	"testing"
+
+	"solana-bot/internal/config"
+)
+
+func TestRegistryRoute(t *testing.T) {
+	t.Setenv("TEST_SLOT_KEY", "k1")
+	channels := []config.ChannelConf{
+		{Name: "a", Type: "astralane", Enabled: true, Weight: 1, Endpoints: []string{"http://a.example/?key={auth}"}},
+		{Name: "s", Type: "0slot", Enabled: true, Weight: 3, Endpoints: []string{"http://s.example/?api-key={auth}"}, AuthEnv: "TEST_SLOT_KEY"},
+		{Name: "t", Type: "tempgoral", Enabled: false, Endpoints: []string{"http://t.example/"}},
+	}
+	r, err := NewRegistry(channels, map[string]config.RouteConf{
+		"Mint": {Sell: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(chs []RpcChannel) []string {
+		var out []string
+		for _, ch := range chs {
+			out = append(out, ch.String())
+		}
+		return out
+	}
+	// 按权重排序，关闭的通道不出现
+	if got := names(r.All()); len(got) != 2 || got[0] != "s" || got[1] != "a" {
+		t.Fatalf("all = %v", got)
+	}
+	if got := names(r.Route("mint", Buy)); len(got) != 2 {
+		t.Fatalf("mint buy = %v", got)
+	}
+	if got := names(r.Route("mint", Sell)); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("mint sell = %v", got)
+	}
+	if got := names(r.Route("smart", Sell)); len(got) != 2 {
+		t.Fatalf("smart sell = %v", got)
+	}
+
+	slot := r.channels["s"].(*SlotChannel)
+	if len(slot.rpcClients) != 1 {
+		t.Fatalf("slot clients = %d", len(slot.rpcClients))
+	}
+	if a := r.channels["a"].(*AstralaneChannel); a.Urls[0] != "http://a.example/?key=" || len(a.Tips) == 0 {
+		t.Fatalf("astralane = %+v", a)
+	}
+
+	if _, err := NewRegistry(channels, map[string]config.RouteConf{"mint": {Buy: []string{"t"}}}); err == nil {
+		t.Fatal("route to disabled channel accepted")
+	}
+	if _, err := NewRegistry([]config.ChannelConf{{Name: "x", Type: "nope", Enabled: true, Endpoints: []string{"x"}}}, nil); err == nil {
+		t.Fatal("unknown type accepted")
+	}
+	bad := []config.ChannelConf{{Name: "b", Type: "astralane", Enabled: true, Endpoints: []string{"http://b.example/"}, Tips: []string{"not-a-key"}}}
+	if _, err := NewRegistry(bad, nil); err == nil {
+		t.Fatal("bad tip account accepted")
+	}
+	if _, err := TipAccounts(bad); err == nil {
+		t.Fatal("bad tip account listed")
+	}
+}
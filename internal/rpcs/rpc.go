@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
@@ -19,7 +20,9 @@ type RpcModel struct {
 }
 
 type RpcChannel interface {
-	SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error)
+	// String 通道名，用于日志和按通道统计
+	String() string
+	SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error)
 	GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction
 }
 
@@ -4,9 +4,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"math/rand"
 	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
@@ -20,61 +20,53 @@ type TempgoralChannel struct {
 	Tips []string
 }
 
-func NewTempgoralChannel() *TempgoralChannel {
-	apiKey := ""
+var tempgoralTips = []string{
+	"TEMPaMeCRFAS9EKF53Jd6KpHxgL47uWLcpFArU1Fanq",
+	"noz3jAjPiHuBPqiSPkkugaJDkJscPuRhYnSpbi8UvC4",
+	"noz3str9KXfpKknefHji8L1mPgimezaiUyCHYMDv1GE",
+	"noz6uoYCDijhu1V7cutCpwxNiSovEwLdRHPwmgCGDNo",
+	"noz9EPNcT7WH6Sou3sr3GGjHQYVkN3DNirpbvDkv9YJ",
+	"nozc5yT15LazbLTFVZzoNZCwjh3yUtW86LoUyqsBu4L",
+	"nozFrhfnNGoyqwVuwPAW4aaGqempx4PU6g6D9CJMv7Z",
+	"nozievPk7HyK1Rqy1MPJwVQ7qQg2QoJGyP71oeDwbsu",
+	"noznbgwYnBLDHu8wcQVCEw6kDrXkPdKkydGJGNXGvL7",
+	"nozNVWs5N8mgzuD3qigrCG2UoKxZttxzZ85pvAQVrbP",
+	"nozpEGbwx4BcGp6pvEdAh1JoC2CQGZdU6HbNP1v2p6P",
+	"nozrhjhkCr3zXT3BiT4WCodYCUFeQvcdUkM7MqhKqge",
+	"nozrwQtWhEdrA6W8dkbt9gnUaMs52PdAv5byipnadq3",
+	"nozUacTVWub3cL4mJmGCYjKZTnE9RbdY5AP46iQgbPJ",
+	"nozWCyTPppJjRuw2fpzDhhWbW355fzosWSzrrMYB1Qk",
+	"nozWNju6dY353eMkMqURqwQEoM3SFgEKC6psLCSfUne",
+	"nozxNBgWohjR75vdspfxR5H9ceC7XXH99xpxhVGt3Bb",
+}
+
+// NewTempgoralChannel urls 为各区域完整的发送地址（已带 key），tips 为空时用内置小费账户
+func NewTempgoralChannel(name string, urls []string, tips []string) *TempgoralChannel {
+	if len(tips) == 0 {
+		tips = tempgoralTips
+	}
 	return &TempgoralChannel{
-		Name: "astralane",
-		Urls: []string{
-			fmt.Sprintf("http://pit1.nozomi.temporal.xyz/?c=%s", apiKey),
-			fmt.Sprintf("http://tyo1.nozomi.temporal.xyz/?c=%s", apiKey),
-			fmt.Sprintf("http://fra2.nozomi.temporal.xyz/?c=%s", apiKey),
-			fmt.Sprintf("http://ewr1.nozomi.temporal.xyz/?c=%s", apiKey),
-			fmt.Sprintf("http://sgp1.nozomi.temporal.xyz/?c=%s", apiKey),
-			fmt.Sprintf("http://ams1.nozomi.temporal.xyz/?c=%s", apiKey),
-		},
-		Tips: []string{
-			"TEMPaMeCRFAS9EKF53Jd6KpHxgL47uWLcpFArU1Fanq",
-			"noz3jAjPiHuBPqiSPkkugaJDkJscPuRhYnSpbi8UvC4",
-			"noz3str9KXfpKknefHji8L1mPgimezaiUyCHYMDv1GE",
-			"noz6uoYCDijhu1V7cutCpwxNiSovEwLdRHPwmgCGDNo",
-			"noz9EPNcT7WH6Sou3sr3GGjHQYVkN3DNirpbvDkv9YJ",
-			"nozc5yT15LazbLTFVZzoNZCwjh3yUtW86LoUyqsBu4L",
-			"nozFrhfnNGoyqwVuwPAW4aaGqempx4PU6g6D9CJMv7Z",
-			"nozievPk7HyK1Rqy1MPJwVQ7qQg2QoJGyP71oeDwbsu",
-			"noznbgwYnBLDHu8wcQVCEw6kDrXkPdKkydGJGNXGvL7",
-			"nozNVWs5N8mgzuD3qigrCG2UoKxZttxzZ85pvAQVrbP",
-			"nozpEGbwx4BcGp6pvEdAh1JoC2CQGZdU6HbNP1v2p6P",
-			"nozrhjhkCr3zXT3BiT4WCodYCUFeQvcdUkM7MqhKqge",
-			"nozrwQtWhEdrA6W8dkbt9gnUaMs52PdAv5byipnadq3",
-			"nozUacTVWub3cL4mJmGCYjKZTnE9RbdY5AP46iQgbPJ",
-			"nozWCyTPppJjRuw2fpzDhhWbW355fzosWSzrrMYB1Qk",
-			"nozWNju6dY353eMkMqURqwQEoM3SFgEKC6psLCSfUne",
-			"nozxNBgWohjR75vdspfxR5H9ceC7XXH99xpxhVGt3Bb",
-		},
+		Name: name,
+		Urls: urls,
+		Tips: tips,
 	}
 }
 
+func (f *TempgoralChannel) String() string {
+	return f.Name
+}
+
 func (f *TempgoralChannel) GetTipInstruction(owner solana.PublicKey, tip uint64) solana.Instruction {
 	randomIndex := rand.Intn(len(f.Tips))
 	tipPublicKey := solana.MustPublicKeyFromBase58(f.Tips[randomIndex])
 	return system.NewTransferInstruction(tip, owner, tipPublicKey).Build()
 }
 
-func (f *TempgoralChannel) SendTransaction(wallet solana.PrivateKey, tip uint64, txBuilder global.TxBuilder) (string, error) {
+func (f *TempgoralChannel) SendTransaction(wallet signer.Signer, tip uint64, txBuilder global.TxBuilder) (string, error) {
 
 	txBuilder.AddInstruction(f.GetTipInstruction(wallet.PublicKey(), tip))
 
-	tx, err := txBuilder.BuildTx([]solana.PrivateKey{wallet})
-	if err != nil {
-		return "", err
-	}
-
-	// 签名交易
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			return &wallet
-		},
-	)
+	tx, err := txBuilder.BuildTx(wallet)
 	if err != nil {
 		return "", err
 	}
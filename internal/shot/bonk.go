@@ -22,6 +22,11 @@ func (a *BonkAdapter) Name() string {
 	return "Bonk"
 }
 
+// FeeAccounts 交易会写锁定的 LaunchLab pool state，accounts 与 BuildInstructions 相同
+func (a *BonkAdapter) FeeAccounts(accounts ...solana.PublicKey) []string {
+	return feeKeys(accounts[3])
+}
+
 func (a *BonkAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.PublicKey) []solana.Instruction {
 	isBuy := true
 	srcMint := txInfo.SrcMint
@@ -76,7 +81,7 @@ func (a *BonkAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.Pu
 		base_amount_out := PredictNextOutputByRealState(virtualBase, virtualQuote, realBaseAfter, realQuoteAfter, amountIn)
 
 		// base_amount_out := EstimateSwapOut(big.NewInt(int64(maxAmountIn)), quote_balance_sol, base_balance_tokens, FeeBasisPoints)
-		base_amount_out = applySlippage(base_amount_out, slippage)
+		base_amount_out = ApplySlippage(base_amount_out, slippage)
 
 		global.CreateSOLAccountOrWrap(&instrs, signerAndOwner.PublicKey(), big.NewInt(int64(maxAmountIn)))
 
@@ -108,7 +113,7 @@ func (a *BonkAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.Pu
 		quote_amount_out := PredictSellExactInByRealState(virtualBase, virtualQuote, realBaseAfter, realQuoteAfter, amountIn)
 
 		// quote_amount_out := EstimateSwapOut(big.NewInt(int64(maxAmountIn)), base_balance_tokens, quote_balance_sol, FeeBasisPoints)
-		min_quote_amount_out := applySlippage(quote_amount_out, slippage).Uint64()
+		min_quote_amount_out := ApplySlippage(quote_amount_out, slippage).Uint64()
 
 		instrs = append(instrs, BonkSwap(
 			false,
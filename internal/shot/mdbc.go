@@ -23,6 +23,11 @@ func (a *MDbcAdapter) Name() string {
 	return "Meteora Dynamic Bonding Curve"
 }
 
+// FeeAccounts 交易会写锁定的 DBC 池子，accounts 与 BuildInstructions 相同
+func (a *MDbcAdapter) FeeAccounts(accounts ...solana.PublicKey) []string {
+	return feeKeys(accounts[2])
+}
+
 func (a *MDbcAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.PublicKey) []solana.Instruction {
 	isBuy := true
 	srcMint := txInfo.SrcMint
@@ -21,6 +21,11 @@ func (a *PumpAmmAdapter) Name() string {
 	return "PumpAmm"
 }
 
+// FeeAccounts 交易会写锁定的池子和两个 vault，accounts 与 BuildInstructions 相同
+func (a *PumpAmmAdapter) FeeAccounts(accounts ...solana.PublicKey) []string {
+	return feeKeys(accounts[1], accounts[3], accounts[4])
+}
+
 func (a *PumpAmmAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.PublicKey) []solana.Instruction {
 	isBuy := true
 	srcMint := txInfo.SrcMint
@@ -62,7 +67,7 @@ func (a *PumpAmmAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana
 
 	if isBuy {
 		base_amount_out := EstimateSwapOut(big.NewInt(int64(maxAmountIn)), quote_balance_sol, base_balance_tokens, FeeBasisPoints)
-		base_amount_out = applySlippage(base_amount_out, slippage)
+		base_amount_out = ApplySlippage(base_amount_out, slippage)
 
 		global.CreateSOLAccountOrWrap(&instrs, signerAndOwner.PublicKey(), big.NewInt(int64(maxAmountIn)))
 
@@ -73,7 +78,7 @@ func (a *PumpAmmAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana
 	} else {
 
 		quote_amount_out := EstimateSwapOut(big.NewInt(int64(maxAmountIn)), base_balance_tokens, quote_balance_sol, FeeBasisPoints)
-		min_quote_amount_out := applySlippage(quote_amount_out, slippage).Uint64()
+		min_quote_amount_out := ApplySlippage(quote_amount_out, slippage).Uint64()
 
 		addPumpAmmSellIx(&instrs, signerAndOwner.PublicKey(), maxAmountIn, min_quote_amount_out, pool, globalConfig, dstMint, srcMint, poolBaseTokenAccount, poolQuoteTokenAccount, protocolFeeRecipient, protocolFeeRecipientATA, coinCreatorVaultAta, coinCreatorVaultAuthority)
 
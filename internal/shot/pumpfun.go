@@ -23,6 +23,11 @@ func (a *PumpFunAdapter) Name() string {
 	return "pump.fun"
 }
 
+// FeeAccounts 交易会写锁定的 bonding curve，accounts 与 BuildInstructions 相同
+func (a *PumpFunAdapter) FeeAccounts(accounts ...solana.PublicKey) []string {
+	return feeKeys(accounts[3])
+}
+
 func (a *PumpFunAdapter) BuildInstructions(txInfo *TxContext, accounts ...solana.PublicKey) []solana.Instruction {
 	isBuy := true
 	srcMint := txInfo.SrcMint
@@ -89,7 +94,7 @@ func addPumpBuyIx(
 	// this is used for quoting
 	amountInAfterPumpFee := new(big.Int).Sub(amountInAfterOurFee, pumpFee)
 	amountOut := QuoteBuyByCurve(amountInAfterPumpFee, virtualSolReserves, virtualTokenReserves)
-	amountOutWithSlippage := applySlippage(amountOut, slippage)
+	amountOutWithSlippage := ApplySlippage(amountOut, slippage)
 
 	instruction := &PumpBuyInstruction{
 		MethodId:         PUMPBuyMethod,
@@ -147,7 +152,7 @@ func addPumpSellIx(
 	associatedBondingCurvePk solana.PublicKey,
 ) {
 	amountOut := QuoteSellByCurve(amountIn, virtualSolReserves, virtualTokenReserves)
-	amountOutWithSlippage := applySlippage(amountOut, slippage)
+	amountOutWithSlippage := ApplySlippage(amountOut, slippage)
 
 	instruction := &PumpSellInstruction{
 		MethodId:         PUMPSellMethod,
@@ -2,6 +2,8 @@ package shot
 
 import (
 	"math/big"
+	"solana-bot/internal/fee"
+	"solana-bot/internal/signer"
 
 	"github.com/gagliardetto/solana-go"
 )
@@ -12,7 +14,7 @@ type ShotAdapter interface {
 }
 
 type TxContext struct {
-	SignerAndOwner       solana.PrivateKey
+	SignerAndOwner       signer.Signer
 	SrcMint              solana.PublicKey
 	DstMint              solana.PublicKey
 	VirtualSolReserves   *big.Int
@@ -23,3 +25,22 @@ type TxContext struct {
 	PriorityFee          uint64
 	Fee                  uint64
 }
+
+// FeeAdapter 能给出交易会写锁定的池子账户，用来按池子估算优先费
+type FeeAdapter interface {
+	FeeAccounts(accounts ...solana.PublicKey) []string
+}
+
+// PriorityFee 按 adapter 将要写入的池子账户估算 percentile 分位的优先费（micro-lamports / CU），
+// accounts 与传给 BuildInstructions 的相同
+func PriorityFee(a FeeAdapter, percentile int, accounts ...solana.PublicKey) uint64 {
+	return fee.Estimate(a.FeeAccounts(accounts...), percentile)
+}
+
+func feeKeys(keys ...solana.PublicKey) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k.String()
+	}
+	return out
+}
@@ -67,8 +67,8 @@ func QuoteSellByCurve(
 	return amountOutAfterFee
 }
 
-// slippage is a value between 0 - 100
-func applySlippage(amount *big.Int, slippage float32) *big.Int {
+// ApplySlippage 按滑点（0 - 100）算出成交数量的下限，adapter 构建指令和 paper 成交都用它
+func ApplySlippage(amount *big.Int, slippage float32) *big.Int {
 
 	slippageBP := (int64(100*slippage) + 25) * SlippageAdjustment
 	maxSlippage := new(big.Int).Mul(global.Big10000, big.NewInt(SlippageAdjustment))
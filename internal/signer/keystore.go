@@ -0,0 +1,171 @@
+package signer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// scrypt 参数，写进文件里，以后调整不影响旧文件
+var (
+	scryptN = 1 << 16
+	scryptR = 8
+	scryptP = 1
+)
+
+var ErrPassphrase = errors.New("keystore: wrong passphrase")
+
+// Keystore 用口令加密的私钥文件：scrypt 派生密钥，AES-256-GCM 加密
+type Keystore struct {
+	Version    int    `json:"version"`
+	PublicKey  string `json:"pubkey"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+func deriveKey(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptKey 用 passphrase 加密私钥
+func EncryptKey(key solana.PrivateKey, passphrase string) (*Keystore, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	pubkey := key.PublicKey().String()
+	return &Keystore{
+		Version:    1,
+		PublicKey:  pubkey,
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       hex.EncodeToString(salt),
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, key, []byte(pubkey))),
+	}, nil
+}
+
+// Decrypt 解出私钥，口令错误返回 ErrPassphrase
+func (ks *Keystore) Decrypt(passphrase string) (solana.PrivateKey, error) {
+	if ks.KDF != "scrypt" {
+		return nil, fmt.Errorf("keystore: unsupported kdf %q", ks.KDF)
+	}
+	salt, err := hex.DecodeString(ks.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: ciphertext: %w", err)
+	}
+	aead, err := deriveKey(passphrase, salt, ks.N, ks.R, ks.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("keystore: bad nonce")
+	}
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(ks.PublicKey))
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	key := solana.PrivateKey(plain)
+	if key.PublicKey().String() != ks.PublicKey {
+		return nil, errors.New("keystore: public key mismatch")
+	}
+	return key, nil
+}
+
+// WriteKeystore 加密私钥并写入 path，文件权限 0600，不覆盖已有文件
+func WriteKeystore(path string, key solana.PrivateKey, passphrase string) error {
+	ks, err := EncryptKey(key, passphrase)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadKeystore 读取并解密 keystore 文件
+func LoadKeystore(path, passphrase string) (*KeySigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ks Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	key, err := ks.Decrypt(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	return NewKeySigner(key), nil
+}
+
+// ReadPassphrase 从环境变量 env 读取口令，没有设置时在终端输入
+func ReadPassphrase(env, prompt string) (string, error) {
+	if env != "" {
+		if v := os.Getenv(env); v != "" {
+			return v, nil
+		}
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("passphrase: env %s is empty and stdin is not a terminal", env)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,470 @@
+package signer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+
+	"solana-bot/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// DefaultPrograms 允许交易调用的程序，配置里的 programs 在此基础上追加
+var DefaultPrograms = []string{
+	"11111111111111111111111111111111",             // System
+	"ComputeBudget111111111111111111111111111111",  // Compute Budget
+	"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",  // Token
+	"TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb",  // Token-2022
+	"ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL", // Associated Token Account
+	"AddressLookupTab1e1111111111111111111111111",  // Address Lookup Table
+	"MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr",  // Memo
+	"Memo1UhkJRfHyvLMcVucJwxXeuD728EqVDDwQDxFMNo",  // Memo v1
+	"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",  // pump.fun
+	"pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA",  // PumpSwap
+	"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8", // Raydium AMM v4
+	"CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C", // Raydium CPMM
+	"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK", // Raydium CLMM
+	"LanMV9sAd7wArD4vJFi2qDdfnVhFxYSUg6eADduJ3uj",  // Raydium Launchpad
+	"routeUGWgWzqBWFcrCfv8tritsqukccJPu3q5GPP3xS",  // Raydium Route
+	"dbcij3LWUppWqq96dh6gJWwBifmcGfLSB5D4DuSMaqN",  // Meteora DBC
+	"LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo",  // Meteora DLMM
+	"Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB", // Meteora Pools
+	"cpamdpZCGKUy5JxQXB4dcpGPiikHawvSWAd6mEn1sGG",  // Meteora DAMM v2
+	"JUP6LkbZbjS1jKKwapdHNy74zcdm3bZ9sV7rjpwn8Gw",  // Jupiter v6
+	"6m2CDdhRgxpH4WjvdzxAYbGxwdGUz5MziiL5jek2kBma", // OKX DEX Router
+}
+
+// DefaultMaxLamports 没有配置时每笔交易从钱包转出的 SOL 上限
+const DefaultMaxLamports = 1_000_000_000
+
+// DefaultMaxPriorityFee 没有配置时每笔交易的优先费上限
+const DefaultMaxPriorityFee = 10_000_000
+
+var ErrPolicy = errors.New("policy violation")
+
+// Violation 违反策略的指令，Index 为交易中的第几条指令
+type Violation struct {
+	Index    int
+	Program  solana.PublicKey
+	Accounts []solana.PublicKey
+	Data     []byte
+	Reason   string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%v: instruction %d (%s): %s", ErrPolicy, v.Index, v.Program, v.Reason)
+}
+
+func (v *Violation) Unwrap() error {
+	return ErrPolicy
+}
+
+// Policy 签名前检查交易，只看顶层指令：程序白名单、SOL 只能转给小费账户和自己、
+// 每笔交易转出的 SOL（含优先费）上限、优先费上限、涉及钱包的 Token 指令只允许白名单里的几种、
+// 关闭代币账户和地址表的租金只能退回自己
+type Policy struct {
+	programs       map[solana.PublicKey]bool
+	tips           map[solana.PublicKey]bool
+	maxLamports    uint64
+	maxPriorityFee uint64
+	tables         atomic.Pointer[map[solana.PublicKey]solana.PublicKeySlice]
+}
+
+// NewPolicy tips 为各发送通道的小费账户，配置里的 tips 在此基础上追加
+func NewPolicy(conf config.PolicyConf, tips ...solana.PublicKey) (*Policy, error) {
+	p := &Policy{
+		programs:       make(map[solana.PublicKey]bool),
+		tips:           make(map[solana.PublicKey]bool),
+		maxLamports:    conf.MaxLamports,
+		maxPriorityFee: conf.MaxPriorityFee,
+	}
+	if p.maxLamports == 0 {
+		p.maxLamports = DefaultMaxLamports
+	}
+	if p.maxPriorityFee == 0 {
+		p.maxPriorityFee = DefaultMaxPriorityFee
+	}
+	for _, s := range append(append([]string{}, DefaultPrograms...), conf.Programs...) {
+		program, err := solana.PublicKeyFromBase58(s)
+		if err != nil {
+			return nil, fmt.Errorf("policy program %s: %w", s, err)
+		}
+		p.programs[program] = true
+	}
+	for _, s := range conf.Tips {
+		tip, err := solana.PublicKeyFromBase58(s)
+		if err != nil {
+			return nil, fmt.Errorf("policy tip %s: %w", s, err)
+		}
+		p.tips[tip] = true
+	}
+	for _, tip := range tips {
+		p.tips[tip] = true
+	}
+	return p, nil
+}
+
+// SetAddressTables 签名方自己从链上读取的地址表，用来解析没有附带地址表内容的 v0 交易
+func (p *Policy) SetAddressTables(tables map[solana.PublicKey]solana.PublicKeySlice) {
+	p.tables.Store(&tables)
+}
+
+// Check 检查 owner 签名的交易，违反策略时返回 *Violation
+func (p *Policy) Check(tx *solana.Transaction, owner solana.PublicKey) error {
+	keys, err := tx.Message.GetAllKeys()
+	if tables := p.tables.Load(); err != nil && tables != nil && tx.Message.GetAddressTables() == nil {
+		tx.Message.SetAddressTables(*tables)
+		keys, err = tx.Message.GetAllKeys()
+	}
+	if err != nil {
+		// 地址表没有解析时只认静态账户，地址表里的账户当作未知账户处理
+		keys = tx.Message.AccountKeys
+	}
+	var (
+		spent  uint64
+		budget computeBudget
+	)
+	for i, ins := range tx.Message.Instructions {
+		if int(ins.ProgramIDIndex) >= len(keys) {
+			return &Violation{Index: i, Reason: "invalid program index"}
+		}
+		program := keys[ins.ProgramIDIndex]
+		accounts := make([]solana.PublicKey, len(ins.Accounts))
+		for j, index := range ins.Accounts {
+			if int(index) < len(keys) {
+				accounts[j] = keys[index]
+			}
+		}
+		violation := func(format string, args ...interface{}) error {
+			return &Violation{Index: i, Program: program, Accounts: accounts, Data: ins.Data, Reason: fmt.Sprintf(format, args...)}
+		}
+		if !p.programs[program] {
+			return violation("program not allowed")
+		}
+
+		switch program {
+		case solana.SystemProgramID:
+			lamports, err := p.checkSystem(ins.Data, accounts, owner)
+			if err != nil {
+				return violation("%v", err)
+			}
+			spent += lamports
+			if spent > p.maxLamports {
+				return violation("%d lamports leaving the wallet, max %d", spent, p.maxLamports)
+			}
+		case solana.TokenProgramID, solana.Token2022ProgramID:
+			if err := checkToken(ins.Data, accounts, owner); err != nil {
+				return violation("%v", err)
+			}
+		case solana.AddressLookupTableProgramID:
+			if err := checkLookupTable(ins.Data, accounts, owner); err != nil {
+				return violation("%v", err)
+			}
+		case solana.ComputeBudget:
+			if err := budget.add(i, ins.Data); err != nil {
+				return violation("%v", err)
+			}
+		}
+	}
+
+	// 优先费由付款人出，计入从钱包转出的 SOL
+	if len(keys) == 0 || !keys[0].Equals(owner) {
+		return nil
+	}
+	fee, err := budget.fee(len(tx.Message.Instructions))
+	violation := func(format string, args ...interface{}) error {
+		return &Violation{Index: budget.priceIndex, Program: solana.ComputeBudget, Reason: fmt.Sprintf(format, args...)}
+	}
+	switch {
+	case err != nil:
+		return violation("%v", err)
+	case fee > p.maxPriorityFee:
+		return violation("priority fee %d lamports, max %d", fee, p.maxPriorityFee)
+	case spent+fee > p.maxLamports:
+		return violation("%d lamports leaving the wallet with priority fee %d, max %d", spent+fee, fee, p.maxLamports)
+	}
+	return nil
+}
+
+// Compute Budget 程序的指令序号
+const (
+	cbRequestHeapFrame               = 1
+	cbSetComputeUnitLimit            = 2
+	cbSetComputeUnitPrice            = 3
+	cbSetLoadedAccountsDataSizeLimit = 4
+
+	// 没有 SetComputeUnitLimit 时每条指令默认的 CU 上限和整笔交易的上限
+	defaultInstructionUnits = 200_000
+	maxComputeUnits         = 1_400_000
+)
+
+// computeBudget 交易里的 Compute Budget 指令，同一种指令只能出现一次
+type computeBudget struct {
+	seen       [5]bool
+	count      int    // Compute Budget 指令数，不计入默认 CU 上限
+	limit      uint64 // 0 表示没有设置
+	price      uint64 // micro-lamports / CU
+	priceIndex int
+}
+
+func (b *computeBudget) add(index int, data []byte) error {
+	if len(data) == 0 || data[0] < cbRequestHeapFrame || data[0] > cbSetLoadedAccountsDataSizeLimit {
+		return errors.New("invalid compute budget instruction")
+	}
+	if b.seen[data[0]] {
+		return fmt.Errorf("duplicate compute budget instruction %d", data[0])
+	}
+	b.seen[data[0]] = true
+	b.count++
+	switch data[0] {
+	case cbSetComputeUnitLimit:
+		if len(data) < 5 {
+			return errors.New("invalid compute unit limit")
+		}
+		b.limit = uint64(binary.LittleEndian.Uint32(data[1:]))
+	case cbSetComputeUnitPrice:
+		if len(data) < 9 {
+			return errors.New("invalid compute unit price")
+		}
+		b.price = binary.LittleEndian.Uint64(data[1:])
+		b.priceIndex = index
+	}
+	return nil
+}
+
+// fee 优先费（lamports）：CU 单价 × CU 上限，向上取整
+func (b *computeBudget) fee(instructions int) (uint64, error) {
+	limit := b.limit
+	if !b.seen[cbSetComputeUnitLimit] {
+		limit = uint64(instructions-b.count) * defaultInstructionUnits
+	}
+	limit = min(limit, maxComputeUnits)
+	hi, lo := bits.Mul64(b.price, limit)
+	if hi != 0 {
+		return 0, errors.New("priority fee overflow")
+	}
+	fee := lo / 1_000_000
+	if lo%1_000_000 != 0 {
+		fee++
+	}
+	return fee, nil
+}
+
+// at 指令的第 i 个账户，不存在时返回零值
+func at(accounts []solana.PublicKey, i int) solana.PublicKey {
+	if i >= len(accounts) {
+		return solana.PublicKey{}
+	}
+	return accounts[i]
+}
+
+// System 程序的指令序号
+const (
+	sysCreateAccount         = 0
+	sysTransfer              = 2
+	sysCreateAccountWithSeed = 3
+	sysAdvanceNonceAccount   = 4
+	sysWithdrawNonceAccount  = 5
+	sysInitializeNonce       = 6
+	sysAuthorizeNonceAccount = 7
+	sysTransferWithSeed      = 11
+)
+
+// checkSystem 返回指令从 owner 转出的 lamports
+func (p *Policy) checkSystem(data []byte, accounts []solana.PublicKey, owner solana.PublicKey) (uint64, error) {
+	account := func(i int) solana.PublicKey { return at(accounts, i) }
+	if len(data) < 4 {
+		return 0, errors.New("invalid system instruction")
+	}
+	u64 := func(offset int) (uint64, error) {
+		if len(data) < offset+8 {
+			return 0, errors.New("invalid system instruction")
+		}
+		return binary.LittleEndian.Uint64(data[offset:]), nil
+	}
+
+	switch binary.LittleEndian.Uint32(data) {
+	case sysCreateAccount:
+		// 新账户必须签名，只计入转出金额
+		if !account(0).Equals(owner) {
+			return 0, nil
+		}
+		return u64(4)
+	case sysCreateAccountWithSeed:
+		if !account(0).Equals(owner) {
+			return 0, nil
+		}
+		// base(32) + seed(u64 长度 + 内容) + lamports
+		seedLen, err := u64(36)
+		if err != nil || seedLen > 32 {
+			return 0, errors.New("invalid system instruction")
+		}
+		return u64(44 + int(seedLen))
+	case sysTransfer, sysTransferWithSeed:
+		from, to := account(0), account(1)
+		if binary.LittleEndian.Uint32(data) == sysTransferWithSeed {
+			from, to = account(1), account(2)
+		}
+		if !from.Equals(owner) {
+			return 0, nil
+		}
+		lamports, err := u64(4)
+		if err != nil {
+			return 0, err
+		}
+		if !p.ownTransfer(to, owner) {
+			return 0, fmt.Errorf("transfer %d lamports to %s", lamports, to)
+		}
+		return lamports, nil
+	case sysAdvanceNonceAccount, sysInitializeNonce:
+		return 0, nil
+	case sysWithdrawNonceAccount:
+		if account(4).Equals(owner) && !account(1).Equals(owner) {
+			return 0, fmt.Errorf("withdraw nonce account to %s", account(1))
+		}
+		return 0, nil
+	case sysAuthorizeNonceAccount:
+		if account(1).Equals(owner) {
+			return 0, errors.New("authorize nonce account")
+		}
+		return 0, nil
+	}
+	// Assign、Allocate 等：不允许作用于钱包
+	for _, key := range accounts {
+		if key.Equals(owner) {
+			return 0, fmt.Errorf("system instruction %d on wallet", binary.LittleEndian.Uint32(data))
+		}
+	}
+	return 0, nil
+}
+
+// ownTransfer SOL 只能转给小费账户、钱包自己或钱包的 WSOL 账户
+func (p *Policy) ownTransfer(to, owner solana.PublicKey) bool {
+	if p.tips[to] || to.Equals(owner) {
+		return true
+	}
+	for _, program := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		ata, _, err := solana.FindProgramAddress(
+			[][]byte{owner[:], program[:], solana.WrappedSol[:]},
+			solana.SPLAssociatedTokenAccountProgramID,
+		)
+		if err == nil && to.Equals(ata) {
+			return true
+		}
+	}
+	return false
+}
+
+// Token 程序的指令序号
+const (
+	tokenInitializeAccount        = 1
+	tokenRevoke                   = 5
+	tokenCloseAccount             = 9
+	tokenInitializeAccount2       = 16
+	tokenSyncNative               = 17
+	tokenInitializeAccount3       = 18
+	tokenGetAccountDataSize       = 21
+	tokenInitializeImmutableOwner = 22
+	tokenAmountToUiAmount         = 23
+	tokenUiAmountToAmount         = 24
+)
+
+// tokenAllowed 钱包作为账户或权限出现时允许的 Token 指令，其余（转账、授权、销毁、
+// 修改权限、Token-2022 扩展等）一律拒绝
+var tokenAllowed = map[byte]bool{
+	tokenInitializeAccount:        true,
+	tokenRevoke:                   true,
+	tokenCloseAccount:             true,
+	tokenInitializeAccount2:       true,
+	tokenSyncNative:               true,
+	tokenInitializeAccount3:       true,
+	tokenGetAccountDataSize:       true,
+	tokenInitializeImmutableOwner: true,
+	tokenAmountToUiAmount:         true,
+	tokenUiAmountToAmount:         true,
+}
+
+func checkToken(data []byte, accounts []solana.PublicKey, owner solana.PublicKey) error {
+	account := func(i int) solana.PublicKey { return at(accounts, i) }
+	if len(data) == 0 {
+		return errors.New("invalid token instruction")
+	}
+	if !containsKey(accounts, owner) {
+		return nil
+	}
+	if !tokenAllowed[data[0]] {
+		return fmt.Errorf("token instruction %d on wallet", data[0])
+	}
+	if data[0] == tokenCloseAccount && !account(1).Equals(owner) {
+		return fmt.Errorf("close %s to %s", account(0), account(1))
+	}
+	return nil
+}
+
+// Address Lookup Table 程序的指令序号
+const altCloseLookupTable = 4
+
+// checkLookupTable 关闭钱包的地址表时租金只能退回钱包
+func checkLookupTable(data []byte, accounts []solana.PublicKey, owner solana.PublicKey) error {
+	account := func(i int) solana.PublicKey { return at(accounts, i) }
+	if len(data) < 4 {
+		return errors.New("invalid lookup table instruction")
+	}
+	if binary.LittleEndian.Uint32(data) == altCloseLookupTable && account(1).Equals(owner) && !account(2).Equals(owner) {
+		return fmt.Errorf("close lookup table %s to %s", account(0), account(2))
+	}
+	return nil
+}
+
+func containsKey(keys []solana.PublicKey, key solana.PublicKey) bool {
+	for _, k := range keys {
+		if k.Equals(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewGuarded 按配置给 s 加上策略检查，policy.disabled 时原样返回
+func NewGuarded(s Signer, conf config.PolicyConf, tips ...solana.PublicKey) (Signer, error) {
+	if conf.Disabled {
+		logx.Infof("[signer] policy disabled")
+		return s, nil
+	}
+	policy, err := NewPolicy(conf, tips...)
+	if err != nil {
+		return nil, err
+	}
+	return Guard(s, policy), nil
+}
+
+// Guarded 签名前按策略检查交易的 Signer
+type Guarded struct {
+	Signer
+	policy *Policy
+}
+
+// Guard 给 s 加上策略检查，policy 为 nil 时原样返回
+func Guard(s Signer, policy *Policy) Signer {
+	if policy == nil {
+		return s
+	}
+	return &Guarded{Signer: s, policy: policy}
+}
+
+func (g *Guarded) SignTransaction(tx *solana.Transaction) error {
+	if err := g.policy.Check(tx, g.PublicKey()); err != nil {
+		var v *Violation
+		if errors.As(err, &v) {
+			logx.Errorf("[signer] refuse to sign: %v, accounts %v, data %x", err, v.Accounts, v.Data)
+		} else {
+			logx.Errorf("[signer] refuse to sign: %v", err)
+		}
+		return err
+	}
+	return g.Signer.SignTransaction(tx)
+}
@@ -0,0 +1,196 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+
+	"solana-bot/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+func TestPolicy(t *testing.T) {
+	owner := NewKeySigner(solana.NewWallet().PrivateKey)
+	me := owner.PublicKey()
+	tip := solana.NewWallet().PublicKey()
+	stranger := solana.NewWallet().PublicKey()
+	wsol, _, _ := solana.FindAssociatedTokenAddress(me, solana.WrappedSol)
+
+	policy, err := NewPolicy(config.PolicyConf{MaxLamports: 1e9}, tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		ins   []solana.Instruction
+		index int // 违反策略的指令，-1 表示通过
+	}{
+		{"swap", []solana.Instruction{
+			computebudget.NewSetComputeUnitLimitInstruction(100_000).Build(),
+			system.NewTransferInstruction(5e8, me, wsol).Build(),
+			token.NewSyncNativeInstruction(wsol).Build(),
+			token.NewCloseAccountInstruction(wsol, me, me, nil).Build(),
+			system.NewTransferInstruction(1e6, me, tip).Build(),
+		}, -1},
+		{"transfer to stranger", []solana.Instruction{
+			system.NewTransferInstruction(1e6, me, tip).Build(),
+			system.NewTransferInstruction(1, me, stranger).Build(),
+		}, 1},
+		{"over max", []solana.Instruction{
+			system.NewTransferInstruction(6e8, me, wsol).Build(),
+			system.NewTransferInstruction(6e8, me, tip).Build(),
+		}, 1},
+		{"unknown program", []solana.Instruction{
+			solana.NewInstruction(stranger, solana.AccountMetaSlice{solana.Meta(me).SIGNER().WRITE()}, []byte{1}),
+		}, 0},
+		{"approve", []solana.Instruction{
+			token.NewApproveInstruction(1, wsol, stranger, me, nil).Build(),
+		}, 0},
+		{"set authority", []solana.Instruction{
+			token.NewSetAuthorityInstruction(token.AuthorityAccountOwner, stranger, wsol, me, nil).Build(),
+		}, 0},
+		{"close to stranger", []solana.Instruction{
+			token.NewCloseAccountInstruction(wsol, stranger, me, nil).Build(),
+		}, 0},
+		{"burn", []solana.Instruction{
+			token.NewBurnInstruction(1, wsol, solana.WrappedSol, me, nil).Build(),
+		}, 0},
+		{"burn checked", []solana.Instruction{
+			token.NewBurnCheckedInstruction(1, 9, wsol, solana.WrappedSol, me, nil).Build(),
+		}, 0},
+		{"transfer checked", []solana.Instruction{
+			token.NewTransferCheckedInstruction(1, 9, wsol, solana.WrappedSol, stranger, me, nil).Build(),
+		}, 0},
+		// Token-2022 TransferCheckedWithFee
+		{"token-2022 extension", []solana.Instruction{
+			solana.NewInstruction(solana.Token2022ProgramID, solana.AccountMetaSlice{
+				solana.Meta(wsol).WRITE(),
+				solana.Meta(solana.WrappedSol),
+				solana.Meta(stranger).WRITE(),
+				solana.Meta(me).SIGNER(),
+			}, []byte{26, 1, 1, 0, 0, 0, 0, 0, 0, 0, 9, 0, 0, 0, 0, 0, 0, 0, 0}),
+		}, 0},
+		{"other wallet's token instruction", []solana.Instruction{
+			token.NewBurnInstruction(1, stranger, solana.WrappedSol, stranger, nil).Build(),
+		}, -1},
+		{"close lookup table", []solana.Instruction{
+			closeLookupTable(stranger, me, me),
+		}, -1},
+		{"close lookup table to stranger", []solana.Instruction{
+			closeLookupTable(stranger, me, stranger),
+		}, 0},
+		{"assign wallet", []solana.Instruction{
+			system.NewAssignInstruction(stranger, me).Build(),
+		}, 0},
+		// 1e12 micro-lamports × 100k CU = 0.1 SOL
+		{"priority fee over max", []solana.Instruction{
+			computebudget.NewSetComputeUnitLimitInstruction(100_000).Build(),
+			computebudget.NewSetComputeUnitPriceInstruction(1e12).Build(),
+			system.NewTransferInstruction(1e6, me, tip).Build(),
+		}, 1},
+		// 没有 CU 上限时按每条指令 200k 计算
+		{"priority fee default limit", []solana.Instruction{
+			computebudget.NewSetComputeUnitPriceInstruction(1e11).Build(),
+			system.NewTransferInstruction(1e6, me, tip).Build(),
+		}, 0},
+		{"priority fee over max lamports", []solana.Instruction{
+			computebudget.NewSetComputeUnitLimitInstruction(1_000_000).Build(),
+			computebudget.NewSetComputeUnitPriceInstruction(9e6).Build(),
+			system.NewTransferInstruction(9.95e8, me, wsol).Build(),
+		}, 1},
+		{"duplicate compute unit price", []solana.Instruction{
+			computebudget.NewSetComputeUnitPriceInstruction(1).Build(),
+			computebudget.NewSetComputeUnitPriceInstruction(1e11).Build(),
+		}, 1},
+	}
+	for _, c := range cases {
+		tx, err := solana.NewTransaction(c.ins, solana.Hash{1}, solana.TransactionPayer(me))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = policy.Check(tx, me)
+		var v *Violation
+		switch {
+		case c.index < 0 && err != nil:
+			t.Errorf("%s: %v", c.name, err)
+		case c.index >= 0 && !errors.As(err, &v):
+			t.Errorf("%s: err = %v, want violation", c.name, err)
+		case c.index >= 0 && v.Index != c.index:
+			t.Errorf("%s: violation at %d, want %d: %v", c.name, v.Index, c.index, err)
+		}
+	}
+}
+
+func closeLookupTable(table, authority, recipient solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(solana.AddressLookupTableProgramID, solana.AccountMetaSlice{
+		solana.Meta(table).WRITE(),
+		solana.Meta(authority).SIGNER(),
+		solana.Meta(recipient).WRITE(),
+	}, []byte{4, 0, 0, 0})
+}
+
+func TestGuard(t *testing.T) {
+	key := NewKeySigner(solana.NewWallet().PrivateKey)
+	policy, err := NewPolicy(config.PolicyConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Guard(key, policy)
+
+	tx := testTx(t, s.PublicKey())
+	if err := s.SignTransaction(tx); !errors.Is(err, ErrPolicy) {
+		t.Fatalf("err = %v, want policy violation", err)
+	}
+	if len(tx.Signatures) != 0 {
+		t.Fatal("signed a rejected transaction")
+	}
+
+	tx = testTx(t, s.PublicKey())
+	tx.Message.Instructions = tx.Message.Instructions[:0]
+	if err := s.SignTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPolicyAddressTables(t *testing.T) {
+	me := solana.NewWallet().PublicKey()
+	tip := solana.NewWallet().PublicKey()
+	table := solana.NewWallet().PublicKey()
+	tables := map[solana.PublicKey]solana.PublicKeySlice{table: {tip}}
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(1e6, me, tip).Build()},
+		solana.Hash{1},
+		solana.TransactionPayer(me),
+		solana.TransactionAddressTables(tables),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 签名服务收到的交易不带地址表内容
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := NewPolicy(config.PolicyConf{}, tip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Check(received, me); !errors.Is(err, ErrPolicy) {
+		t.Fatalf("unresolved lookup: err = %v, want policy violation", err)
+	}
+	policy.SetAddressTables(tables)
+	received, _ = solana.TransactionFromBytes(raw)
+	if err := policy.Check(received, me); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,235 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const remoteTimeout = 3 * time.Second
+
+// SignRequest 远程签名请求，带完整交易供签名方做策略检查
+type SignRequest struct {
+	PublicKey   string `json:"pubkey"`
+	Transaction string `json:"transaction"` // base64
+}
+
+type SignResponse struct {
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ErrNoToken 签名服务不在 unix socket 上时必须用共享口令鉴权
+var ErrNoToken = errors.New("signer: token is required for non-unix endpoints")
+
+// RemoteSigner 通过本机 Unix socket 或 HTTP 请求签名服务，私钥不进入本进程
+type RemoteSigner struct {
+	base   string
+	token  string
+	client *http.Client
+	pubkey solana.PublicKey
+}
+
+// NewRemoteSigner endpoint 为 unix:///path/to.sock 或 http://host:port，创建时查询签名方的公钥；
+// token 为签名服务的共享口令，http 端点必须设置
+func NewRemoteSigner(endpoint, token string) (*RemoteSigner, error) {
+	s := &RemoteSigner{
+		base:   strings.TrimRight(endpoint, "/"),
+		token:  token,
+		client: &http.Client{Timeout: remoteTimeout},
+	}
+	path, unix := strings.CutPrefix(endpoint, "unix://")
+	if !unix && token == "" {
+		return nil, ErrNoToken
+	}
+	if unix {
+		s.base = "http://signer"
+		s.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+	}
+
+	resp, err := s.do(http.MethodGet, "/pubkey", nil)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer %s: %s", endpoint, strings.TrimSpace(string(body)))
+	}
+	s.pubkey, err = solana.PublicKeyFromBase58(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("remote signer %s: %w", endpoint, err)
+	}
+	return s, nil
+}
+
+func (s *RemoteSigner) PublicKey() solana.PublicKey {
+	return s.pubkey
+}
+
+func (s *RemoteSigner) SignTransaction(tx *solana.Transaction) error {
+	// 补齐签名位，保证发过去的是一笔合法的交易
+	if err := SetSignature(tx, s.pubkey, solana.Signature{}); err != nil {
+		return err
+	}
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	raw, err := tx.ToBase64()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(SignRequest{PublicKey: s.pubkey.String(), Transaction: raw})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodPost, "/sign", body)
+	if err != nil {
+		return fmt.Errorf("remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+	var out SignResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("remote signer: %s: %w", resp.Status, err)
+	}
+	if out.Error != "" {
+		return fmt.Errorf("remote signer: %s", out.Error)
+	}
+	sig, err := solana.SignatureFromBase58(out.Signature)
+	if err != nil {
+		return fmt.Errorf("remote signer: %w", err)
+	}
+	if !sig.Verify(s.pubkey, msg) {
+		return errors.New("remote signer: invalid signature")
+	}
+	return SetSignature(tx, s.pubkey, sig)
+}
+
+func (s *RemoteSigner) do(method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return s.client.Do(req)
+}
+
+// Handler 签名服务端：GET /pubkey 返回公钥，POST /sign 对请求中的交易签名；
+// token 不为空时每个请求都要带 Authorization: Bearer <token>
+func Handler(s Signer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pubkey", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, s.PublicKey().String())
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		writeSign(w, handleSign(s, r))
+	})
+	if token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			logx.Errorf("[signer] unauthorized request from %s", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func handleSign(s Signer, r *http.Request) SignResponse {
+	if r.Method != http.MethodPost {
+		return SignResponse{Error: "method not allowed"}
+	}
+	var req SignRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
+		return SignResponse{Error: err.Error()}
+	}
+	if req.PublicKey != s.PublicKey().String() {
+		return SignResponse{Error: "unknown pubkey " + req.PublicKey}
+	}
+	tx, err := solana.TransactionFromBase64(req.Transaction)
+	if err != nil {
+		return SignResponse{Error: err.Error()}
+	}
+	if err := s.SignTransaction(tx); err != nil {
+		logx.Errorf("[signer] refuse: %v", err)
+		return SignResponse{Error: err.Error()}
+	}
+	for i, key := range tx.Message.AccountKeys[:tx.Message.Header.NumRequiredSignatures] {
+		if key.Equals(s.PublicKey()) {
+			return SignResponse{Signature: tx.Signatures[i].String()}
+		}
+	}
+	return SignResponse{Error: "not a signer"}
+}
+
+func writeSign(w http.ResponseWriter, resp SignResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Error != "" {
+		w.WriteHeader(http.StatusForbidden)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Serve 在 listen（unix:///path/to.sock 或 host:port）上提供签名服务，直到 ctx 结束；
+// 监听 TCP 时必须设置 token，否则任何能连上的人都能让它签名
+func Serve(ctx context.Context, listen string, s Signer, token string) error {
+	network, addr := "tcp", listen
+	if path, ok := strings.CutPrefix(listen, "unix://"); ok {
+		network, addr = "unix", path
+		os.Remove(path)
+	} else if token == "" {
+		return ErrNoToken
+	}
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		// 只允许同一用户连接
+		if err := os.Chmod(addr, 0o600); err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	srv := &http.Server{Handler: Handler(s, token)}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	logx.Infof("[signer] %s serving on %s", s.PublicKey(), listen)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
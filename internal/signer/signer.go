@@ -0,0 +1,119 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"solana-bot/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Signer 交易钱包的签名能力；私钥可以在本进程（keystore、环境变量）或远程签名服务中
+type Signer interface {
+	PublicKey() solana.PublicKey
+	// SignTransaction 对 tx 签名并写入自己的签名位；实现方可以检查 tx 的内容后拒绝签名
+	SignTransaction(tx *solana.Transaction) error
+}
+
+// KeySigner 用进程内的私钥签名
+type KeySigner struct {
+	key solana.PrivateKey
+}
+
+func NewKeySigner(key solana.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (s *KeySigner) PublicKey() solana.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s *KeySigner) SignTransaction(tx *solana.Transaction) error {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	sig, err := s.key.Sign(msg)
+	if err != nil {
+		return err
+	}
+	return SetSignature(tx, s.PublicKey(), sig)
+}
+
+// FromEnv 从环境变量读取 base58 私钥，兼容之前的 PRIVATE_KEY
+func FromEnv(name string) (*KeySigner, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("env %s is empty", name)
+	}
+	key, err := solana.PrivateKeyFromBase58(value)
+	if err != nil {
+		return nil, fmt.Errorf("env %s: %w", name, err)
+	}
+	return NewKeySigner(key), nil
+}
+
+// SetSignature 把 pubkey 的签名写到 tx 中对应的位置
+func SetSignature(tx *solana.Transaction, pubkey solana.PublicKey, sig solana.Signature) error {
+	n := int(tx.Message.Header.NumRequiredSignatures)
+	if n > len(tx.Message.AccountKeys) {
+		return errors.New("invalid message header")
+	}
+	if len(tx.Signatures) < n {
+		tx.Signatures = append(tx.Signatures, make([]solana.Signature, n-len(tx.Signatures))...)
+	}
+	for i, key := range tx.Message.AccountKeys[:n] {
+		if key.Equals(pubkey) {
+			tx.Signatures[i] = sig
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a signer of the transaction", pubkey)
+}
+
+// Sign 依次用 signers 签名
+func Sign(tx *solana.Transaction, signers ...Signer) error {
+	for _, s := range signers {
+		if err := s.SignTransaction(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New 按配置创建交易钱包的签名器
+func New(conf config.SignerConf) (Signer, error) {
+	switch conf.Type {
+	case "", "env":
+		name := conf.Env
+		if name == "" {
+			name = "PRIVATE_KEY"
+		}
+		return FromEnv(name)
+	case "keystore":
+		if conf.Keystore == "" {
+			return nil, errors.New("signer: keystore path is empty")
+		}
+		env := conf.PassphraseEnv
+		if env == "" {
+			env = "KEYSTORE_PASSPHRASE"
+		}
+		passphrase, err := ReadPassphrase(env, "keystore passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		return LoadKeystore(conf.Keystore, passphrase)
+	case "remote":
+		if conf.Remote == "" {
+			return nil, errors.New("signer: remote endpoint is empty")
+		}
+		env := conf.TokenEnv
+		if env == "" {
+			env = "SIGNER_TOKEN"
+		}
+		return NewRemoteSigner(conf.Remote, os.Getenv(env))
+	}
+	return nil, fmt.Errorf("signer: unknown type %q", conf.Type)
+}
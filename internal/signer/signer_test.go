@@ -0,0 +1,94 @@
+package signer
+
+import (
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+func testTx(t *testing.T, payer solana.PublicKey) *solana.Transaction {
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(1, payer, solana.NewWallet().PublicKey()).Build()},
+		solana.Hash{1},
+		solana.TransactionPayer(payer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestKeySigner(t *testing.T) {
+	s := NewKeySigner(solana.NewWallet().PrivateKey)
+	tx := testTx(t, s.PublicKey())
+	if err := s.SignTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewKeySigner(solana.NewWallet().PrivateKey)
+	if err := other.SignTransaction(tx); err == nil {
+		t.Fatal("signed a transaction it is not part of")
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	scryptN = 1 << 10
+	key := solana.NewWallet().PrivateKey
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	if err := WriteKeystore(path, key, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteKeystore(path, key, "secret"); err == nil {
+		t.Fatal("overwrote existing keystore")
+	}
+
+	s, err := LoadKeystore(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.PublicKey().Equals(key.PublicKey()) {
+		t.Fatalf("pubkey = %s, want %s", s.PublicKey(), key.PublicKey())
+	}
+	if _, err := LoadKeystore(path, "wrong"); !errors.Is(err, ErrPassphrase) {
+		t.Fatalf("wrong passphrase err = %v", err)
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	local := NewKeySigner(solana.NewWallet().PrivateKey)
+	srv := httptest.NewServer(Handler(local, "secret"))
+	defer srv.Close()
+
+	if _, err := NewRemoteSigner(srv.URL, ""); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("http endpoint without token err = %v", err)
+	}
+	if _, err := NewRemoteSigner(srv.URL, "wrong"); err == nil {
+		t.Fatal("wrong token accepted")
+	}
+	remote, err := NewRemoteSigner(srv.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !remote.PublicKey().Equals(local.PublicKey()) {
+		t.Fatalf("pubkey = %s, want %s", remote.PublicKey(), local.PublicKey())
+	}
+	tx := testTx(t, remote.PublicKey())
+	if err := remote.SignTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 签名方拒绝不含自己的交易
+	if err := remote.SignTransaction(testTx(t, solana.NewWallet().PublicKey())); err == nil {
+		t.Fatal("signed a foreign transaction")
+	}
+}
@@ -6,9 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"log"
-	"math/big"
 	"os"
-	"solana-bot/internal/config"
 	"solana-bot/internal/global"
 	"solana-bot/internal/global/utils"
 	"solana-bot/internal/pb/feepb"
@@ -17,14 +15,11 @@ import (
 
 	"time"
 
-	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
-	"github.com/gagliardetto/solana-go/programs/system"
 	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
 	"github.com/zeromicro/go-zero/core/logx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
 )
 
 // 参考github.com/BlockRazorinc/solana-trader-client-go
@@ -45,6 +40,7 @@ func NewBlzStream() *GrpcStream {
 		conns = append(conns, conn)
 	}
 	return &GrpcStream{
+		Name:   "blz",
 		Conns:  conns,
 		Xtoken: os.Getenv("BLZ_XTOKEN"),
 	}
@@ -134,53 +130,23 @@ func Fee_subscribe(ctx context.Context, recv chan interface{}) {
 }
 
 func Grpc_subscribe(conn *grpc.ClientConn, subscription *pb.SubscribeRequest, ctx context.Context, recv chan interface{}) {
-	var err error
-	client := pb.NewGeyserClient(conn)
-
 	subscriptionJson, err := json.Marshal(&subscription)
 	if err != nil {
 		logx.Errorf("Failed to marshal subscription request: %v", subscriptionJson)
 	}
 	logx.Infof("Subscription request: %s", string(subscriptionJson))
 
-	// Set up the subscription request
-	relayCtx := context.Background()
-	md := metadata.New(map[string]string{"x-token": os.Getenv("BLZ_XTOKEN")})
-	relayCtx = metadata.NewOutgoingContext(relayCtx, md)
-	stream, err := client.Subscribe(relayCtx)
-	if err != nil {
-		logx.Errorf("%v", err)
-		return
-	}
-	err = stream.Send(subscription)
-	if err != nil {
-		logx.Errorf("%v", err)
-		return
-	}
-	logx.Info("yellowStoneSubscribe start recv ...")
-	for {
-		select {
-		case <-ctx.Done():
-			logx.Info("Context done, stopping subscription")
-			close(recv)
-			return
-		default:
-			resp, err := stream.Recv()
-
-			//打印
-			// spew.Dump(resp)
+	sub := NewSubscription(conn, os.Getenv("BLZ_XTOKEN"), subscription)
+	err = sub.Run(ctx, func(resp *pb.SubscribeUpdate) {
+		recv <- resp
+	})
 
-			if err == io.EOF {
-				logx.Info("Stream closed")
-				return
-			}
-			if err != nil {
-				// log.Fatalf("Error occurred in receiving update: %v", err)
-				continue
-			}
-			recv <- resp
-		}
+	if ctx.Err() != nil {
+		logx.Info("Context done, stopping subscription")
+		close(recv)
+		return
 	}
+	logx.Errorf("[%s]: Subscription stopped: %v", conn.CanonicalTarget(), err)
 }
 
 func SlotSubscribeWithRelay(conn *grpc.ClientConn) {
@@ -207,19 +173,17 @@ func SlotSubscribeWithRelay(conn *grpc.ClientConn) {
 	}
 }
 
-func BlockSubscribeWithRelay(conn *grpc.ClientConn) {
+func BlockSubscribeWithRelay(ctx context.Context, hub *Hub) {
 	subscribe := make(chan interface{})
 	var subscription pb.SubscribeRequest
-	commitment := pb.CommitmentLevel_PROCESSED
-	subscription.Commitment = &commitment
 	if subscription.BlocksMeta == nil {
 		subscription.BlocksMeta = make(map[string]*pb.SubscribeRequestFilterBlocksMeta)
 	}
 	subscription.BlocksMeta["block_meta"] = &pb.SubscribeRequestFilterBlocksMeta{}
-	go Grpc_subscribe(conn, &subscription, context.Background(), subscribe)
+	hub.Subscribe(ctx, &subscription, subscribe)
 
 	for msg := range subscribe {
-		got := msg.(*pb.SubscribeUpdate)
+		got := msg.(*StreamMessage).Data.(*pb.SubscribeUpdate)
 		// spew.Dump(got)
 		updateBlock := got.GetBlockMeta()
 		if updateBlock == nil {
@@ -231,42 +195,43 @@ func BlockSubscribeWithRelay(conn *grpc.ClientConn) {
 	}
 }
 
-func WSOLSubscribeWithRelay(conn *grpc.ClientConn) {
-
-	player := solana.MustPublicKeyFromBase58(config.C.Bot.Player)
+// WSOLSubscribeWithRelay 跟踪交易钱包的 SOL 和 WSOL 余额：启动时各查一次 RPC，之后靠钱包账户和 WSOL 账户的订阅更新
+func WSOLSubscribeWithRelay(ctx context.Context, hub *Hub, owners ...solana.PublicKey) {
+	wsolOwners := make(map[solana.PublicKey]solana.PublicKey, len(owners))
+	accounts := make([]string, 0, 2*len(owners))
+	for _, owner := range owners {
+		balance := global.Balance(owner)
+
+		bal, _ := global.GetTokenBalance(owner, solana.SolMint)
+		if bal == nil || bal.Uint64() == 0 {
+			balance.WSOL.Store(0)
+			balance.HasWSOL.Store(false)
+		} else {
+			balance.WSOL.Store(bal.Uint64())
+			balance.HasWSOL.Store(true)
+		}
 
-	bal, _ := global.GetTokenBalance(
-		player,
-		solana.SolMint,
-	)
-	if bal == nil || bal.Uint64() == 0 {
-		global.SolATA_Balance.Store(big.NewInt(0))
-		global.SolATA.Store(false)
-	} else {
-		global.SolATA_Balance.Store(big.NewInt(int64(bal.Uint64())))
-		global.SolATA.Store(true)
-	}
+		balF := global.GetBalanceByPublic(owner.String())
+		logx.Infof("获取 %s SOL 余额成功: %v \n", owner, balF)
+		if balF != nil {
+			balU, _ := balF.Uint64()
+			balance.Sol.Store(balU)
+		}
 
-	balF := global.GetBalanceByPublic(config.C.Bot.Player)
-	logx.Infof("获取 SOL 余额成功: %v \n", balF)
-	if bal != nil {
-		balU, _ := balF.Uint64()
-		global.Sol_Balance.Store(balU)
+		tokenAccount, _, _ := solana.FindAssociatedTokenAddress(owner, solana.SolMint)
+		wsolOwners[tokenAccount] = owner
+		accounts = append(accounts, owner.String(), tokenAccount.String())
 	}
 
-	tokenAccount, _, _ := solana.FindAssociatedTokenAddress(player, solana.SolMint)
-
 	subscribe := make(chan interface{})
 	var subscription pb.SubscribeRequest
-	commitment := pb.CommitmentLevel_PROCESSED
-	subscription.Commitment = &commitment
 	subscription.Accounts = make(map[string]*pb.SubscribeRequestFilterAccounts)
 	subscription.Accounts["account_sub"] = &pb.SubscribeRequestFilterAccounts{}
-	subscription.Accounts["account_sub"].Account = []string{tokenAccount.String()}
-	go Grpc_subscribe(conn, &subscription, context.Background(), subscribe)
+	subscription.Accounts["account_sub"].Account = accounts
+	hub.Subscribe(ctx, &subscription, subscribe)
 
 	for msg := range subscribe {
-		update, ok := msg.(*pb.SubscribeUpdate)
+		update, ok := msg.(*StreamMessage).Data.(*pb.SubscribeUpdate)
 		if !ok {
 			log.Printf("收到非预期类型消息: %T \n", msg)
 			continue
@@ -276,79 +241,42 @@ func WSOLSubscribeWithRelay(conn *grpc.ClientConn) {
 		if accountSub == nil || accountSub.Account == nil {
 			continue
 		}
+		pubkey := solana.PublicKeyFromBytes(accountSub.Account.GetPubkey())
 
-		accountData := accountSub.GetAccount().Data
-		wsolToken, err := utils.TokenAccountFromData(accountData)
-		if err != nil {
-			log.Printf("解析 TokenAccount 数据失败: %v \n", err)
+		owner, ok := wsolOwners[pubkey]
+		if !ok {
+			// 钱包账户本身，lamports 即 SOL 余额
+			global.Balance(pubkey).SetSol(accountSub.Account.GetLamports())
 			continue
 		}
 
-		amount := int64(wsolToken.Amount)
-		if amount > 0 {
-			global.SolATA_Balance.Store(big.NewInt(amount)) // 可优化复用
-			global.SolATA.Store(true)
-		} else {
-			global.SolATA_Balance.Store(big.NewInt(0))
-			global.SolATA.Store(false)
-		}
-
-		go func() {
-			bal := global.GetBalanceByPublic(config.C.Bot.Player)
-			logx.Infof("获取 SOL 余额成功: %v \n", bal)
-			if bal != nil {
-				balU, _ := bal.Uint64()
-				global.Sol_Balance.Store(balU)
-			}
-		}()
-
-	}
-}
-
-func UpdateGasWithRelay() {
-	subscribe := make(chan interface{})
-	go Fee_subscribe(context.Background(), subscribe)
-
-	for msg := range subscribe {
-		got := msg.(*feepb.TransactionFeeStreamResponse)
-		// spew.Dump(got)
-		fee := got.GetPriorityFee()
-		tip := got.GetTip()
-		tipUint := uint64(tip.Value * 1e99)
-		if fee == nil {
+		balance := global.Balance(owner)
+		wsolToken, err := utils.TokenAccountFromData(accountSub.GetAccount().Data)
+		if err != nil {
+			// WSOL 账户被关闭
+			balance.WSOL.Store(0)
+			balance.HasWSOL.Store(false)
 			continue
 		}
-
-		global.UpdateGasData(uint64(fee.Value), uint64(fee.Value), tipUint, tipUint)
-
+		balance.WSOL.Store(wsolToken.Amount)
+		balance.HasWSOL.Store(wsolToken.Amount > 0)
 	}
 }
 
-func NonceSubscribeWithRelay(conn *grpc.ClientConn) {
-
-	go func() {
-
-		for {
-			for _, n := range global.NonceAccount {
-				go global.SyncNonceAccount(n)
-			}
-			time.Sleep(time.Second * 1)
-		}
-
-	}()
-
+// NonceSubscribeWithRelay 启动时拉取一次池中所有 nonce，之后靠账户订阅得知 nonce 被推进
+func NonceSubscribeWithRelay(ctx context.Context, hub *Hub, pool *global.NoncePool) {
 	//订阅
 	subscribe := make(chan interface{})
 	var subscription pb.SubscribeRequest
-	commitment := pb.CommitmentLevel_PROCESSED
-	subscription.Commitment = &commitment
 	subscription.Accounts = make(map[string]*pb.SubscribeRequestFilterAccounts)
 	subscription.Accounts["account_sub"] = &pb.SubscribeRequestFilterAccounts{}
-	subscription.Accounts["account_sub"].Account = global.NonceAccount
-	go Grpc_subscribe(conn, &subscription, context.Background(), subscribe)
+	subscription.Accounts["account_sub"].Account = pool.Accounts()
+	hub.Subscribe(ctx, &subscription, subscribe)
+
+	go pool.Sync()
 
 	for msg := range subscribe {
-		update, ok := msg.(*pb.SubscribeUpdate)
+		update, ok := msg.(*StreamMessage).Data.(*pb.SubscribeUpdate)
 		if !ok {
 			log.Printf("收到非预期类型消息: %T \n", msg)
 			continue
@@ -359,18 +287,12 @@ func NonceSubscribeWithRelay(conn *grpc.ClientConn) {
 			continue
 		}
 
-		accountData := accountSub.GetAccount().Data
-		dec := bin.NewBinDecoder(accountData)
-		acc := new(system.NonceAccount)
-
-		err := acc.UnmarshalWithDecoder(dec)
+		hash, err := global.DecodeNonceHash(accountSub.GetAccount().Data)
 		if err != nil {
 			log.Printf("解析nonce account失败: %v \n", err)
 			continue
 		}
-		hash := solana.Hash(acc.Nonce)
-
-		global.UpdataNonceAccountHash(string(accountSub.Account.GetPubkey()), hash)
 
+		pool.Update(solana.PublicKeyFromBytes(accountSub.Account.GetPubkey()).String(), hash)
 	}
 }
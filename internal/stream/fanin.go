@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// FanIn 合并多个流来源：同一条更新只放行最先到达的一份，并统计每个来源的抢先率和落后延迟
+type FanIn struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	current  map[string]*arrival
+	previous map[string]*arrival
+	rotated  time.Time
+	unique   uint64
+	stats    map[string]*sourceStats
+}
+
+type arrival struct {
+	source string
+	at     time.Time
+}
+
+type sourceStats struct {
+	seen     uint64
+	wins     uint64
+	lagTotal time.Duration
+	lagMax   time.Duration
+}
+
+// SourceReport 单个来源在统计周期内的表现
+type SourceReport struct {
+	Source   string
+	Seen     uint64        // 收到的更新数
+	Wins     uint64        // 最先到达的次数
+	WinRate  float64       // Wins / 周期内去重后的更新总数
+	Coverage float64       // Seen / 周期内去重后的更新总数
+	AvgLag   time.Duration // 非最先到达时平均落后多少
+	MaxLag   time.Duration
+}
+
+// NewFanIn window 为去重窗口，同一 key 在窗口内重复到达视为副本
+func NewFanIn(window time.Duration) *FanIn {
+	return &FanIn{
+		window:   window,
+		current:  make(map[string]*arrival),
+		previous: make(map[string]*arrival),
+		rotated:  time.Now(),
+		stats:    make(map[string]*sourceStats),
+	}
+}
+
+// Observe 记录 source 在 at 时刻送达 key，返回是否是第一份
+func (f *FanIn) Observe(source, key string, at time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if at.Sub(f.rotated) >= f.window {
+		f.previous = f.current
+		f.current = make(map[string]*arrival, len(f.previous))
+		f.rotated = at
+	}
+
+	st, ok := f.stats[source]
+	if !ok {
+		st = &sourceStats{}
+		f.stats[source] = st
+	}
+	st.seen++
+
+	first, ok := f.current[key]
+	if !ok {
+		first, ok = f.previous[key]
+	}
+	if ok {
+		if lag := at.Sub(first.at); lag > 0 {
+			st.lagTotal += lag
+			if lag > st.lagMax {
+				st.lagMax = lag
+			}
+		}
+		return false
+	}
+
+	f.current[key] = &arrival{source: source, at: at}
+	f.unique++
+	st.wins++
+	return true
+}
+
+// Report 返回当前周期的统计，reset 为 true 时清零开始新周期
+func (f *FanIn) Report(reset bool) []SourceReport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]SourceReport, 0, len(f.stats))
+	for source, st := range f.stats {
+		r := SourceReport{
+			Source: source,
+			Seen:   st.seen,
+			Wins:   st.wins,
+			MaxLag: st.lagMax,
+		}
+		if f.unique > 0 {
+			r.WinRate = float64(st.wins) / float64(f.unique)
+			r.Coverage = float64(st.seen) / float64(f.unique)
+		}
+		if late := st.seen - st.wins; late > 0 {
+			r.AvgLag = st.lagTotal / time.Duration(late)
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Wins > out[j].Wins })
+
+	if reset {
+		f.unique = 0
+		f.stats = make(map[string]*sourceStats)
+	}
+	return out
+}
+
+// Run 每隔 interval 输出一次各来源的抢先率和延迟报告
+func (f *FanIn) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, r := range f.Report(true) {
+				logx.Infof("[fanin] %s: seen %d, wins %d (%.1f%%), coverage %.1f%%, avg lag %dµs, max lag %dµs",
+					r.Source, r.Seen, r.Wins, r.WinRate*100, r.Coverage*100, r.AvgLag.Microseconds(), r.MaxLag.Microseconds())
+			}
+		}
+	}
+}
+
+// UpdateKey 生成更新的去重 key；返回空串表示不参与去重
+func UpdateKey(resp *pb.SubscribeUpdate) string {
+	switch {
+	case resp.GetTransaction() != nil:
+		info := resp.GetTransaction().GetTransaction()
+		if info == nil {
+			return ""
+		}
+		return "tx:" + solana.SignatureFromBytes(info.Signature).String()
+	case resp.GetAccount() != nil:
+		acc := resp.GetAccount()
+		info := acc.GetAccount()
+		if info == nil {
+			return ""
+		}
+		return "acc:" + solana.PublicKeyFromBytes(info.Pubkey).String() + ":" + strconv.FormatUint(acc.Slot, 10) + ":" + solana.SignatureFromBytes(info.TxnSignature).String()
+	case resp.GetBlockMeta() != nil:
+		return "meta:" + strconv.FormatUint(resp.GetBlockMeta().Slot, 10)
+	case resp.GetSlot() != nil:
+		slot := resp.GetSlot()
+		return "slot:" + strconv.FormatUint(slot.Slot, 10) + ":" + slot.Status.String()
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func TestFanInObserve(t *testing.T) {
+	f := NewFanIn(time.Minute)
+	now := time.Now()
+
+	if !f.Observe("blz", "tx:a", now) {
+		t.Fatal("first arrival should pass")
+	}
+	if f.Observe("triton", "tx:a", now.Add(300*time.Microsecond)) {
+		t.Fatal("duplicate should be dropped")
+	}
+	if !f.Observe("triton", "tx:b", now.Add(time.Millisecond)) {
+		t.Fatal("new key should pass")
+	}
+	if f.Observe("blz", "tx:b", now.Add(1500*time.Microsecond)) {
+		t.Fatal("duplicate should be dropped")
+	}
+	// 窗口轮转后上一窗口的 key 仍然能去重
+	if f.Observe("blz", "tx:a", now.Add(61*time.Second)) {
+		t.Fatal("key from previous window should be dropped")
+	}
+
+	reports := f.Report(true)
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(reports))
+	}
+	for _, r := range reports {
+		if r.Wins != 1 || r.WinRate != 0.5 {
+			t.Fatalf("unexpected report: %+v", r)
+		}
+		if r.Source == "triton" && r.AvgLag != 300*time.Microsecond {
+			t.Fatalf("unexpected triton lag: %v", r.AvgLag)
+		}
+	}
+	if len(f.Report(false)) != 0 {
+		t.Fatal("report should be reset")
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	sig := make([]byte, 64)
+	sig[0] = 1
+	tx := &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Transaction{
+		Transaction: &pb.SubscribeUpdateTransaction{
+			Transaction: &pb.SubscribeUpdateTransactionInfo{Signature: sig},
+		},
+	}}
+	if key := UpdateKey(tx); key == "" || key[:3] != "tx:" {
+		t.Fatalf("unexpected tx key: %q", key)
+	}
+	if key := UpdateKey(&pb.SubscribeUpdate{}); key != "" {
+		t.Fatalf("empty update should not be deduped: %q", key)
+	}
+}
@@ -0,0 +1,56 @@
+package geysertest
+
+import (
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+// Transaction 构造一条交易更新，keys 作为消息的账户列表，第一个账户为付款人
+func Transaction(slot uint64, sig solana.Signature, keys ...solana.PublicKey) *pb.SubscribeUpdate {
+	accountKeys := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		accountKeys = append(accountKeys, k.Bytes())
+	}
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Transaction{
+		Transaction: &pb.SubscribeUpdateTransaction{
+			Slot: slot,
+			Transaction: &pb.SubscribeUpdateTransactionInfo{
+				Signature: sig[:],
+				Transaction: &pb.Transaction{
+					Signatures: [][]byte{sig[:]},
+					Message: &pb.Message{
+						Header:      &pb.MessageHeader{NumRequiredSignatures: 1},
+						AccountKeys: accountKeys,
+					},
+				},
+				Meta: &pb.TransactionStatusMeta{},
+			},
+		},
+	}}
+}
+
+// Account 构造一条账户更新
+func Account(slot uint64, pubkey, owner solana.PublicKey, lamports uint64, data []byte) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Account{
+		Account: &pb.SubscribeUpdateAccount{
+			Slot: slot,
+			Account: &pb.SubscribeUpdateAccountInfo{
+				Pubkey:   pubkey.Bytes(),
+				Owner:    owner.Bytes(),
+				Lamports: lamports,
+				Data:     data,
+			},
+		},
+	}}
+}
+
+// BlockMeta 构造一条区块元数据更新
+func BlockMeta(slot uint64, blockhash solana.Hash) *pb.SubscribeUpdate {
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_BlockMeta{
+		BlockMeta: &pb.SubscribeUpdateBlockMeta{
+			Slot:       slot,
+			Blockhash:  blockhash.String(),
+			ParentSlot: slot - 1,
+		},
+	}}
+}
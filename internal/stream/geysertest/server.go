@@ -0,0 +1,209 @@
+// Package geysertest 提供进程内的假 Geyser gRPC 服务，用于离线、可重复的集成测试。
+// 服务按 Geyser 的过滤语义（AccountInclude 等）把脚本化的 SubscribeUpdate 推给订阅方。
+package geysertest
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"solana-bot/internal/stream"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Server 假 Geyser 服务，监听本地随机端口
+type Server struct {
+	pb.UnimplementedGeyserServer
+
+	// Xtoken 非空时校验请求的 x-token
+	Xtoken string
+	// Script 每个新订阅收到第一个 SubscribeRequest 后依次推送的更新
+	Script []*pb.SubscribeUpdate
+
+	lis  net.Listener
+	srv  *grpc.Server
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+	cond *sync.Cond
+}
+
+type subscriber struct {
+	mu   sync.Mutex
+	req  *pb.SubscribeRequest
+	out  chan *pb.SubscribeUpdate
+	kick chan struct{}
+}
+
+// NewServer 启动服务，测试结束时调用 Close
+func NewServer() (*Server, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		lis:  lis,
+		srv:  grpc.NewServer(),
+		subs: make(map[*subscriber]struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	pb.RegisterGeyserServer(s.srv, s)
+	go s.srv.Serve(lis)
+	return s, nil
+}
+
+// Addr 返回监听地址，可直接传给 stream.Grpc_connect(addr, true)
+func (s *Server) Addr() string {
+	return s.lis.Addr().String()
+}
+
+// Close 停止服务并断开所有订阅
+func (s *Server) Close() {
+	s.srv.Stop()
+}
+
+// Subscribers 当前已发送过 SubscribeRequest 的订阅数
+func (s *Server) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
+
+// WaitSubscribers 等待至少 n 个订阅就绪
+func (s *Server) WaitSubscribers(n int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	timer := time.AfterFunc(timeout, func() {
+		s.mu.Lock()
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.subs) < n {
+		if time.Now().After(deadline) {
+			return errors.New("geysertest: timeout waiting for subscribers")
+		}
+		s.cond.Wait()
+	}
+	return nil
+}
+
+// Push 把更新推给过滤器命中的所有订阅，返回命中的订阅数
+func (s *Server) Push(updates ...*pb.SubscribeUpdate) int {
+	s.mu.Lock()
+	subs := make([]*subscriber, 0, len(s.subs))
+	for sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	hit := 0
+	for _, update := range updates {
+		for _, sub := range subs {
+			if sub.send(update) {
+				hit++
+			}
+		}
+	}
+	return hit
+}
+
+// Kick 断开所有当前订阅，用于测试客户端重连
+func (s *Server) Kick() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		close(sub.kick)
+		delete(s.subs, sub)
+	}
+}
+
+func (s *Server) Subscribe(srv pb.Geyser_SubscribeServer) error {
+	if s.Xtoken != "" {
+		md, _ := metadata.FromIncomingContext(srv.Context())
+		if tokens := md.Get("x-token"); len(tokens) == 0 || tokens[0] != s.Xtoken {
+			return status.Error(codes.Unauthenticated, "invalid x-token")
+		}
+	}
+
+	req, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	sub := &subscriber{
+		req:  req,
+		out:  make(chan *pb.SubscribeUpdate, 1024),
+		kick: make(chan struct{}),
+	}
+	for _, update := range s.Script {
+		sub.send(update)
+	}
+
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, sub)
+		s.mu.Unlock()
+	}()
+
+	// 客户端可以在同一条流上随时替换过滤器
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			req, err := srv.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			sub.mu.Lock()
+			sub.req = req
+			sub.mu.Unlock()
+		}
+	}()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return srv.Context().Err()
+		case <-sub.kick:
+			return status.Error(codes.Unavailable, "kicked")
+		case err := <-errCh:
+			return err
+		case update := <-sub.out:
+			if err := srv.Send(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send 按订阅当前的过滤器匹配更新，命中时带上过滤器名入队
+func (sub *subscriber) send(update *pb.SubscribeUpdate) bool {
+	sub.mu.Lock()
+	names := stream.MatchFilters(sub.req, update)
+	sub.mu.Unlock()
+	if len(names) == 0 {
+		return false
+	}
+	out := &pb.SubscribeUpdate{
+		Filters:     names,
+		UpdateOneof: update.UpdateOneof,
+		CreatedAt:   update.CreatedAt,
+	}
+	select {
+	case sub.out <- out:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,118 @@
+package geysertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/stream"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"google.golang.org/grpc"
+)
+
+func newHub(t *testing.T, ctx context.Context, srv *Server) *stream.Hub {
+	t.Helper()
+	conn := stream.Grpc_connect(srv.Addr(), true)
+	if conn == nil {
+		t.Fatal("connect failed")
+	}
+	t.Cleanup(func() { conn.Close() })
+	return stream.NewHub(ctx, &stream.GrpcStream{Name: "fake", Conns: []*grpc.ClientConn{conn}, Xtoken: srv.Xtoken})
+}
+
+func recvTx(t *testing.T, ch chan interface{}) solana.Signature {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		info := msg.(*stream.StreamMessage).Data.(*pb.SubscribeUpdate).GetTransaction().GetTransaction()
+		return solana.SignatureFromBytes(info.Signature)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for transaction")
+	}
+	return solana.Signature{}
+}
+
+func TestHubAccountInclude(t *testing.T) {
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Xtoken = "test-token"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := newHub(t, ctx, srv)
+
+	mint := solana.NewWallet().PublicKey()
+	recv := make(chan interface{}, 16)
+	hub.Subscribe(ctx, &pb.SubscribeRequest{
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"transactions_sub": {AccountInclude: []string{mint.String()}},
+		},
+	}, recv)
+	if err := srv.WaitSubscribers(1, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	payer := solana.NewWallet().PublicKey()
+	hit := solana.Signature{1}
+	// 过滤器推送是异步的，等服务端看到 AccountInclude 后再推
+	deadline := time.Now().Add(3 * time.Second)
+	for srv.Push(Transaction(1, hit, payer, mint)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("filter never reached server")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	srv.Push(Transaction(1, solana.Signature{2}, payer))
+
+	if got := recvTx(t, recv); got != hit {
+		t.Fatalf("unexpected signature %s", got)
+	}
+	select {
+	case msg := <-recv:
+		t.Fatalf("unmatched transaction delivered: %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBlockMetaAndReconnect(t *testing.T) {
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := newHub(t, ctx, srv)
+	go stream.BlockSubscribeWithRelay(ctx, hub)
+
+	wait := func(slot uint64, hash solana.Hash) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for global.GetSlot() != slot {
+			if time.Now().After(deadline) {
+				t.Fatalf("slot %d not applied, got %d", slot, global.GetSlot())
+			}
+			srv.Push(BlockMeta(slot, hash))
+			time.Sleep(20 * time.Millisecond)
+		}
+		if global.GetBlockHash() != hash {
+			t.Fatalf("unexpected blockhash %s", global.GetBlockHash())
+		}
+	}
+
+	wait(100, solana.Hash{1})
+
+	// 服务端断开后 Subscription 应按退避自动重连并重发过滤器
+	srv.Kick()
+	wait(101, solana.Hash{2})
+	if st := hub.Status(); len(st) != 1 || st[0].Reconnects == 0 {
+		t.Fatalf("expected a reconnect, got %+v", st)
+	}
+}
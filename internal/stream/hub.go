@@ -0,0 +1,281 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	hubQueueSize   = 1024
+	dedupWindow    = 2 * time.Minute
+	reportInterval = time.Minute
+)
+
+// Hub 每个端点只维持一条双向 Subscribe 流；消费者在运行时注册/注销过滤器，
+// Hub 合并所有过滤器推送到已有的流上，并按 SubscribeUpdate.Filters 把更新路由给对应的消费者。
+// 多个来源的同一条更新经 fan-in 去重，只有最先到达的一份会被分发
+type Hub struct {
+	ctx        context.Context
+	subs       []*Subscription
+	commitment pb.CommitmentLevel
+	fanin      *FanIn
+	recorder   atomic.Pointer[Recorder]
+
+	slotsOnce sync.Once
+	slots     *SlotTracker
+
+	pushMu    sync.Mutex
+	mu        sync.RWMutex
+	nextID    uint64
+	consumers map[uint64]*HubConsumer
+}
+
+// HubConsumer 一个已注册的消费者
+type HubConsumer struct {
+	id     uint64
+	hub    *Hub
+	filter *pb.SubscribeRequest
+	queue  chan *StreamMessage
+	cancel context.CancelFunc
+}
+
+func NewHub(ctx context.Context, streams ...*GrpcStream) *Hub {
+	h := &Hub{
+		ctx:        ctx,
+		commitment: pb.CommitmentLevel_PROCESSED,
+		fanin:      NewFanIn(dedupWindow),
+		consumers:  make(map[uint64]*HubConsumer),
+	}
+	for _, gs := range streams {
+		for _, conn := range gs.Conns {
+			source := conn.CanonicalTarget()
+			if gs.Name != "" {
+				source = gs.Name + "/" + source
+			}
+			sub := NewSubscription(conn, gs.Xtoken, h.buildRequest())
+			h.subs = append(h.subs, sub)
+			go sub.Run(ctx, func(resp *pb.SubscribeUpdate) {
+				h.route(source, resp)
+			})
+		}
+	}
+	if len(h.subs) > 1 {
+		go h.fanin.Run(ctx, reportInterval)
+	}
+	return h
+}
+
+// SetRecorder 开启录制，之后所有来源收到的原始更新（含重复副本）都写入 r；传 nil 关闭
+func (h *Hub) SetRecorder(r *Recorder) {
+	h.recorder.Store(r)
+}
+
+// Inject 注入一条外部更新（如回放文件），按当前消费者的过滤器在本地重新匹配后分发
+func (h *Hub) Inject(source string, resp *pb.SubscribeUpdate) {
+	h.mu.RLock()
+	filters := make([]string, 0, 1)
+	for id, c := range h.consumers {
+		for _, name := range MatchFilters(c.filter, resp) {
+			filters = append(filters, filterKey(id, name))
+		}
+	}
+	h.mu.RUnlock()
+
+	resp.Filters = filters
+	h.route(source, resp)
+}
+
+// Slots 返回 Hub 上共享的 slot 状态跟踪器，首次调用时开始订阅 slot 更新
+func (h *Hub) Slots() *SlotTracker {
+	h.slotsOnce.Do(func() {
+		h.slots = NewSlotTracker()
+		go h.slots.Run(h.ctx, h)
+	})
+	return h.slots
+}
+
+// FanIn 返回各来源的抢先率/延迟统计
+func (h *Hub) FanIn() *FanIn {
+	return h.fanin
+}
+
+// Subscribe 注册 filter 中的过滤器（Accounts/Transactions/Slots/BlocksMeta），
+// 匹配的更新以 *StreamMessage 写入 recv；ctx 结束或调用 Close 后注销过滤器并关闭 recv
+func (h *Hub) Subscribe(ctx context.Context, filter *pb.SubscribeRequest, recv chan interface{}) *HubConsumer {
+	ctx, cancel := context.WithCancel(ctx)
+
+	h.mu.Lock()
+	h.nextID++
+	c := &HubConsumer{
+		id:     h.nextID,
+		hub:    h,
+		filter: filter,
+		queue:  make(chan *StreamMessage, hubQueueSize),
+		cancel: cancel,
+	}
+	h.consumers[c.id] = c
+	h.mu.Unlock()
+
+	h.push()
+
+	go func() {
+		defer close(recv)
+		defer h.remove(c.id)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.ctx.Done():
+				return
+			case msg := <-c.queue:
+				select {
+				case recv <- msg:
+				case <-ctx.Done():
+					return
+				case <-h.ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return c
+}
+
+// Update 替换消费者的过滤器，不会新建连接
+func (c *HubConsumer) Update(filter *pb.SubscribeRequest) {
+	c.hub.mu.Lock()
+	c.filter = filter
+	c.hub.mu.Unlock()
+	c.hub.push()
+}
+
+// Close 注销消费者
+func (c *HubConsumer) Close() {
+	c.cancel()
+}
+
+// Status 返回 Hub 下每个端点的连接状态
+func (h *Hub) Status() []SubscriptionStatus {
+	out := make([]SubscriptionStatus, 0, len(h.subs))
+	for _, s := range h.subs {
+		out = append(out, s.Status())
+	}
+	return out
+}
+
+func (h *Hub) remove(id uint64) {
+	h.mu.Lock()
+	_, ok := h.consumers[id]
+	delete(h.consumers, id)
+	h.mu.Unlock()
+	if ok && h.ctx.Err() == nil {
+		h.push()
+	}
+}
+
+// push 重新合并过滤器并推送到所有端点
+func (h *Hub) push() {
+	h.pushMu.Lock()
+	defer h.pushMu.Unlock()
+	req := h.buildRequest()
+	for _, s := range h.subs {
+		if err := s.Update(req); err != nil {
+			logx.Errorf("[%s]: update subscription error: %v", s.Target(), err)
+		}
+	}
+}
+
+func (h *Hub) buildRequest() *pb.SubscribeRequest {
+	commitment := h.commitment
+	req := &pb.SubscribeRequest{
+		Commitment:   &commitment,
+		Accounts:     make(map[string]*pb.SubscribeRequestFilterAccounts),
+		Transactions: make(map[string]*pb.SubscribeRequestFilterTransactions),
+		Slots:        make(map[string]*pb.SubscribeRequestFilterSlots),
+		BlocksMeta:   make(map[string]*pb.SubscribeRequestFilterBlocksMeta),
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for id, c := range h.consumers {
+		if c.filter == nil {
+			continue
+		}
+		for k, v := range c.filter.Accounts {
+			req.Accounts[filterKey(id, k)] = v
+		}
+		for k, v := range c.filter.Transactions {
+			req.Transactions[filterKey(id, k)] = v
+		}
+		for k, v := range c.filter.Slots {
+			req.Slots[filterKey(id, k)] = v
+		}
+		for k, v := range c.filter.BlocksMeta {
+			req.BlocksMeta[filterKey(id, k)] = v
+		}
+	}
+	return req
+}
+
+// route 把更新分发给过滤器命中的消费者，其他来源已送达过的副本直接丢弃
+func (h *Hub) route(source string, resp *pb.SubscribeUpdate) {
+	if r := h.recorder.Load(); r != nil {
+		if err := r.Write(source, time.Now(), resp); err != nil {
+			logx.Errorf("[%s]: record update error: %v", source, err)
+		}
+	}
+	if key := UpdateKey(resp); key != "" && !h.fanin.Observe(source, key, time.Now()) {
+		return
+	}
+
+	msg := &StreamMessage{
+		Source: source,
+		Data:   resp,
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	delivered := make(map[uint64]struct{}, len(resp.Filters))
+	for _, name := range resp.Filters {
+		id, ok := parseFilterKey(name)
+		if !ok {
+			continue
+		}
+		if _, ok := delivered[id]; ok {
+			continue
+		}
+		delivered[id] = struct{}{}
+
+		c, ok := h.consumers[id]
+		if !ok {
+			continue
+		}
+		select {
+		case c.queue <- msg:
+		default:
+			logx.Errorf("[%s]: consumer %d queue full, drop message", source, id)
+		}
+	}
+}
+
+func filterKey(id uint64, name string) string {
+	return strconv.FormatUint(id, 10) + "/" + name
+}
+
+func parseFilterKey(key string) (uint64, bool) {
+	idx := strings.IndexByte(key, '/')
+	if idx <= 0 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(key[:idx], 10, 64)
+	return id, err == nil
+}
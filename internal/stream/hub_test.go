@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func TestHubRoute(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := NewHub(ctx, &GrpcStream{})
+
+	mint := make(chan interface{})
+	h.Subscribe(ctx, &pb.SubscribeRequest{
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"transactions_sub": {AccountInclude: []string{"mint"}},
+		},
+	}, mint)
+
+	nonceCtx, closeNonce := context.WithCancel(ctx)
+	nonce := make(chan interface{})
+	h.Subscribe(nonceCtx, &pb.SubscribeRequest{
+		Accounts: map[string]*pb.SubscribeRequestFilterAccounts{
+			"account_sub": {Account: []string{"nonce"}},
+		},
+	}, nonce)
+
+	req := h.buildRequest()
+	if _, ok := req.Transactions["1/transactions_sub"]; !ok {
+		t.Fatalf("missing merged transaction filter: %v", req.Transactions)
+	}
+	if _, ok := req.Accounts["2/account_sub"]; !ok {
+		t.Fatalf("missing merged account filter: %v", req.Accounts)
+	}
+
+	h.route("test", &pb.SubscribeUpdate{Filters: []string{"1/transactions_sub"}})
+	select {
+	case msg := <-mint:
+		if msg.(*StreamMessage).Source != "test" {
+			t.Fatalf("unexpected source: %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("mint consumer got nothing")
+	}
+	select {
+	case msg := <-nonce:
+		t.Fatalf("nonce consumer should not receive: %v", msg)
+	default:
+	}
+
+	closeNonce()
+	if _, ok := <-nonce; ok {
+		t.Fatal("nonce channel should be closed")
+	}
+	if _, ok := h.buildRequest().Accounts["2/account_sub"]; ok {
+		t.Fatal("account filter should be removed after close")
+	}
+}
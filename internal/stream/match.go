@@ -0,0 +1,160 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"github.com/mr-tron/base58"
+)
+
+// MatchFilters 按 Geyser 的过滤语义在本地判断 resp 命中了 req 中的哪些过滤器，返回命中的过滤器名。
+// 用于回放录制文件等没有服务端过滤的场景；账户过滤只支持 memcmp 和 datasize
+func MatchFilters(req *pb.SubscribeRequest, resp *pb.SubscribeUpdate) []string {
+	if req == nil || resp == nil {
+		return nil
+	}
+	var names []string
+	switch {
+	case resp.GetTransaction() != nil:
+		info := resp.GetTransaction().GetTransaction()
+		if info == nil {
+			return nil
+		}
+		for name, f := range req.Transactions {
+			if matchTransaction(f, info) {
+				names = append(names, name)
+			}
+		}
+	case resp.GetAccount() != nil:
+		info := resp.GetAccount().GetAccount()
+		if info == nil {
+			return nil
+		}
+		for name, f := range req.Accounts {
+			if matchAccount(f, info) {
+				names = append(names, name)
+			}
+		}
+	case resp.GetSlot() != nil:
+		for name := range req.Slots {
+			names = append(names, name)
+		}
+	case resp.GetBlockMeta() != nil:
+		for name := range req.BlocksMeta {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func matchTransaction(f *pb.SubscribeRequestFilterTransactions, info *pb.SubscribeUpdateTransactionInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.Vote != nil && *f.Vote != info.IsVote {
+		return false
+	}
+	if f.Failed != nil && *f.Failed != (info.GetMeta().GetErr() != nil) {
+		return false
+	}
+	if f.Signature != nil && *f.Signature != solana.SignatureFromBytes(info.Signature).String() {
+		return false
+	}
+
+	keys := make(map[string]struct{})
+	for _, k := range info.GetTransaction().GetMessage().GetAccountKeys() {
+		keys[solana.PublicKeyFromBytes(k).String()] = struct{}{}
+	}
+	for _, k := range info.GetMeta().GetLoadedWritableAddresses() {
+		keys[solana.PublicKeyFromBytes(k).String()] = struct{}{}
+	}
+	for _, k := range info.GetMeta().GetLoadedReadonlyAddresses() {
+		keys[solana.PublicKeyFromBytes(k).String()] = struct{}{}
+	}
+
+	if len(f.AccountInclude) > 0 && !containsAny(keys, f.AccountInclude) {
+		return false
+	}
+	if containsAny(keys, f.AccountExclude) {
+		return false
+	}
+	for _, k := range f.AccountRequired {
+		if _, ok := keys[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAccount(f *pb.SubscribeRequestFilterAccounts, info *pb.SubscribeUpdateAccountInfo) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Account) > 0 && !containsString(f.Account, solana.PublicKeyFromBytes(info.Pubkey).String()) {
+		return false
+	}
+	if len(f.Owner) > 0 && !containsString(f.Owner, solana.PublicKeyFromBytes(info.Owner).String()) {
+		return false
+	}
+	if f.NonemptyTxnSignature != nil && *f.NonemptyTxnSignature != (len(info.TxnSignature) > 0) {
+		return false
+	}
+	for _, filter := range f.Filters {
+		switch v := filter.GetFilter().(type) {
+		case *pb.SubscribeRequestFilterAccountsFilter_Datasize:
+			if uint64(len(info.Data)) != v.Datasize {
+				return false
+			}
+		case *pb.SubscribeRequestFilterAccountsFilter_Memcmp:
+			if !matchMemcmp(v.Memcmp, info.Data) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchMemcmp(m *pb.SubscribeRequestFilterAccountsFilterMemcmp, data []byte) bool {
+	var want []byte
+	switch v := m.GetData().(type) {
+	case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Bytes:
+		want = v.Bytes
+	case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Base58:
+		b, err := base58.Decode(v.Base58)
+		if err != nil {
+			return false
+		}
+		want = b
+	case *pb.SubscribeRequestFilterAccountsFilterMemcmp_Base64:
+		b, err := base64.StdEncoding.DecodeString(v.Base64)
+		if err != nil {
+			return false
+		}
+		want = b
+	}
+	end := m.Offset + uint64(len(want))
+	if end > uint64(len(data)) {
+		return false
+	}
+	return bytes.Equal(data[m.Offset:end], want)
+}
+
+func containsAny(set map[string]struct{}, list []string) bool {
+	for _, k := range list {
+		if _, ok := set[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
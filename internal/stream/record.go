@@ -0,0 +1,180 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// 录制文件格式：文件头 recordMagic，之后每条记录为
+// [4 字节记录长度][8 字节接收时间 UnixNano][1 字节来源长度][来源][SubscribeUpdate protobuf]，整数均为大端
+const recordMagic = "SBREC1\n"
+
+const maxRecordSize = 64 << 20
+
+// Record 录制文件中的一条更新
+type Record struct {
+	At     time.Time
+	Source string
+	Update *pb.SubscribeUpdate
+}
+
+// Recorder 把收到的 SubscribeUpdate 追加写入录制文件
+type Recorder struct {
+	mu   sync.Mutex
+	f    *os.File
+	w    *bufio.Writer
+	buf  []byte
+	last time.Time
+}
+
+// NewRecorder 打开（或创建）录制文件并追加写入
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &Recorder{f: f, w: bufio.NewWriterSize(f, 1<<20)}
+	if info.Size() == 0 {
+		if _, err := r.w.WriteString(recordMagic); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Write 写入一条更新；为了不拖慢接收，每秒最多刷盘一次
+func (r *Recorder) Write(source string, at time.Time, resp *pb.SubscribeUpdate) error {
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if len(source) > 255 {
+		source = source[:255]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return os.ErrClosed
+	}
+
+	size := 8 + 1 + len(source) + len(data)
+	r.buf = r.buf[:0]
+	r.buf = binary.BigEndian.AppendUint32(r.buf, uint32(size))
+	r.buf = binary.BigEndian.AppendUint64(r.buf, uint64(at.UnixNano()))
+	r.buf = append(r.buf, byte(len(source)))
+	r.buf = append(r.buf, source...)
+	if _, err := r.w.Write(r.buf); err != nil {
+		return err
+	}
+	if _, err := r.w.Write(data); err != nil {
+		return err
+	}
+	if at.Sub(r.last) >= time.Second {
+		r.last = at
+		return r.w.Flush()
+	}
+	return nil
+}
+
+// Close 刷盘并关闭文件
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil {
+		return nil
+	}
+	err := r.w.Flush()
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	r.w = nil
+	return err
+}
+
+// RecordReader 顺序读取录制文件
+type RecordReader struct {
+	r *bufio.Reader
+	c io.Closer
+}
+
+func OpenRecord(path string) (*RecordReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	rr, err := NewRecordReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rr.c = f
+	return rr, nil
+}
+
+func NewRecordReader(r io.Reader) (*RecordReader, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	magic := make([]byte, len(recordMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("read record header: %w", err)
+	}
+	if string(magic) != recordMagic {
+		return nil, errors.New("not a record file")
+	}
+	return &RecordReader{r: br}, nil
+}
+
+// Next 返回下一条记录，读完返回 io.EOF；末尾写了一半的记录也视为结束
+func (rr *RecordReader) Next() (*Record, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(rr.r, head[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(head[:])
+	if size < 9 || size > maxRecordSize {
+		return nil, fmt.Errorf("invalid record size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+
+	at := time.Unix(0, int64(binary.BigEndian.Uint64(buf[:8])))
+	n := int(buf[8])
+	if 9+n > len(buf) {
+		return nil, fmt.Errorf("invalid source length %d", n)
+	}
+	resp := &pb.SubscribeUpdate{}
+	if err := proto.Unmarshal(buf[9+n:], resp); err != nil {
+		return nil, err
+	}
+	return &Record{At: at, Source: string(buf[9 : 9+n]), Update: resp}, nil
+}
+
+func (rr *RecordReader) Close() error {
+	if rr.c == nil {
+		return nil
+	}
+	return rr.c.Close()
+}
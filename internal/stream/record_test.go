@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func txUpdate(sig byte, keys ...solana.PublicKey) *pb.SubscribeUpdate {
+	signature := make([]byte, 64)
+	signature[0] = sig
+	var accountKeys [][]byte
+	for _, k := range keys {
+		accountKeys = append(accountKeys, k.Bytes())
+	}
+	return &pb.SubscribeUpdate{UpdateOneof: &pb.SubscribeUpdate_Transaction{
+		Transaction: &pb.SubscribeUpdateTransaction{
+			Transaction: &pb.SubscribeUpdateTransactionInfo{
+				Signature:   signature,
+				Transaction: &pb.Transaction{Message: &pb.Message{AccountKeys: accountKeys}},
+				Meta:        &pb.TransactionStatusMeta{},
+			},
+		},
+	}}
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.rec")
+	mint := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+
+	rec, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := rec.Write("blz", now, txUpdate(1, mint)); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Write("triton", now.Add(time.Millisecond), txUpdate(2, other)); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rr, err := OpenRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := rr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Source != "blz" || !first.At.Equal(time.Unix(0, now.UnixNano())) {
+		t.Fatalf("unexpected record: %+v", first)
+	}
+	rr.Close()
+
+	recv := make(chan interface{}, 4)
+	src := NewReplaySource(path, 0)
+	src.Subscribe(context.Background(), &pb.SubscribeRequest{
+		Transactions: map[string]*pb.SubscribeRequestFilterTransactions{
+			"transactions_sub": {AccountInclude: []string{mint.String()}},
+		},
+	}, &sync.Once{}, recv)
+
+	var got []*StreamMessage
+	for msg := range recv {
+		got = append(got, msg.(*StreamMessage))
+	}
+	if len(got) != 1 || got[0].Source != "blz" {
+		t.Fatalf("expected only the mint transaction, got %d", len(got))
+	}
+	if f := got[0].Data.(*pb.SubscribeUpdate).Filters; len(f) != 1 || f[0] != "transactions_sub" {
+		t.Fatalf("unexpected filters: %v", f)
+	}
+}
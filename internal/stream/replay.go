@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ReplaySource 把录制文件重新作为更新流输出，用来离线复现一段行情
+type ReplaySource struct {
+	Path  string
+	Speed float64 // 1 为原速，2 为两倍速，<=0 表示不等待、尽快回放
+}
+
+func NewReplaySource(path string, speed float64) *ReplaySource {
+	return &ReplaySource{Path: path, Speed: speed}
+}
+
+// Run 按录制顺序把每条更新交给 handler，读完文件或 ctx 结束时返回
+func (r *ReplaySource) Run(ctx context.Context, handler func(source string, resp *pb.SubscribeUpdate)) error {
+	rr, err := OpenRecord(r.Path)
+	if err != nil {
+		return err
+	}
+	defer rr.Close()
+
+	var (
+		first   time.Time
+		started = time.Now()
+		count   int
+	)
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rec, err := rr.Next()
+		if err == io.EOF {
+			logx.Infof("[replay] %s done, %d updates", r.Path, count)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if r.Speed > 0 {
+			if first.IsZero() {
+				first = rec.At
+			}
+			due := started.Add(time.Duration(float64(rec.At.Sub(first)) / r.Speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		count++
+		handler(rec.Source, rec.Update)
+	}
+}
+
+// Subscribe 与 GrpcStream.Subscribe 相同的用法：只输出命中 subscription 过滤器的更新，
+// 回放结束或 ctx 结束后关闭 recv
+func (r *ReplaySource) Subscribe(ctx context.Context, subscription *pb.SubscribeRequest, once *sync.Once, recv chan interface{}) {
+	go func() {
+		defer once.Do(func() {
+			close(recv)
+		})
+		err := r.Run(ctx, func(source string, resp *pb.SubscribeUpdate) {
+			names := MatchFilters(subscription, resp)
+			if len(names) == 0 {
+				return
+			}
+			resp.Filters = names
+			select {
+			case recv <- &StreamMessage{Source: source, Data: resp}:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			logx.Errorf("[replay] %s: %v", r.Path, err)
+		}
+	}()
+}
@@ -0,0 +1,189 @@
+package stream
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SlotCommitment slot 的确认状态
+type SlotCommitment int32
+
+const (
+	SlotUnknown SlotCommitment = iota
+	SlotProcessed
+	SlotConfirmed
+	SlotFinalized
+	SlotRolledBack // 收到 SLOT_DEAD，其中的交易不会上链
+	// finalized 已越过但没收到它的 finalized 更新：可能被跳过，也可能只是更新被 hub 丢了，
+	// 需要按交易签名确认
+	SlotMissing
+)
+
+func (c SlotCommitment) String() string {
+	switch c {
+	case SlotProcessed:
+		return "processed"
+	case SlotConfirmed:
+		return "confirmed"
+	case SlotFinalized:
+		return "finalized"
+	case SlotRolledBack:
+		return "rolled back"
+	case SlotMissing:
+		return "missing"
+	}
+	return "unknown"
+}
+
+// Final 是否已经是最终状态
+func (c SlotCommitment) Final() bool {
+	return c == SlotFinalized || c == SlotRolledBack || c == SlotMissing
+}
+
+const (
+	// finalized 推进到 slot+rollbackMargin 之后仍未 finalized 的 slot 判定为 missing
+	rollbackMargin = 8
+	// 只保留 finalized 之前这么多个 slot 的状态
+	slotHistory = 512
+)
+
+// SlotTracker 根据 slot 更新跟踪每个 slot 的确认状态，
+// 让基于 PROCESSED 事件做出的动作可以在 slot 被回滚时收到通知
+type SlotTracker struct {
+	mu        sync.Mutex
+	slots     map[uint64]SlotCommitment
+	finalized uint64
+	watchers  map[uint64][]chan SlotCommitment
+}
+
+func NewSlotTracker() *SlotTracker {
+	return &SlotTracker{
+		slots:    make(map[uint64]SlotCommitment),
+		watchers: make(map[uint64][]chan SlotCommitment),
+	}
+}
+
+// Run 通过 hub 订阅所有状态的 slot 更新，直到 ctx 结束
+func (s *SlotTracker) Run(ctx context.Context, hub *Hub) {
+	filterByCommitment := false
+	subscribe := make(chan interface{})
+	hub.Subscribe(ctx, &pb.SubscribeRequest{
+		Slots: map[string]*pb.SubscribeRequestFilterSlots{
+			"slot_status": {FilterByCommitment: &filterByCommitment},
+		},
+	}, subscribe)
+
+	for msg := range subscribe {
+		slot := msg.(*StreamMessage).Data.(*pb.SubscribeUpdate).GetSlot()
+		if slot == nil {
+			continue
+		}
+		s.Observe(slot.Slot, slot.Status)
+	}
+}
+
+// Observe 记录一条 slot 状态更新
+func (s *SlotTracker) Observe(slot uint64, status pb.SlotStatus) {
+	var c SlotCommitment
+	switch status {
+	case pb.SlotStatus_SLOT_PROCESSED:
+		c = SlotProcessed
+	case pb.SlotStatus_SLOT_CONFIRMED:
+		c = SlotConfirmed
+	case pb.SlotStatus_SLOT_FINALIZED:
+		c = SlotFinalized
+	case pb.SlotStatus_SLOT_DEAD:
+		c = SlotRolledBack
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur := s.slots[slot]; cur.Final() || cur >= c {
+		return
+	}
+	if slot+slotHistory < s.finalized {
+		return
+	}
+	s.slots[slot] = c
+	if c == SlotRolledBack {
+		logx.Errorf("[slots]: slot %d dead", slot)
+	}
+	if c.Final() {
+		s.notify(slot, c)
+	}
+	if c == SlotFinalized && slot > s.finalized {
+		s.finalized = slot
+		s.sweep()
+	}
+}
+
+// Status 返回 slot 当前的确认状态
+func (s *SlotTracker) Status(slot uint64) SlotCommitment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status(slot)
+}
+
+func (s *SlotTracker) status(slot uint64) SlotCommitment {
+	c := s.slots[slot]
+	if !c.Final() && c != SlotUnknown && slot+rollbackMargin <= s.finalized {
+		return SlotMissing
+	}
+	return c
+}
+
+// Watch 返回的 channel 在 slot 进入最终状态（finalized、回滚或 missing）时收到一次结果；
+// 如果一直没见过该 slot，finalized 越过它后收到 SlotUnknown
+func (s *SlotTracker) Watch(slot uint64) <-chan SlotCommitment {
+	ch := make(chan SlotCommitment, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.status(slot); c.Final() {
+		ch <- c
+		return ch
+	}
+	if s.finalized > 0 && slot+rollbackMargin <= s.finalized {
+		ch <- SlotUnknown
+		return ch
+	}
+	s.watchers[slot] = append(s.watchers[slot], ch)
+	return ch
+}
+
+func (s *SlotTracker) notify(slot uint64, c SlotCommitment) {
+	for _, ch := range s.watchers[slot] {
+		ch <- c
+	}
+	delete(s.watchers, slot)
+}
+
+// sweep finalized 推进后结算被跳过的 slot 并清理历史
+func (s *SlotTracker) sweep() {
+	for slot := range s.watchers {
+		if slot+rollbackMargin > s.finalized {
+			continue
+		}
+		c := s.status(slot)
+		if c.Final() {
+			if c == SlotMissing {
+				logx.Errorf("[slots]: slot %d not finalized, finalized %d", slot, s.finalized)
+			}
+			s.notify(slot, c)
+		} else {
+			s.notify(slot, SlotUnknown)
+		}
+	}
+	for slot := range s.slots {
+		if slot+slotHistory < s.finalized {
+			delete(s.slots, slot)
+		}
+	}
+}
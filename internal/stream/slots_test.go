@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"testing"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+)
+
+func TestSlotTracker(t *testing.T) {
+	s := NewSlotTracker()
+
+	s.Observe(100, pb.SlotStatus_SLOT_PROCESSED)
+	s.Observe(101, pb.SlotStatus_SLOT_PROCESSED)
+	s.Observe(102, pb.SlotStatus_SLOT_PROCESSED)
+
+	landed := s.Watch(100)
+	skipped := s.Watch(101)
+	dead := s.Watch(102)
+
+	s.Observe(102, pb.SlotStatus_SLOT_DEAD)
+	if got := <-dead; got != SlotRolledBack {
+		t.Fatalf("dead slot: got %v", got)
+	}
+
+	s.Observe(100, pb.SlotStatus_SLOT_CONFIRMED)
+	if got := s.Status(100); got != SlotConfirmed {
+		t.Fatalf("expected confirmed, got %v", got)
+	}
+	s.Observe(100, pb.SlotStatus_SLOT_FINALIZED)
+	if got := <-landed; got != SlotFinalized {
+		t.Fatalf("landed slot: got %v", got)
+	}
+
+	// 101 一直没有 finalized，finalized 越过 margin 后只判定为 missing，不当作回滚
+	select {
+	case got := <-skipped:
+		t.Fatalf("skipped slot resolved too early: %v", got)
+	default:
+	}
+	s.Observe(101+rollbackMargin, pb.SlotStatus_SLOT_FINALIZED)
+	if got := <-skipped; got != SlotMissing {
+		t.Fatalf("skipped slot: got %v", got)
+	}
+
+	// 从没见过的旧 slot 不能判定为回滚
+	if got := <-s.Watch(50); got != SlotUnknown {
+		t.Fatalf("unseen slot: got %v", got)
+	}
+}
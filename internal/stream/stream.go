@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/json"
-	"io"
 	"sync"
 	"time"
 
@@ -15,7 +14,6 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/metadata"
 )
 
 // StreamMessage 包装来自不同流的消息，添加来源信息
@@ -25,6 +23,7 @@ type StreamMessage struct {
 }
 
 type GrpcStream struct {
+	Name   string // 来源名称，用于 fan-in 统计
 	Conns  []*grpc.ClientConn
 	Xtoken string
 }
@@ -64,9 +63,6 @@ func Grpc_connect(address string, plaintext bool) *grpc.ClientConn {
 }
 
 func Grpc_subscribe_once(ctx context.Context, conn *grpc.ClientConn, xtoken string, subscription *pb.SubscribeRequest, once *sync.Once, recv chan interface{}) {
-	var err error
-	client := pb.NewGeyserClient(conn)
-
 	subscriptionJson, err := json.Marshal(&subscription)
 	if err != nil {
 		logx.Errorf("[%s]: Failed to marshal subscription request: %v", conn.CanonicalTarget(), subscriptionJson)
@@ -75,55 +71,26 @@ func Grpc_subscribe_once(ctx context.Context, conn *grpc.ClientConn, xtoken stri
 
 	logx.Infof("[%s]: Subscription request: %s", conn.CanonicalTarget(), string(subscriptionJson))
 
-	// Set up the subscription request
-	relayCtx := context.Background()
-	md := metadata.New(map[string]string{"x-token": xtoken})
-	relayCtx = metadata.NewOutgoingContext(relayCtx, md)
-	stream, err := client.Subscribe(relayCtx)
-	if err != nil {
-		logx.Errorf("[%s]: Subscribe error:%v", conn.CanonicalTarget(), err)
-		return
-	}
-	err = stream.Send(subscription)
-	if err != nil {
-		logx.Errorf("[%s]: Subscribe Send error:%v", conn.CanonicalTarget(), err)
-		return
-	}
-	logx.Infof("[%s]: start recv ...", conn.CanonicalTarget())
-	for {
-		select {
-		case <-ctx.Done():
-			logx.Infof("[%s]: Context done, stopping subscription", conn.CanonicalTarget())
-			once.Do(func() {
-				close(recv)
-			})
-			return
-		default:
-			resp, err := stream.Recv()
-
-			//打印
-			// spew.Dump(resp)
-
-			if err == io.EOF {
-				logx.Infof("[%s]: Stream closed", conn.CanonicalTarget())
-				return
-			}
-			if err != nil {
-				logx.Errorf("[%s]: Error occurred in receiving update: %v", conn.CanonicalTarget(), err)
-				continue
-			}
-
-			// 包装消息，添加来源信息
-			wrappedMsg := &StreamMessage{
-				Source: conn.CanonicalTarget(),
-				Data:   resp,
-			}
-			// recv <- wrappedMsg
-			if !safeSend(recv, wrappedMsg) {
-				logx.Errorf("[%s]: Failed to send message to channel", conn.CanonicalTarget())
-			}
+	sub := NewSubscription(conn, xtoken, subscription)
+	err = sub.Run(ctx, func(resp *pb.SubscribeUpdate) {
+		// 包装消息，添加来源信息
+		wrappedMsg := &StreamMessage{
+			Source: conn.CanonicalTarget(),
+			Data:   resp,
+		}
+		if !safeSend(recv, wrappedMsg) {
+			logx.Errorf("[%s]: Failed to send message to channel", conn.CanonicalTarget())
 		}
+	})
+
+	if ctx.Err() != nil {
+		logx.Infof("[%s]: Context done, stopping subscription", conn.CanonicalTarget())
+		once.Do(func() {
+			close(recv)
+		})
+		return
 	}
+	logx.Errorf("[%s]: Subscription stopped: %v", conn.CanonicalTarget(), err)
 }
 
 func safeSend(ch chan interface{}, v *StreamMessage) (ok bool) {
@@ -0,0 +1,302 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/lonelybeanz/solanaswap-go/yellowstone-grpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ConnState 订阅连接状态
+type ConnState int32
+
+const (
+	StateConnecting ConnState = iota
+	StateConnected
+	StateReconnecting
+	StateFailed
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateFailed:
+		return "failed"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// SubscriptionStatus 订阅状态快照，供调用方查看流是否健康
+type SubscriptionStatus struct {
+	Target      string
+	Filters     []string
+	State       ConnState
+	LastMessage time.Time
+	Reconnects  uint64
+	LastError   error
+}
+
+// Backoff 指数退避参数
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64 // 随机抖动比例 0~1
+	MaxRetries int     // 连续失败次数上限，0 表示无限重试
+}
+
+var DefaultBackoff = Backoff{
+	Initial:    200 * time.Millisecond,
+	Max:        10 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// Delay 返回第 attempt 次（从 1 开始）重连前的等待时间
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(b.Initial)
+	for i := 1; i < attempt && d < float64(b.Max); i++ {
+		d *= b.Multiplier
+	}
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+var subscriptions sync.Map // *Subscription -> struct{}
+
+// Statuses 返回当前所有受监管订阅的状态
+func Statuses() []SubscriptionStatus {
+	out := make([]SubscriptionStatus, 0)
+	subscriptions.Range(func(k, _ any) bool {
+		out = append(out, k.(*Subscription).Status())
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Target < out[j].Target })
+	return out
+}
+
+// Subscription 带自动重连的 Geyser 订阅：断线后按退避策略重连并重新发送当前的 SubscribeRequest
+type Subscription struct {
+	conn    *grpc.ClientConn
+	xtoken  string
+	Backoff Backoff
+
+	mu     sync.Mutex
+	req    *pb.SubscribeRequest
+	stream pb.Geyser_SubscribeClient
+
+	state      atomic.Int32
+	lastMsg    atomic.Int64
+	reconnects atomic.Uint64
+	lastErr    atomic.Value // errBox
+}
+
+type errBox struct{ err error }
+
+func NewSubscription(conn *grpc.ClientConn, xtoken string, req *pb.SubscribeRequest) *Subscription {
+	return &Subscription{
+		conn:    conn,
+		xtoken:  xtoken,
+		Backoff: DefaultBackoff,
+		req:     req,
+	}
+}
+
+func (s *Subscription) Target() string {
+	return s.conn.CanonicalTarget()
+}
+
+// Request 返回当前生效的订阅请求
+func (s *Subscription) Request() *pb.SubscribeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.req
+}
+
+// Update 替换订阅请求；流已建立时立即推送，重连后也会使用新请求
+func (s *Subscription) Update(req *pb.SubscribeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.req = req
+	if s.stream == nil {
+		return nil
+	}
+	return s.stream.Send(req)
+}
+
+func (s *Subscription) Status() SubscriptionStatus {
+	st := SubscriptionStatus{
+		Target:     s.Target(),
+		Filters:    filterNames(s.Request()),
+		State:      ConnState(s.state.Load()),
+		Reconnects: s.reconnects.Load(),
+	}
+	if ts := s.lastMsg.Load(); ts > 0 {
+		st.LastMessage = time.Unix(0, ts)
+	}
+	if v, ok := s.lastErr.Load().(errBox); ok {
+		st.LastError = v.err
+	}
+	return st
+}
+
+// Run 阻塞运行订阅直到 ctx 结束或遇到不可恢复的错误，每条更新交给 handler 处理
+func (s *Subscription) Run(ctx context.Context, handler func(*pb.SubscribeUpdate)) error {
+	subscriptions.Store(s, struct{}{})
+	defer subscriptions.Delete(s)
+
+	s.state.Store(int32(StateConnecting))
+	attempt := 0
+	for {
+		received, err := s.session(ctx, handler)
+		if ctx.Err() != nil {
+			s.state.Store(int32(StateClosed))
+			return ctx.Err()
+		}
+		s.lastErr.Store(errBox{err})
+
+		if !retryable(err) {
+			logx.Errorf("[%s]: subscription failed: %v", s.Target(), err)
+			s.state.Store(int32(StateFailed))
+			return err
+		}
+
+		// 收到过消息说明连接曾经健康，退避从头开始
+		if received {
+			attempt = 0
+		}
+		attempt++
+		if s.Backoff.MaxRetries > 0 && attempt > s.Backoff.MaxRetries {
+			logx.Errorf("[%s]: subscription failed after %d retries: %v", s.Target(), attempt-1, err)
+			s.state.Store(int32(StateFailed))
+			return err
+		}
+
+		delay := s.Backoff.Delay(attempt)
+		logx.Errorf("[%s]: subscription lost: %v, reconnecting in %v (attempt %d)", s.Target(), err, delay, attempt)
+		s.state.Store(int32(StateReconnecting))
+		s.reconnects.Add(1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.state.Store(int32(StateClosed))
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// session 建立一次流并持续接收，返回是否收到过消息以及结束原因
+func (s *Subscription) session(ctx context.Context, handler func(*pb.SubscribeUpdate)) (bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.xtoken != "" {
+		streamCtx = metadata.AppendToOutgoingContext(streamCtx, "x-token", s.xtoken)
+	}
+
+	stream, err := pb.NewGeyserClient(s.conn).Subscribe(streamCtx)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	err = stream.Send(s.req)
+	if err == nil {
+		s.stream = stream
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		s.mu.Lock()
+		s.stream = nil
+		s.mu.Unlock()
+	}()
+
+	logx.Infof("[%s]: start recv ...", s.Target())
+	s.state.Store(int32(StateConnected))
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("stream closed by server")
+			}
+			return received, err
+		}
+		received = true
+		s.lastMsg.Store(time.Now().UnixNano())
+		if resp.GetPing() != nil || resp.GetPong() != nil {
+			continue
+		}
+		handler(resp)
+	}
+}
+
+// retryable 判断错误是否值得重连；鉴权、参数错误重连也无济于事
+func retryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument, codes.Unimplemented:
+		return false
+	}
+	return true
+}
+
+func filterNames(req *pb.SubscribeRequest) []string {
+	if req == nil {
+		return nil
+	}
+	var names []string
+	for k := range req.Accounts {
+		names = append(names, "accounts:"+k)
+	}
+	for k := range req.Transactions {
+		names = append(names, "transactions:"+k)
+	}
+	for k := range req.Slots {
+		names = append(names, "slots:"+k)
+	}
+	for k := range req.BlocksMeta {
+		names = append(names, "blocks_meta:"+k)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,41 @@
+package stream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2}
+
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := b.Delay(i + 1); got != w*time.Millisecond {
+			t.Fatalf("attempt %d: got %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+
+	b.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		d := b.Delay(3)
+		if d < 320*time.Millisecond || d > 480*time.Millisecond {
+			t.Fatalf("jittered delay out of range: %v", d)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if !retryable(errors.New("stream closed by server")) {
+		t.Fatal("plain error should be retryable")
+	}
+	if !retryable(status.Error(codes.Unavailable, "down")) {
+		t.Fatal("unavailable should be retryable")
+	}
+	if retryable(status.Error(codes.Unauthenticated, "bad token")) {
+		t.Fatal("unauthenticated should not be retryable")
+	}
+}
@@ -1,26 +1,37 @@
 package stream
 
 import (
+	"os"
+	"strings"
+
 	"google.golang.org/grpc"
 )
 
+// NewTritonStream 从 TRITON_GRPC_URLS / TRITON_XTOKEN 读取 Triton 端点，未配置时 Conns 为空
 func NewTritonStream() *GrpcStream {
-	var (
-		GRPCUrl = []string{
-			"api.rpcpool.com:443",
-		}
-		xtoken = "your x-token"
-	)
+	return newEnvStream("triton", os.Getenv("TRITON_GRPC_URLS"), os.Getenv("TRITON_XTOKEN"), false)
+}
 
+// NewSelfStream 自建节点，从 SELF_GRPC_URLS / SELF_XTOKEN 读取
+func NewSelfStream() *GrpcStream {
+	return newEnvStream("self", os.Getenv("SELF_GRPC_URLS"), os.Getenv("SELF_XTOKEN"), true)
+}
+
+func newEnvStream(name, urls, xtoken string, plaintext bool) *GrpcStream {
 	var conns []*grpc.ClientConn
-	for _, url := range GRPCUrl {
-		conn := Grpc_connect(url, true)
+	for _, url := range strings.Split(urls, ",") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+		conn := Grpc_connect(url, plaintext)
 		if conn == nil {
 			continue
 		}
 		conns = append(conns, conn)
 	}
 	return &GrpcStream{
+		Name:   name,
 		Conns:  conns,
 		Xtoken: xtoken,
 	}
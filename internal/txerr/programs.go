@@ -0,0 +1,126 @@
+package txerr
+
+import "github.com/gagliardetto/solana-go"
+
+var (
+	PumpProgramID      = solana.MustPublicKeyFromBase58("6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P")
+	PumpAmmProgramID   = solana.MustPublicKeyFromBase58("pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA")
+	DbcProgramID       = solana.MustPublicKeyFromBase58("dbcij3LWUppWqq96dh6gJWwBifmcGfLSB5D4DuSMaqN")
+	LaunchLabProgramID = solana.MustPublicKeyFromBase58("LanMV9sAd7wArD4vJFi2qDdfnVhFxYSUg6eADduJ3uj")
+)
+
+// 重试策略用到的错误，用 errors.Is 匹配
+var (
+	PumpTooMuchSolRequired     = &ProgramError{ProgramID: PumpProgramID, Code: 6002}
+	PumpTooLittleSolReceived   = &ProgramError{ProgramID: PumpProgramID, Code: 6003}
+	PumpNotEnoughTokensToSell  = &ProgramError{ProgramID: PumpProgramID, Code: 6023}
+	PumpAmmExceededSlippage    = &ProgramError{ProgramID: PumpAmmProgramID, Code: 6004}
+	DbcExceededSlippage        = &ProgramError{ProgramID: DbcProgramID, Code: 6002}
+	LaunchLabExceededSlippage  = &ProgramError{ProgramID: LaunchLabProgramID, Code: 6004}
+	TokenInsufficientFunds     = &ProgramError{ProgramID: solana.TokenProgramID, Code: 1}
+	Token2022InsufficientFunds = &ProgramError{ProgramID: solana.Token2022ProgramID, Code: 1}
+	SystemInsufficientLamports = &ProgramError{ProgramID: solana.SystemProgramID, Code: 1}
+)
+
+// AnchorAccountNotInitialized 账户不存在，卖出时通常是 token 账户已经关闭
+const AnchorAccountNotInitialized = 3012
+
+type program struct {
+	name   string
+	anchor bool // 6000 以下为 Anchor 框架错误
+	errors map[uint32]string
+}
+
+var programs = map[solana.PublicKey]program{
+	PumpProgramID:             {name: "pump", anchor: true, errors: pumpErrors},
+	PumpAmmProgramID:          {name: "pump_amm", anchor: true, errors: pumpAmmErrors},
+	DbcProgramID:              {name: "meteora_dbc", anchor: true, errors: dbcErrors},
+	LaunchLabProgramID:        {name: "raydium_launchlab", anchor: true, errors: launchLabErrors},
+	solana.TokenProgramID:     {name: "spl_token", errors: tokenErrors},
+	solana.Token2022ProgramID: {name: "spl_token_2022", errors: tokenErrors},
+	solana.SystemProgramID:    {name: "system", errors: systemErrors},
+}
+
+func indexed(start uint32, names ...string) map[uint32]string {
+	out := make(map[uint32]string, len(names))
+	for i, name := range names {
+		out[start+uint32(i)] = name
+	}
+	return out
+}
+
+// 以下错误表按各程序 IDL / 源码中的顺序
+var (
+	pumpErrors = indexed(6000,
+		"NotAuthorized", "AlreadyInitialized", "TooMuchSolRequired", "TooLittleSolReceived",
+		"MintDoesNotMatchBondingCurve", "BondingCurveComplete", "BondingCurveNotComplete", "NotInitialized",
+		"WithdrawTooFrequent", "NewSizeShouldBeGreaterThanCurrentSize", "AccountTypeNotSupported",
+		"InitialRealTokenReservesShouldBeLessThanTokenTotalSupply",
+		"InitialVirtualTokenReservesShouldBeGreaterThanInitialRealTokenReserves",
+		"FeeBasisPointsGreaterThanMaximum", "AllZerosWithdrawAuthority",
+		"PoolMigrationFeeShouldBeLessThanFinalRealSolReserves",
+		"PoolMigrationFeeShouldBeGreaterThanCreatorFeePlusMaxMigrateFees",
+		"DisabledWithdraw", "DisabledMigrate", "InvalidCreator", "BuyZeroAmount",
+		"NotEnoughTokensToBuy", "SellZeroAmount", "NotEnoughTokensToSell", "Overflow",
+		"Truncation", "DivisionByZero", "NotEnoughRemainingAccounts", "AllFeeRecipientsShouldBeNonZero",
+		"UnsortedNotUniqueFeeRecipients", "CreatorShouldNotBeZero",
+	)
+
+	pumpAmmErrors = indexed(6000,
+		"FeeBasisPointsExceedsMaximum", "ZeroBaseAmount", "ZeroQuoteAmount", "TooLittlePoolTokenLiquidity",
+		"ExceededSlippage", "InvalidAdmin", "UnsupportedBaseMint", "UnsupportedQuoteMint",
+		"InvalidBaseMint", "InvalidQuoteMint", "InvalidLpMint", "AllProtocolFeeRecipientsShouldBeNonZero",
+		"UnsortedNotUniqueProtocolFeeRecipients", "InvalidProtocolFeeRecipient", "InvalidPoolBaseTokenAccount",
+		"InvalidPoolQuoteTokenAccount", "BuyMoreBaseAmountThanPoolReserves", "DisabledCreatePool",
+		"DisabledDeposit", "DisabledWithdraw", "DisabledBuy", "DisabledSell", "SameMint", "Overflow",
+		"Truncation", "DivisionByZero", "NewSizeLessThanCurrentSize", "AccountTypeNotSupported",
+		"OnlyCanonicalPumpPoolsCanHaveCoinCreator",
+	)
+
+	dbcErrors = indexed(6000,
+		"MathOverflow", "InvalidFee", "ExceededSlippage", "PoolDisabled", "ExceedMaxFeeBps",
+		"InvalidAdmin", "AmountIsZero", "TypeCastFailed", "UnableToModifyActivationPoint",
+		"InvalidAuthorityToCreateThePool", "InvalidActivationType", "InvalidQuoteMint",
+		"InvalidCollectFeeMode", "InvalidMigrationFeeOption", "InvalidInput", "NotEnoughLiquidity",
+		"PoolIsCompleted",
+	)
+
+	launchLabErrors = indexed(6000,
+		"NotApproved", "InvalidOwner", "InvalidInput", "InputNotMatchCurveConfig", "ExceededSlippage",
+		"PoolFunding", "PoolMigrated", "MigrateTypeNotMatch", "MathOverflow", "NoAssetsToCollect",
+		"VestingRatioTooHigh", "VestingSettingEnded", "VestingNotStarted", "NoVestingSchedule",
+		"InvalidPlatformInfo", "PoolNotMigrated", "InvalidCpSwapConfig", "NoSupportExtension",
+		"NotEnoughRemainingAccounts",
+	)
+
+	tokenErrors = indexed(0,
+		"NotRentExempt", "InsufficientFunds", "InvalidMint", "MintMismatch", "OwnerMismatch",
+		"FixedSupply", "AlreadyInUse", "InvalidNumberOfProvidedSigners", "InvalidNumberOfRequiredSigners",
+		"UninitializedState", "NativeNotSupported", "NonNativeHasBalance", "InvalidInstruction",
+		"InvalidState", "Overflow", "AuthorityTypeNotSupported", "MintCannotFreeze", "AccountFrozen",
+		"MintDecimalsMismatch", "NonNativeNotSupported",
+	)
+
+	systemErrors = indexed(0,
+		"AccountAlreadyInUse", "ResultWithNegativeLamports", "InvalidProgramId", "InvalidAccountDataLength",
+		"MaxSeedLengthExceeded", "AddressWithSeedMismatch", "NonceNoRecentBlockhashes",
+		"NonceBlockhashNotExpired", "NonceUnexpectedBlockhashValue",
+	)
+
+	// Anchor 框架错误，所有 Anchor 程序共用
+	anchorErrors = map[uint32]string{
+		100: "InstructionMissing", 101: "InstructionFallbackNotFound",
+		102: "InstructionDidNotDeserialize", 103: "InstructionDidNotSerialize",
+		2000: "ConstraintMut", 2001: "ConstraintHasOne", 2002: "ConstraintSigner", 2003: "ConstraintRaw",
+		2004: "ConstraintOwner", 2005: "ConstraintRentExempt", 2006: "ConstraintSeeds",
+		2007: "ConstraintExecutable", 2009: "ConstraintAssociated", 2011: "ConstraintClose",
+		2012: "ConstraintAddress", 2014: "ConstraintTokenMint", 2015: "ConstraintTokenOwner",
+		3000: "AccountDiscriminatorAlreadySet", 3001: "AccountDiscriminatorNotFound",
+		3002: "AccountDiscriminatorMismatch", 3003: "AccountDidNotDeserialize",
+		3004: "AccountDidNotSerialize", 3005: "AccountNotEnoughKeys", 3006: "AccountNotMutable",
+		3007: "AccountOwnedByWrongProgram", 3008: "InvalidProgramId", 3009: "InvalidProgramExecutable",
+		3010: "AccountNotSigner", 3011: "AccountNotSystemOwned", 3012: "AccountNotInitialized",
+		3013: "AccountNotProgramData", 3014: "AccountNotAssociatedTokenAccount",
+		3015: "AccountSysvarMismatch",
+	}
+)
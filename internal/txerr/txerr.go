@@ -0,0 +1,176 @@
+package txerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// ErrRateLimited 接口返回 429
+var ErrRateLimited = errors.New("rate limited")
+
+// ProgramError 指令返回的自定义错误码，按出错指令所属的程序解析
+type ProgramError struct {
+	ProgramID solana.PublicKey
+	Program   string // 程序名，未知程序为空
+	Index     int    // 出错指令的序号
+	Code      uint32
+	Name      string // 错误名，未知错误码为空
+}
+
+func (e *ProgramError) Error() string {
+	program := e.Program
+	if program == "" {
+		program = e.ProgramID.String()
+	}
+	name := e.Name
+	if name == "" {
+		name = "Custom"
+	}
+	return fmt.Sprintf("%s: %s(%d) at instruction %d", program, name, e.Code, e.Index)
+}
+
+// Is 按程序和错误码匹配，不比较指令序号，用于 errors.Is(err, txerr.PumpNotEnoughTokensToSell)
+func (e *ProgramError) Is(target error) bool {
+	t, ok := target.(*ProgramError)
+	return ok && t.ProgramID.Equals(e.ProgramID) && t.Code == e.Code
+}
+
+// InstructionError 不是自定义错误码的指令错误，例如 InsufficientFunds
+type InstructionError struct {
+	ProgramID solana.PublicKey
+	Index     int
+	Name      string
+}
+
+func (e *InstructionError) Error() string {
+	return fmt.Sprintf("%s at instruction %d", e.Name, e.Index)
+}
+
+// TransactionError 交易级别的错误，例如 BlockhashNotFound、InsufficientFundsForFee
+type TransactionError struct {
+	Err interface{}
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction error: %v", e.Err)
+}
+
+// Decode 把 Meta.Err（RPC 返回的 JSON 结构或 confirm.TransactionError 的结果）解析成类型化的错误，
+// programs[i] 为第 i 条指令调用的程序；txErr 为 nil 时返回 nil
+func Decode(txErr interface{}, programs []solana.PublicKey) error {
+	if txErr == nil {
+		return nil
+	}
+	m, ok := txErr.(map[string]interface{})
+	if !ok {
+		return &TransactionError{Err: txErr}
+	}
+	detail, ok := m["InstructionError"].([]interface{})
+	if !ok || len(detail) != 2 {
+		return &TransactionError{Err: txErr}
+	}
+	index, ok := number(detail[0])
+	if !ok {
+		return &TransactionError{Err: txErr}
+	}
+
+	var programID solana.PublicKey
+	if int(index) < len(programs) {
+		programID = programs[index]
+	}
+	switch v := detail[1].(type) {
+	case map[string]interface{}:
+		if code, ok := number(v["Custom"]); ok {
+			return NewProgramError(programID, int(index), uint32(code))
+		}
+	case string:
+		return &InstructionError{ProgramID: programID, Index: int(index), Name: v}
+	}
+	return &TransactionError{Err: txErr}
+}
+
+// NewProgramError 按 programID 的错误表查出错误名
+func NewProgramError(programID solana.PublicKey, index int, code uint32) *ProgramError {
+	e := &ProgramError{ProgramID: programID, Index: index, Code: code}
+	if p, ok := programs[programID]; ok {
+		e.Program = p.name
+		e.Name = p.errors[code]
+		if e.Name == "" && p.anchor {
+			e.Name = anchorErrors[code]
+		}
+	}
+	return e
+}
+
+// Programs 交易里每条指令调用的程序，传给 Decode
+func Programs(instrs []solana.Instruction) []solana.PublicKey {
+	out := make([]solana.PublicKey, len(instrs))
+	for i, ins := range instrs {
+		out[i] = ins.ProgramID()
+	}
+	return out
+}
+
+// TxPrograms 已经编译好的交易里每条指令调用的程序；程序不能从地址表加载，只查静态账户
+func TxPrograms(tx *solana.Transaction) []solana.PublicKey {
+	out := make([]solana.PublicKey, len(tx.Message.Instructions))
+	for i, ins := range tx.Message.Instructions {
+		if int(ins.ProgramIDIndex) < len(tx.Message.AccountKeys) {
+			out[i] = tx.Message.AccountKeys[ins.ProgramIDIndex]
+		}
+	}
+	return out
+}
+
+// IsRateLimited err 是接口或 RPC 返回的 429
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var httpErr *jsonrpc.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Code == 429
+}
+
+// IsComputeExceeded 交易因 CU 不够失败：超出 CU 上限时运行时返回 ProgramFailedToComplete
+// 或 ComputationalBudgetExceeded
+func IsComputeExceeded(err error) bool {
+	var e *InstructionError
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.Name == "ProgramFailedToComplete" || e.Name == "ComputationalBudgetExceeded"
+}
+
+// IsAnchor err 是 Anchor 框架在任意 Anchor 程序里返回的 code，例如 3012 AccountNotInitialized
+func IsAnchor(err error, code uint32) bool {
+	var e *ProgramError
+	if !errors.As(err, &e) || e.Code != code {
+		return false
+	}
+	p, ok := programs[e.ProgramID]
+	return ok && p.anchor
+}
+
+// number 兼容 RPC JSON（float64、json.Number）和流里解析出的整数
+func number(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package txerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+func TestDecode(t *testing.T) {
+	programs := []solana.PublicKey{solana.ComputeBudget, PumpAmmProgramID, PumpProgramID}
+
+	// RPC 返回的 JSON 里数字是 float64，流里解析出的是整数
+	var fromRPC interface{}
+	json.Unmarshal([]byte(`{"InstructionError":[2,{"Custom":6023}]}`), &fromRPC)
+	fromStream := map[string]interface{}{"InstructionError": []interface{}{1, map[string]interface{}{"Custom": uint32(6023)}}}
+
+	err := Decode(fromRPC, programs)
+	var progErr *ProgramError
+	if !errors.As(err, &progErr) || progErr.Program != "pump" || progErr.Name != "NotEnoughTokensToSell" || progErr.Index != 2 {
+		t.Fatalf("rpc err = %v", err)
+	}
+	// 同一个错误码在 PumpAMM 里是 Overflow
+	err = Decode(fromStream, programs)
+	if errors.Is(err, PumpNotEnoughTokensToSell) || !errors.As(err, &progErr) || progErr.Name != "Overflow" {
+		t.Fatalf("stream err = %v", err)
+	}
+
+	err = Decode(map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 3012}}}, programs)
+	if !IsAnchor(err, AnchorAccountNotInitialized) || err.Error() != "pump: AccountNotInitialized(3012) at instruction 2" {
+		t.Fatalf("anchor err = %v", err)
+	}
+	if err := Decode(map[string]interface{}{"InstructionError": []interface{}{0, map[string]interface{}{"Custom": 3012}}}, []solana.PublicKey{solana.TokenProgramID}); IsAnchor(err, AnchorAccountNotInitialized) {
+		t.Fatalf("token program is not anchor: %v", err)
+	}
+
+	var insErr *InstructionError
+	if err := Decode(map[string]interface{}{"InstructionError": []interface{}{0.0, "InsufficientFunds"}}, programs); !errors.As(err, &insErr) || insErr.Name != "InsufficientFunds" {
+		t.Fatalf("instruction err = %v", err)
+	}
+	if err := Decode(map[string]interface{}{"InstructionError": []interface{}{2, "ProgramFailedToComplete"}}, programs); !IsComputeExceeded(fmt.Errorf("send: %w", err)) {
+		t.Fatalf("compute exceeded not detected: %v", err)
+	}
+	if IsComputeExceeded(insErr) {
+		t.Fatal("InsufficientFunds detected as compute exceeded")
+	}
+	var txErr *TransactionError
+	if err := Decode("BlockhashNotFound", programs); !errors.As(err, &txErr) {
+		t.Fatalf("transaction err = %v", err)
+	}
+	if Decode(nil, programs) != nil {
+		t.Fatal("nil err decoded")
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	if !IsRateLimited(fmt.Errorf("quote: %w", ErrRateLimited)) || !IsRateLimited(jsonrpc.NewHTTPError(429, errors.New("too many"))) {
+		t.Fatal("429 not detected")
+	}
+	if IsRateLimited(jsonrpc.NewHTTPError(500, errors.New("internal"))) {
+		t.Fatal("500 detected as rate limit")
+	}
+}
@@ -26,3 +26,97 @@ type PumpBotRequest struct {
 type PumpBotResponse struct {
 	Message string `json:"message"`
 }
+
+type Order struct {
+	Id      uint64  `json:"id"`
+	Wallet  string  `json:"wallet"`
+	Mint    string  `json:"mint"`
+	Kind    string  `json:"kind"`
+	Price   float64 `json:"price"`
+	Amount  uint64  `json:"amount"`
+	Rule    string  `json:"rule"`
+	State   string  `json:"state"`
+	Reason  string  `json:"reason,omitempty"`
+	Created int64   `json:"created"`
+	Updated int64   `json:"updated"`
+}
+
+type ListOrdersRequest struct {
+	Mint string `form:"mint,optional"`
+	All  bool   `form:"all,optional"`
+}
+
+type ListOrdersResponse struct {
+	Orders []Order `json:"orders"`
+}
+
+type CancelOrderRequest struct {
+	Id uint64 `path:"id"`
+}
+
+type CancelOrderResponse struct {
+	Message string `json:"message"`
+}
+
+type RiskPosition struct {
+	Ticket    uint64 `json:"ticket"`
+	Mint      string `json:"mint"`
+	Strategy  string `json:"strategy"`
+	Leader    string `json:"leader,omitempty"`
+	Launchpad string `json:"launchpad"`
+	Amount    uint64 `json:"amount"`
+	Opened    int64  `json:"opened"`
+}
+
+type GetRiskRequest struct {
+}
+
+type GetRiskResponse struct {
+	Exposure   uint64         `json:"exposure"`
+	WindowPnl  int64          `json:"windowPnl"`
+	Halted     bool           `json:"halted"`
+	HaltReason string         `json:"haltReason,omitempty"`
+	HaltedAt   int64          `json:"haltedAt,omitempty"`
+	Positions  []RiskPosition `json:"positions"`
+}
+
+type ResetRiskRequest struct {
+}
+
+type ResetRiskResponse struct {
+	Message string `json:"message"`
+}
+
+type TipBucket struct {
+	Tip         uint64  `json:"tip"`
+	Samples     int     `json:"samples"`
+	Probability float64 `json:"probability"`
+}
+
+type ChannelStatus struct {
+	Channel  string      `json:"channel"`
+	Tip      uint64      `json:"tip"`
+	Sent     uint64      `json:"sent"`
+	Landed   uint64      `json:"landed"`
+	AvgSlots float64     `json:"avgSlots"`
+	Buckets  []TipBucket `json:"buckets"`
+}
+
+type StreamStatus struct {
+	Source   string  `json:"source"`
+	Seen     uint64  `json:"seen"`
+	Wins     uint64  `json:"wins"`
+	WinRate  float64 `json:"winRate"`
+	Coverage float64 `json:"coverage"`
+	AvgLagMs float64 `json:"avgLagMs"`
+	MaxLagMs float64 `json:"maxLagMs"`
+}
+
+type GetStatusRequest struct {
+}
+
+type GetStatusResponse struct {
+	Running  bool            `json:"running"`
+	Channels []ChannelStatus `json:"channels"`
+	Streams  []StreamStatus  `json:"streams"`
+}
@@ -0,0 +1,311 @@
+package wallets
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"solana-bot/internal/config"
+	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 新开仓时选择钱包的方式
+const (
+	RoundRobin    = "round_robin"    // 轮流使用
+	LeastExposure = "least_exposure" // 持仓成本最少的钱包
+	Strategy      = "strategy"       // 优先使用专用于该模式的钱包
+)
+
+// 没有配置 minBalance 时，SOL 余额低于 0.5 SOL 的钱包不再开仓
+const defaultMinBalance = 5e8
+
+var ErrNoWallet = errors.New("no wallet available")
+
+// Wallet 一个交易钱包：签名、nonce 池、余额，以及按钱包统计的持仓和盈亏
+type Wallet struct {
+	signer.Signer
+	Name       string
+	Strategies []string // 只用于这些模式，为空时不限
+	Nonces     *global.NoncePool
+	Balance    *global.WalletBalance
+
+	mu        sync.Mutex
+	positions map[string]uint64 // token -> 买入花费的 lamports，买入成交前为 0
+	buys      int
+	closed    int
+	pnl       int64
+}
+
+func NewWallet(name string, s signer.Signer, nonces *global.NoncePool, strategies ...string) *Wallet {
+	return &Wallet{
+		Signer:     s,
+		Name:       name,
+		Strategies: strategies,
+		Nonces:     nonces,
+		Balance:    global.Balance(s.PublicKey()),
+		positions:  make(map[string]uint64),
+	}
+}
+
+func (w *Wallet) String() string {
+	return w.Name
+}
+
+// serves 钱包可以用于 strategy 模式
+func (w *Wallet) serves(strategy string) bool {
+	return len(w.Strategies) == 0 || slices.Contains(w.Strategies, strategy)
+}
+
+// open 登记一个新仓位
+func (w *Wallet) open(token string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.positions[token]; !ok {
+		w.positions[token] = 0
+	}
+}
+
+// Bought 买入成交，cost 为花费的 lamports
+func (w *Wallet) Bought(token string, cost uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.positions[token] += cost
+	w.buys++
+}
+
+// Resume 重启后接管一个已有的仓位，cost 为当初买入花费的 lamports，不计入买入次数
+func (w *Wallet) Resume(token string, cost uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.positions[token] = cost
+}
+
+// Close 仓位结束（卖完或买入失败），pnl 为这笔仓位的盈亏 lamports；重复调用只记一次
+func (w *Wallet) Close(token string, pnl int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cost, ok := w.positions[token]
+	if !ok {
+		return
+	}
+	delete(w.positions, token)
+	if cost > 0 {
+		w.closed++
+		w.pnl += pnl
+	}
+}
+
+// Exposure 当前仓位数和持仓成本
+func (w *Wallet) Exposure() (positions int, lamports uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, cost := range w.positions {
+		lamports += cost
+	}
+	return len(w.positions), lamports
+}
+
+// Stats 按钱包统计的余额、持仓和盈亏
+type Stats struct {
+	Name      string
+	PublicKey string
+	Sol       uint64
+	WSOL      uint64
+	Positions int
+	Exposure  uint64
+	Buys      int
+	Closed    int
+	PnL       int64
+}
+
+func (w *Wallet) Stats() Stats {
+	positions, exposure := w.Exposure()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return Stats{
+		Name:      w.Name,
+		PublicKey: w.PublicKey().String(),
+		Sol:       w.Balance.Sol.Load(),
+		WSOL:      w.Balance.WSOL.Load(),
+		Positions: positions,
+		Exposure:  exposure,
+		Buys:      w.buys,
+		Closed:    w.closed,
+		PnL:       w.pnl,
+	}
+}
+
+// Set 所有交易钱包
+type Set struct {
+	policy     string
+	minBalance uint64
+	wallets    []*Wallet
+
+	mu   sync.Mutex
+	next int
+}
+
+func NewSet(policy string, minBalance uint64, wallets ...*Wallet) *Set {
+	if minBalance == 0 {
+		minBalance = defaultMinBalance
+	}
+	return &Set{
+		policy:     policy,
+		minBalance: minBalance,
+		wallets:    wallets,
+	}
+}
+
+// All 所有钱包，按配置顺序
+func (s *Set) All() []*Wallet {
+	return s.wallets
+}
+
+// PublicKeys 所有钱包的地址
+func (s *Set) PublicKeys() []solana.PublicKey {
+	out := make([]solana.PublicKey, 0, len(s.wallets))
+	for _, w := range s.wallets {
+		out = append(out, w.PublicKey())
+	}
+	return out
+}
+
+// Get 按地址查找钱包
+func (s *Set) Get(pubkey solana.PublicKey) *Wallet {
+	for _, w := range s.wallets {
+		if w.PublicKey().Equals(pubkey) {
+			return w
+		}
+	}
+	return nil
+}
+
+// Pick 为 strategy 模式下 token 的新仓位选择钱包并登记仓位；
+// 余额已知且低于 minBalance 的钱包不参与选择，余额为 0 也算已知
+func (s *Set) Pick(strategy, token string) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var candidates, dedicated []*Wallet
+	for _, w := range s.wallets {
+		if !w.serves(strategy) {
+			continue
+		}
+		if w.Balance.SolKnown.Load() && w.Balance.Sol.Load() < s.minBalance {
+			continue
+		}
+		candidates = append(candidates, w)
+		if len(w.Strategies) > 0 {
+			dedicated = append(dedicated, w)
+		}
+	}
+	if s.policy == Strategy && len(dedicated) > 0 {
+		candidates = dedicated
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoWallet, strategy)
+	}
+
+	var picked *Wallet
+	switch s.policy {
+	case LeastExposure:
+		var minPositions int
+		var minExposure uint64
+		for _, w := range candidates {
+			positions, exposure := w.Exposure()
+			if picked == nil || exposure < minExposure || (exposure == minExposure && positions < minPositions) {
+				picked, minPositions, minExposure = w, positions, exposure
+			}
+		}
+	default:
+		picked = candidates[s.next%len(candidates)]
+		s.next++
+	}
+	picked.open(token)
+	return picked, nil
+}
+
+// First 第一个可以用于 strategy 模式的钱包，不登记仓位
+func (s *Set) First(strategy string) (*Wallet, error) {
+	for _, w := range s.wallets {
+		if w.serves(strategy) {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoWallet, strategy)
+}
+
+// Stats 每个钱包的统计
+func (s *Set) Stats() []Stats {
+	out := make([]Stats, 0, len(s.wallets))
+	for _, w := range s.wallets {
+		out = append(out, w.Stats())
+	}
+	return out
+}
+
+// Report 输出每个钱包的余额、持仓和盈亏
+func (s *Set) Report() {
+	for _, st := range s.Stats() {
+		logx.Infof("[wallet] %s %s: SOL %.4f, WSOL %.4f, positions %d, exposure %.4f, buys %d, closed %d, pnl %.4f",
+			st.Name, st.PublicKey, float64(st.Sol)/1e9, float64(st.WSOL)/1e9, st.Positions,
+			float64(st.Exposure)/1e9, st.Buys, st.Closed, float64(st.PnL)/1e9)
+	}
+}
+
+// Load 按配置创建交易钱包，签名前都经过 signer.policy 检查；
+// 没有配置 wallets.list 时使用 signer、nonce 配置的一个钱包
+func Load(conf config.BotConf, tips ...solana.PublicKey) (*Set, error) {
+	guard := func(s signer.Signer) (signer.Signer, error) {
+		return signer.NewGuarded(s, conf.Signer.Policy, tips...)
+	}
+
+	if len(conf.Wallets.List) == 0 {
+		s, err := signer.New(conf.Signer)
+		if err != nil {
+			return nil, err
+		}
+		if s, err = guard(s); err != nil {
+			return nil, err
+		}
+		nonces := global.InitNoncePool(conf.Nonce.Accounts, conf.Nonce.Size)
+		return NewSet(conf.Wallets.Policy, conf.Wallets.MinBalance, NewWallet("default", s, nonces)), nil
+	}
+
+	var wallets []*Wallet
+	seen := make(map[solana.PublicKey]string)
+	for i, wc := range conf.Wallets.List {
+		name := wc.Name
+		if name == "" {
+			name = fmt.Sprintf("wallet-%d", i)
+		}
+		// 每个钱包的 nonce 账户 authority 不同，不能共用内置账户
+		if len(wc.Nonce.Accounts) == 0 {
+			return nil, fmt.Errorf("wallet %s: no nonce accounts", name)
+		}
+		s, err := signer.New(wc.Signer)
+		if err != nil {
+			return nil, fmt.Errorf("wallet %s: %w", name, err)
+		}
+		if other, ok := seen[s.PublicKey()]; ok {
+			return nil, fmt.Errorf("wallet %s: same key as %s", name, other)
+		}
+		seen[s.PublicKey()] = name
+		if s, err = guard(s); err != nil {
+			return nil, err
+		}
+
+		accounts := wc.Nonce.Accounts
+		if wc.Nonce.Size > 0 && wc.Nonce.Size < len(accounts) {
+			accounts = accounts[:wc.Nonce.Size]
+		}
+		wallets = append(wallets, NewWallet(name, s, global.NewNoncePool(accounts), wc.Strategies...))
+	}
+	logx.Infof("[wallet] %d wallets, policy %s", len(wallets), conf.Wallets.Policy)
+	return NewSet(conf.Wallets.Policy, conf.Wallets.MinBalance, wallets...), nil
+}
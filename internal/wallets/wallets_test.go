@@ -0,0 +1,109 @@
+package wallets
+
+import (
+	"errors"
+	"testing"
+
+	"solana-bot/internal/global"
+	"solana-bot/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func testWallet(name string, strategies ...string) *Wallet {
+	s := signer.NewKeySigner(solana.NewWallet().PrivateKey)
+	return NewWallet(name, s, global.NewNoncePool(nil), strategies...)
+}
+
+func TestRoundRobin(t *testing.T) {
+	a, b := testWallet("a"), testWallet("b")
+	set := NewSet(RoundRobin, 0, a, b)
+
+	want := []*Wallet{a, b, a}
+	for i, token := range []string{"t1", "t2", "t3"} {
+		w, err := set.Pick("mint", token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != want[i] {
+			t.Fatalf("pick %d = %s, want %s", i, w, want[i])
+		}
+	}
+}
+
+func TestLeastExposure(t *testing.T) {
+	a, b := testWallet("a"), testWallet("b")
+	set := NewSet(LeastExposure, 0, a, b)
+
+	w, _ := set.Pick("mint", "t1")
+	w.Bought("t1", 1e9)
+	if w, _ = set.Pick("mint", "t2"); w != b {
+		t.Fatalf("pick = %s, want b", w)
+	}
+	w.Bought("t2", 2e8)
+	if w, _ = set.Pick("mint", "t3"); w != b {
+		t.Fatalf("pick = %s, want b", w)
+	}
+}
+
+func TestStrategy(t *testing.T) {
+	shared, smart := testWallet("shared"), testWallet("smart", "smart")
+	set := NewSet(Strategy, 0, shared, smart)
+
+	for _, token := range []string{"t1", "t2"} {
+		if w, _ := set.Pick("smart", token); w != smart {
+			t.Fatalf("smart pick = %s, want smart", w)
+		}
+		if w, _ := set.Pick("mint", token); w != shared {
+			t.Fatalf("mint pick = %s, want shared", w)
+		}
+	}
+	if _, err := set.First("scm"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMinBalance(t *testing.T) {
+	a, b := testWallet("a"), testWallet("b")
+	set := NewSet(RoundRobin, 0, a, b)
+
+	a.Balance.SetSol(1e8)
+	for _, token := range []string{"t1", "t2"} {
+		if w, _ := set.Pick("mint", token); w != b {
+			t.Fatalf("pick = %s, want b", w)
+		}
+	}
+	b.Balance.SetSol(1e8)
+	if _, err := set.Pick("mint", "t3"); !errors.Is(err, ErrNoWallet) {
+		t.Fatalf("err = %v, want %v", err, ErrNoWallet)
+	}
+
+	// 余额为 0 的钱包同样不能开仓，还没收到余额的钱包可以
+	empty, unknown := testWallet("empty"), testWallet("unknown")
+	empty.Balance.SetSol(0)
+	set = NewSet(RoundRobin, 0, empty, unknown)
+	for _, token := range []string{"t4", "t5"} {
+		if w, _ := set.Pick("mint", token); w != unknown {
+			t.Fatalf("pick = %s, want unknown", w)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	w := testWallet("a")
+	set := NewSet(RoundRobin, 0, w)
+
+	set.Pick("mint", "t1")
+	w.Bought("t1", 5e8)
+	w.Close("t1", 1e8)
+	w.Close("t1", 1e8)
+
+	// 买入失败的仓位不计入已平仓
+	set.Pick("mint", "t2")
+	w.Close("t2", 0)
+
+	st := w.Stats()
+	if st.Positions != 0 || st.Exposure != 0 || st.Buys != 1 || st.Closed != 1 || st.PnL != 1e8 {
+		t.Fatalf("stats = %+v", st)
+	}
+}